@@ -1,4 +1,4 @@
-// Package swagger Code generated by swaggo/swag at 2025-08-23 13:59:59.040786 +0800 +08 m=+0.808375710. DO NOT EDIT
+// Package swagger Code generated by swaggo/swag at 2026-10-19 13:21:26.355879008 +0000 UTC m=+2.747122108. DO NOT EDIT
 package swagger
 
 import "github.com/swaggo/swag"
@@ -24,6 +24,200 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/admin/jobs": {
+            "get": {
+                "description": "List the background jobs in the order they are due. Requires ADMIN_TOKEN.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd admin token here\u003e",
+                        "description": "Insert the admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only jobs of this kind, e.g. sync.result",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dtos.Job"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/jobs/{name}/pause": {
+            "post": {
+                "description": "Pause a background job, a running job finishes. Requires ADMIN_TOKEN.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd admin token here\u003e",
+                        "description": "Insert the admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job name, e.g. sync.result:2110000",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/jobs/{name}/resume": {
+            "post": {
+                "description": "Resume a paused background job. Requires ADMIN_TOKEN.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd admin token here\u003e",
+                        "description": "Insert the admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job name, e.g. sync.result:2110000",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/jobs/{name}/run": {
+            "post": {
+                "description": "Make a background job due now, it runs on the next scheduler tick unless paused. Requires ADMIN_TOKEN.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd admin token here\u003e",
+                        "description": "Insert the admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job name, e.g. sync.result:2110000",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
         "/api/ads": {
             "get": {
                 "description": "Get i-Ma'luum ads",
@@ -43,6 +237,58 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/analytics": {
+            "get": {
+                "description": "Get analytics summary grouped by level and batch",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/login": {
+            "post": {
+                "description": "Logs in the user. Save the token and use it in the Authorization header for future requests.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "parameters": [
+                    {
+                        "description": "Login properties",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth_proto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
         "/api/download/exam-slip": {
             "get": {
                 "description": "Get exam slip PDF from i-Ma'luum",
@@ -101,9 +347,12 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/profile": {
-            "get": {
-                "description": "Get i-Ma'luum profile",
+        "/api/graphql": {
+            "post": {
+                "description": "Query profile, sessions, schedule, results and starpoint over GraphQL, scraping only the fields selected.\nThe schema is in internal/graph/schema.graphql. Failed fields come back in errors with their code and status in extensions.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -118,21 +367,31 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "GraphQL query",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GraphQLRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ResponseDTO"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/api/result": {
+        "/api/me": {
             "get": {
-                "description": "Get result from i-Ma'luum",
+                "description": "Get profile, schedule, result and starpoint in one call, scraped concurrently with one session.\nEach section holds its data in the shape of its /api/v2 endpoint, or the error that kept it from being fetched.\nThe call only fails when every section does.",
                 "produces": [
                     "application/json"
                 ],
@@ -147,27 +406,47 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "profile,schedule",
+                        "description": "Comma separated sections to fetch, all of them by default",
+                        "name": "include",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ResponseDTO"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.Me"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/api/schedule": {
+        "/api/profile": {
             "get": {
-                "description": "Get schedule from i-Ma'luum",
+                "description": "Get i-Ma'luum profile",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "scraper"
                 ],
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -176,6 +455,27 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "cache"
+                        ],
+                        "type": "string",
+                        "description": "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the response cache and refetch from i-Ma'luum",
+                        "name": "fresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier response, answered with 304 Not Modified while unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -183,19 +483,71 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/dtos.ResponseDTO"
+                        },
+                        "headers": {
+                            "Deprecation": {
+                                "type": "string",
+                                "description": "When this route was deprecated, in favour of /api/v2/profile"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Fingerprint of the data"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the data first appeared, once snapshots are enabled"
+                            },
+                            "Sunset": {
+                                "type": "string",
+                                "description": "When this route may be removed"
+                            }
                         }
                     }
                 }
             }
         },
-        "/api/starpoint": {
+        "/api/push/public-key": {
             "get": {
-                "description": "Get co-curricular from i-Ma'luum",
+                "description": "Get the VAPID public key, the applicationServerKey for pushManager.subscribe()",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scraper"
+                    "push"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.PushPublicKey"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/push/subscriptions": {
+            "post": {
+                "description": "Registers a browser push subscription. Results, timetable and starpoints are polled in the background and pushed when they change, the first poll only records a baseline.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "push"
                 ],
                 "parameters": [
                     {
@@ -205,21 +557,40 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "PushSubscription.toJSON()",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PushSubscriptionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ResponseDTO"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.PushSubscription"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            }
-        },
-        "/auth/login": {
-            "post": {
-                "description": "Logs in the user. Save the token and use it in the Authorization header for future requests.",
+            },
+            "delete": {
+                "description": "Removes a browser push subscription of the user. Background polling stops once the user has no subscription or webhook left.",
                 "consumes": [
                     "application/json"
                 ],
@@ -227,16 +598,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "push"
                 ],
                 "parameters": [
                     {
-                        "description": "Login properties",
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription endpoint",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth_proto.LoginRequest"
+                            "$ref": "#/definitions/dtos.PushUnsubscribeRequest"
                         }
                     }
                 ],
@@ -250,17 +629,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/logout": {
-            "get": {
-                "description": "Logs out the user. Clears the token from IIUM's CAS. PASETO token is still valid.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/push/test": {
+            "post": {
+                "description": "Sends a test notification to every push subscription of the user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "push"
                 ],
                 "parameters": [
                     {
@@ -276,24 +652,1021 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.ResponseDTO"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.PushTestResult"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/health": {
+        "/api/result": {
             "get": {
-                "description": "Check the health of the application.",
+                "description": "Get result from i-Ma'luum",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "misc"
+                    "scraper"
+                ],
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cache"
+                        ],
+                        "type": "string",
+                        "description": "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the response cache and refetch from i-Ma'luum",
+                        "name": "fresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier response, answered with 304 Not Modified while unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        },
+                        "headers": {
+                            "Deprecation": {
+                                "type": "string",
+                                "description": "When this route was deprecated, in favour of /api/v2/result"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Fingerprint of the data"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the data first appeared, once snapshots are enabled"
+                            },
+                            "Sunset": {
+                                "type": "string",
+                                "description": "When this route may be removed"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/result/simulate": {
+            "post": {
+                "description": "Projects GPA and CGPA for hypothetical grades, and the average needed to reach a target CGPA. Results are scraped from i-Ma'luum unless given in the body.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "result"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Hypothetical courses and target",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SimulateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.SimulateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/result/summary": {
+            "get": {
+                "description": "Aggregates the results of every session into credit hours earned, passed, failed and repeated courses, grade distribution and CGPA trend",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "result"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.TranscriptSummary"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/schedule": {
+            "get": {
+                "description": "Get schedule from i-Ma'luum",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scraper"
+                ],
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cache"
+                        ],
+                        "type": "string",
+                        "description": "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the response cache and refetch from i-Ma'luum",
+                        "name": "fresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier response, answered with 304 Not Modified while unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        },
+                        "headers": {
+                            "Deprecation": {
+                                "type": "string",
+                                "description": "When this route was deprecated, in favour of /api/v2/schedule"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Fingerprint of the data"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the data first appeared, once snapshots are enabled"
+                            },
+                            "Sunset": {
+                                "type": "string",
+                                "description": "When this route may be removed"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/snapshots": {
+            "get": {
+                "description": "List the stored snapshots of the user, newest first, without their payload",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "profile",
+                            "schedule",
+                            "result",
+                            "starpoint"
+                        ],
+                        "type": "string",
+                        "description": "Only snapshots of this kind",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.SnapshotHistory"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Opt in to storing every successful profile, schedule, result and starpoint scrape. Stored snapshots are served with ?source=cache while i-Ma'luum is down.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Opt out of snapshots and delete every snapshot stored for the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/snapshots/{id}": {
+            "get": {
+                "description": "Get a stored snapshot of the user with its payload",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "snapshot"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID from the history",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.Snapshot"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/api/starpoint": {
+            "get": {
+                "description": "Get co-curricular from i-Ma'luum",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scraper"
+                ],
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cache"
+                        ],
+                        "type": "string",
+                        "description": "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the response cache and refetch from i-Ma'luum",
+                        "name": "fresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier response, answered with 304 Not Modified while unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        },
+                        "headers": {
+                            "Deprecation": {
+                                "type": "string",
+                                "description": "When this route was deprecated, in favour of /api/v2/starpoint"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Fingerprint of the data"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the data first appeared, once snapshots are enabled"
+                            },
+                            "Sunset": {
+                                "type": "string",
+                                "description": "When this route may be removed"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sync/{kind}": {
+            "get": {
+                "description": "Scrape schedule or result from i-Ma'luum while streaming its progress as Server-Sent Events.\nprogress events carry a dtos.SyncProgress, once the session list is read and as each session is parsed.\nThe stream ends with a done event holding the response in the shape of its /api/v2 endpoint, or an error event holding the error body.\nClosing the connection stops the scrape.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "scraper"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "schedule",
+                            "result"
+                        ],
+                        "type": "string",
+                        "description": "What to sync",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SyncProgress"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/profile": {
+            "get": {
+                "description": "Get i-Ma'luum profile, the same as /api/profile",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cache"
+                        ],
+                        "type": "string",
+                        "description": "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the response cache and refetch from i-Ma'luum",
+                        "name": "fresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier response, answered with 304 Not Modified while unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.Profile"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Fingerprint of the data"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the data first appeared, once snapshots are enabled"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/result": {
+            "get": {
+                "description": "Get result from i-Ma'luum with GPA, CGPA and credits as numbers and grades as an enum.\nEach session carries a GPA recomputed from its courses, compared with the one i-Ma'luum printed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cache"
+                        ],
+                        "type": "string",
+                        "description": "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the response cache and refetch from i-Ma'luum",
+                        "name": "fresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier response, answered with 304 Not Modified while unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dtos.ResultResponseV2"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Fingerprint of the data"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the data first appeared, once snapshots are enabled"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/schedule": {
+            "get": {
+                "description": "Get schedule from i-Ma'luum with credit hours spelled out and every class as a weekly slot in 24-hour time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cache"
+                        ],
+                        "type": "string",
+                        "description": "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the response cache and refetch from i-Ma'luum",
+                        "name": "fresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier response, answered with 304 Not Modified while unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dtos.ScheduleResponseV2"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Fingerprint of the data"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the data first appeared, once snapshots are enabled"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/starpoint": {
+            "get": {
+                "description": "Get co-curricular from i-Ma'luum with the cumulative average spelled right",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cache"
+                        ],
+                        "type": "string",
+                        "description": "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip the response cache and refetch from i-Ma'luum",
+                        "name": "fresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of an earlier response, answered with 304 Not Modified while unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.StarpointV2"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Fingerprint of the data"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "When the data first appeared, once snapshots are enabled"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks": {
+            "get": {
+                "description": "Get the webhook registered for the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.Webhook"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers a webhook called with a signed result.released event when new grades appear. Results are polled in the background, the first poll only records a baseline. The secret is only returned here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook URL",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dtos.Webhook"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the webhook of the user. Background polling stops and the stored password is forgotten once the user has no push subscription left either.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/dead-letters": {
+            "get": {
+                "description": "List the latest webhook deliveries of the user that failed every attempt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.ResponseDTO"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dtos.DeadLetter"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "get": {
+                "description": "Logs out the user. Clears the token from IIUM's CAS. PASETO token is still valid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResponseDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check the health of the application.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "misc"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
                     }
                 }
             }
@@ -303,20 +1676,906 @@ const docTemplate = `{
         "auth_proto.LoginRequest": {
             "type": "object",
             "properties": {
-                "password": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CourseAttempt": {
+            "type": "object",
+            "properties": {
+                "grade": {
+                    "$ref": "#/definitions/dtos.Grade"
+                },
+                "session_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CourseCounts": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "passed": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "repeated": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "unknown": {
+                    "type": "integer"
+                },
+                "withdrawn": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ExcludedCourse": {
+            "type": "object",
+            "properties": {
+                "course_code": {
+                    "type": "string"
+                },
+                "grade": {
+                    "$ref": "#/definitions/dtos.Grade"
+                },
+                "reason": {
+                    "$ref": "#/definitions/dtos.ExclusionReason"
+                }
+            }
+        },
+        "dtos.ExclusionReason": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "INCOMPLETE",
+                "WITHDRAWN",
+                "PASS_FAIL",
+                "UNKNOWN_GRADE",
+                "NO_CREDIT"
+            ],
+            "x-enum-varnames": [
+                "ExcludedPending",
+                "ExcludedIncomplete",
+                "ExcludedWithdrawn",
+                "ExcludedPassFail",
+                "ExcludedUnknown",
+                "ExcludedNoCredit"
+            ]
+        },
+        "dtos.GpaCheck": {
+            "type": "object",
+            "properties": {
+                "computed_credits": {
+                    "type": "number"
+                },
+                "computed_gpa": {
+                    "type": "number"
+                },
+                "difference": {
+                    "type": "number"
+                },
+                "excluded": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ExcludedCourse"
+                    }
+                },
+                "scraped_gpa": {
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/dtos.GpaCheckStatus"
+                }
+            }
+        },
+        "dtos.GpaCheckStatus": {
+            "type": "string",
+            "enum": [
+                "MATCH",
+                "MISMATCH",
+                "UNVERIFIED"
+            ],
+            "x-enum-varnames": [
+                "GpaCheckMatch",
+                "GpaCheckMismatch",
+                "GpaCheckUnverified"
+            ]
+        },
+        "dtos.Grade": {
+            "type": "string",
+            "enum": [
+                "A",
+                "A-",
+                "B+",
+                "B",
+                "B-",
+                "C+",
+                "C",
+                "D",
+                "D-",
+                "E",
+                "F",
+                "P",
+                "I",
+                "W",
+                "PENDING",
+                "UNKNOWN"
+            ],
+            "x-enum-varnames": [
+                "GradeA",
+                "GradeAMinus",
+                "GradeBPlus",
+                "GradeB",
+                "GradeBMinus",
+                "GradeCPlus",
+                "GradeC",
+                "GradeD",
+                "GradeDMinus",
+                "GradeE",
+                "GradeF",
+                "GradePass",
+                "GradeIncomplete",
+                "GradeWithdrawn",
+                "GradePending",
+                "GradeUnknown"
+            ]
+        },
+        "dtos.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ schedule(session: \"Sem 1, 2024/2025\") { subjects { courseCode slots { day start end } } } }"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "dtos.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "interval_seconds": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Key is what the job runs for, the matric number of sync jobs",
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "last_duration_ms": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "integer"
+                },
+                "paused": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtos.Me": {
+            "type": "object",
+            "properties": {
+                "profile": {
+                    "$ref": "#/definitions/dtos.MeSection"
+                },
+                "result": {
+                    "$ref": "#/definitions/dtos.MeSection"
+                },
+                "schedule": {
+                    "$ref": "#/definitions/dtos.MeSection"
+                },
+                "starpoint": {
+                    "$ref": "#/definitions/dtos.MeSection"
+                }
+            }
+        },
+        "dtos.MeSection": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error": {
+                    "$ref": "#/definitions/dtos.SectionError"
+                }
+            }
+        },
+        "dtos.ParseWarning": {
+            "type": "object",
+            "properties": {
+                "cells": {
+                    "type": "integer"
+                },
+                "endpoint": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "session": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Profile": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "birthday": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "ic": {
+                    "type": "string"
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "kuliyyah": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string"
+                },
+                "marital_status": {
+                    "type": "string"
+                },
+                "matric_no": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "religion": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Projection": {
+            "type": "object",
+            "properties": {
+                "cgpa": {
+                    "type": "number"
+                },
+                "credit_hours": {
+                    "type": "number"
+                },
+                "gpa": {
+                    "type": "number"
+                }
+            }
+        },
+        "dtos.PushKeys": {
+            "type": "object",
+            "properties": {
+                "auth": {
+                    "type": "string"
+                },
+                "p256dh": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.PushPublicKey": {
+            "type": "object",
+            "properties": {
+                "public_key": {
+                    "description": "PublicKey is the applicationServerKey for pushManager.subscribe()",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.PushSubscription": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "endpoint": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.PushSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "endpoint": {
+                    "type": "string"
+                },
+                "keys": {
+                    "$ref": "#/definitions/dtos.PushKeys"
+                }
+            }
+        },
+        "dtos.PushTestResult": {
+            "type": "object",
+            "properties": {
+                "delivered": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.PushUnsubscribeRequest": {
+            "type": "object",
+            "properties": {
+                "endpoint": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.RepeatedCourse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.CourseAttempt"
+                    }
+                },
+                "course_code": {
+                    "type": "string"
+                },
+                "course_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ResponseDTO": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "data": {},
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "RequestID is also sent as X-Request-Id. Cacheable responses are shared\nbetween requests and leave it out, the header names the current one.",
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ParseWarning"
+                    }
+                }
+            }
+        },
+        "dtos.Result": {
+            "type": "object",
+            "properties": {
+                "course_code": {
                     "type": "string"
                 },
-                "username": {
+                "course_credit": {
+                    "type": "string"
+                },
+                "course_grade": {
+                    "type": "string"
+                },
+                "course_name": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "string"
                 }
             }
         },
-        "dtos.ResponseDTO": {
+        "dtos.ResultRaw": {
             "type": "object",
             "properties": {
-                "data": {},
+                "credit": {
+                    "type": "string"
+                },
+                "grade": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ResultResponse": {
+            "type": "object",
+            "properties": {
+                "cgpa_value": {
+                    "type": "string"
+                },
+                "credit_hours": {
+                    "description": "Remarks      string   ` + "`" + `json:\"remarks\"` + "`" + `",
+                    "type": "string"
+                },
+                "gpa_value": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Result"
+                    }
+                },
+                "session_name": {
+                    "type": "string"
+                },
+                "session_query": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ResultResponseV2": {
+            "type": "object",
+            "properties": {
+                "cgpa": {
+                    "type": "number"
+                },
+                "credit_hours": {
+                    "type": "number"
+                },
+                "gpa": {
+                    "type": "number"
+                },
+                "gpa_check": {
+                    "$ref": "#/definitions/dtos.GpaCheck"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "raw": {
+                    "$ref": "#/definitions/dtos.ResultSummaryRaw"
+                },
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ResultV2"
+                    }
+                },
+                "session_name": {
+                    "type": "string"
+                },
+                "session_query": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ResultSummaryRaw": {
+            "type": "object",
+            "properties": {
+                "cgpa": {
+                    "type": "string"
+                },
+                "credit_hours": {
+                    "type": "string"
+                },
+                "gpa": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ResultV2": {
+            "type": "object",
+            "properties": {
+                "course_code": {
+                    "type": "string"
+                },
+                "course_name": {
+                    "type": "string"
+                },
+                "credit": {
+                    "type": "number"
+                },
+                "grade": {
+                    "$ref": "#/definitions/dtos.Grade"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "raw": {
+                    "$ref": "#/definitions/dtos.ResultRaw"
+                }
+            }
+        },
+        "dtos.ScheduleResponseV2": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ScheduleSubjectV2"
+                    }
+                },
+                "session_name": {
+                    "type": "string"
+                },
+                "session_query": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ScheduleSlot": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "integer"
+                },
+                "end": {
+                    "type": "string",
+                    "example": "09:50"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "08:30"
+                }
+            }
+        },
+        "dtos.ScheduleSubjectV2": {
+            "type": "object",
+            "properties": {
+                "course_code": {
+                    "type": "string"
+                },
+                "course_name": {
+                    "type": "string"
+                },
+                "credit_hours": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lecturer": {
+                    "type": "string"
+                },
+                "section": {
+                    "type": "integer"
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ScheduleSlot"
+                    }
+                },
+                "venue": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SectionError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.SemesterTrend": {
+            "type": "object",
+            "properties": {
+                "cgpa": {
+                    "type": "number"
+                },
+                "credit_hours": {
+                    "type": "number"
+                },
+                "gpa": {
+                    "type": "number"
+                },
+                "session_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SimulateRequest": {
+            "type": "object",
+            "properties": {
+                "courses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.SimulatedCourse"
+                    }
+                },
+                "remaining_credits": {
+                    "description": "RemainingCredits are credit hours still to be taken after Courses.\nWithout it the target is worked out over Courses alone.",
+                    "type": "number"
+                },
+                "results": {
+                    "description": "Results is the history to simulate on, scraped from i-Ma'luum when empty",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ResultResponse"
+                    }
+                },
+                "target_cgpa": {
+                    "description": "TargetCgpa asks for the average needed to reach this CGPA",
+                    "type": "number"
+                }
+            }
+        },
+        "dtos.SimulateResponse": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "$ref": "#/definitions/dtos.Projection"
+                },
+                "grade_points": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "projected": {
+                    "$ref": "#/definitions/dtos.Projection"
+                },
+                "target": {
+                    "$ref": "#/definitions/dtos.SimulatedTarget"
+                }
+            }
+        },
+        "dtos.SimulatedCourse": {
+            "type": "object",
+            "properties": {
+                "course_code": {
+                    "type": "string"
+                },
+                "credit": {
+                    "type": "number"
+                },
+                "grade": {
+                    "$ref": "#/definitions/dtos.Grade"
+                }
+            }
+        },
+        "dtos.SimulatedTarget": {
+            "type": "object",
+            "properties": {
+                "achievable": {
+                    "type": "boolean"
+                },
+                "cgpa": {
+                    "type": "number"
+                },
+                "remaining_credits": {
+                    "type": "number"
+                },
+                "required_average": {
+                    "description": "RequiredAverage is the lowest average grade point over RemainingCredits\nthat reaches Cgpa, zero if it is already reached",
+                    "type": "number"
+                }
+            }
+        },
+        "dtos.Snapshot": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "fetched_at": {
+                    "type": "integer"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                }
+            }
+        },
+        "dtos.SnapshotHistory": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Snapshot"
+                    }
+                }
+            }
+        },
+        "dtos.StarpointProgram": {
+            "type": "object",
+            "properties": {
+                "event_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "number"
+                },
+                "semester": {
+                    "type": "integer"
+                },
+                "session": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.StarpointV2": {
+            "type": "object",
+            "properties": {
+                "cumulative_average": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "programs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.StarpointProgram"
+                    }
+                },
+                "total_points": {
+                    "type": "number"
+                }
+            }
+        },
+        "dtos.SyncProgress": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "type": "integer"
+                },
                 "message": {
                     "type": "string"
+                },
+                "session": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.TranscriptSummary": {
+            "type": "object",
+            "properties": {
+                "cgpa": {
+                    "type": "number"
+                },
+                "courses": {
+                    "$ref": "#/definitions/dtos.CourseCounts"
+                },
+                "credit_hours_earned": {
+                    "type": "number"
+                },
+                "grade_distribution": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "repeated": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.RepeatedCourse"
+                    }
+                },
+                "trend": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.SemesterTrend"
+                    }
+                }
+            }
+        },
+        "dtos.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "last_polled_at": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "Secret signs every delivery, it is only returned when the webhook is registered",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.WebhookRequest": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
                 }
             }
         }
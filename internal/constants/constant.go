@@ -1,19 +1,137 @@
 package constants
 
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	IiumPage      = "https://www.iium.edu.my/"
+	TimeSeparator = "-"
+)
+
+// Production base URLs, used unless overridden (see LoadUpstream)
 const (
-	IiumPage                    = "https://www.iium.edu.my/"
-	ImaluumPage                 = "https://imaluum.iium.edu.my/"
-	ImaluumLogoutPage           = "https://imaluum.iium.edu.my/logout"
-	ImaluumCasPage              = "https://cas.iium.edu.my:8448/cas/login?service=https%3a%2f%2fimaluum.iium.edu.my%2fhome"
-	ImaluumCasLogoutPage        = "https://cas.iium.edu.my:8448/cas/logout?service=http://imaluum.iium.edu.my/"
-	ImaluumProfilePage          = "https://imaluum.iium.edu.my/Profile"
-	ImaluumLoginPage            = "https://cas.iium.edu.my:8448/cas/login?service=https%3a%2f%2fimaluum.iium.edu.my%2fhome?service=https%3a%2f%2fimaluum.iium.edu.my%2fhome"
-	ImaluumHomePage             = "https://imaluum.iium.edu.my/home"
-	ImaluumSchedulePage         = "https://imaluum.iium.edu.my/MyAcademic/schedule"
-	ImaluumResultPage           = "https://imaluum.iium.edu.my/MyAcademic/result"
-	ImaluumConfirmationSlipPage = "https://imaluum.iium.edu.my/MyAcademic/confirmation-sem"
-	ImaluumExamSlipPage         = "https://imaluum.iium.edu.my/examslip"
-	ImaluumStudyPlanPage        = "https://imaluum.iium.edu.my/MyAcademic/studyplan"
-	ImaluumStarpointPage        = "https://imaluum.iium.edu.my/CoCurriculum"
-	TimeSeparator               = "-"
+	DefaultImaluumBaseURL        = "https://imaluum.iium.edu.my"
+	DefaultCASBaseURL            = "https://cas.iium.edu.my:8448"
+	DefaultSmartcardImageBaseURL = "https://smartcard.iium.edu.my/packages/card/printing/camera/uploads/original/"
+	DefaultSouqAdsURL            = "https://souq.iium.edu.my/embeded"
+
+	casLogoutService = "http://imaluum.iium.edu.my/"
 )
+
+// i-Ma'luum page paths, relative to the i-Ma'luum base URL
+const (
+	ImaluumLogoutPath           = "/logout"
+	ImaluumProfilePath          = "/Profile"
+	ImaluumHomePath             = "/home"
+	ImaluumSchedulePath         = "/MyAcademic/schedule"
+	ImaluumResultPath           = "/MyAcademic/result"
+	ImaluumConfirmationSlipPath = "/MyAcademic/confirmation-sem"
+	ImaluumExamSlipPath         = "/examslip"
+	ImaluumStudyPlanPath        = "/MyAcademic/studyplan"
+	ImaluumStarpointPath        = "/CoCurriculum"
+)
+
+// CAS page paths, relative to the CAS base URL
+const (
+	CasLoginPath  = "/cas/login"
+	CasLogoutPath = "/cas/logout"
+)
+
+// Upstream holds the base URLs of every IIUM service gomaluum talks to.
+// Every page URL is derived from them, so a deployment or a test can point
+// the whole scraper at a mirror, a recording proxy or a local fake server.
+type Upstream struct {
+	ImaluumBaseURL        string
+	CASBaseURL            string
+	SmartcardImageBaseURL string
+	SouqAdsURL            string
+}
+
+var DefaultUpstream = Upstream{
+	ImaluumBaseURL:        DefaultImaluumBaseURL,
+	CASBaseURL:            DefaultCASBaseURL,
+	SmartcardImageBaseURL: DefaultSmartcardImageBaseURL,
+	SouqAdsURL:            DefaultSouqAdsURL,
+}
+
+// LoadUpstream returns DefaultUpstream overridden by
+// UPSTREAM_IMALUUM_URL, UPSTREAM_CAS_URL, UPSTREAM_SMARTCARD_IMAGE_URL and UPSTREAM_SOUQ_ADS_URL
+func LoadUpstream() Upstream {
+	return Upstream{
+		ImaluumBaseURL:        strings.TrimSuffix(envOr("UPSTREAM_IMALUUM_URL", DefaultImaluumBaseURL), "/"),
+		CASBaseURL:            strings.TrimSuffix(envOr("UPSTREAM_CAS_URL", DefaultCASBaseURL), "/"),
+		SmartcardImageBaseURL: envOr("UPSTREAM_SMARTCARD_IMAGE_URL", DefaultSmartcardImageBaseURL),
+		SouqAdsURL:            envOr("UPSTREAM_SOUQ_ADS_URL", DefaultSouqAdsURL),
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (u Upstream) ImaluumPage() string {
+	return u.ImaluumBaseURL + "/"
+}
+
+func (u Upstream) ImaluumLogoutPage() string {
+	return u.ImaluumBaseURL + ImaluumLogoutPath
+}
+
+func (u Upstream) ImaluumHomePage() string {
+	return u.ImaluumBaseURL + ImaluumHomePath
+}
+
+func (u Upstream) ImaluumProfilePage() string {
+	return u.ImaluumBaseURL + ImaluumProfilePath
+}
+
+func (u Upstream) ImaluumSchedulePage() string {
+	return u.ImaluumBaseURL + ImaluumSchedulePath
+}
+
+func (u Upstream) ImaluumResultPage() string {
+	return u.ImaluumBaseURL + ImaluumResultPath
+}
+
+func (u Upstream) ImaluumConfirmationSlipPage() string {
+	return u.ImaluumBaseURL + ImaluumConfirmationSlipPath
+}
+
+func (u Upstream) ImaluumExamSlipPage() string {
+	return u.ImaluumBaseURL + ImaluumExamSlipPath
+}
+
+func (u Upstream) ImaluumStudyPlanPage() string {
+	return u.ImaluumBaseURL + ImaluumStudyPlanPath
+}
+
+func (u Upstream) ImaluumStarpointPage() string {
+	return u.ImaluumBaseURL + ImaluumStarpointPath
+}
+
+// ImaluumCasPage is the CAS login form that redirects back to i-Ma'luum home
+func (u Upstream) ImaluumCasPage() string {
+	return u.CASBaseURL + CasLoginPath + "?service=" + url.QueryEscape(u.ImaluumHomePage())
+}
+
+// ImaluumLoginPage is where the CAS login form is posted to
+func (u Upstream) ImaluumLoginPage() string {
+	service := url.QueryEscape(u.ImaluumHomePage())
+	return u.CASBaseURL + CasLoginPath + "?service=" + service + "?service=" + service
+}
+
+// ImaluumCasLogoutPage ends the CAS session. Production has always sent the
+// plain http i-Ma'luum page as service, other upstreams get their own page.
+func (u Upstream) ImaluumCasLogoutPage() string {
+	service := u.ImaluumPage()
+	if u.ImaluumBaseURL == DefaultImaluumBaseURL {
+		service = casLogoutService
+	}
+	return u.CASBaseURL + CasLogoutPath + "?service=" + service
+}
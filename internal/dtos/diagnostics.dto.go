@@ -0,0 +1,9 @@
+package dtos
+
+// ParseWarning describes a table row i-Ma'luum served that could not be parsed
+type ParseWarning struct {
+	Endpoint string `json:"endpoint"`
+	Session  string `json:"session,omitempty"`
+	Reason   string `json:"reason"`
+	Cells    int    `json:"cells"`
+}
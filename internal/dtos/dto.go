@@ -2,5 +2,10 @@ package dtos
 
 type ResponseDTO struct {
 	Data    any    `json:"data"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
+	// RequestID is also sent as X-Request-Id. Cacheable responses are shared
+	// between requests and leave it out, the header names the current one.
+	RequestID string         `json:"request_id,omitempty"`
+	Warnings  []ParseWarning `json:"warnings,omitempty"`
 }
@@ -0,0 +1,8 @@
+package dtos
+
+// GraphQLRequest is a query posted to /api/graphql
+type GraphQLRequest struct {
+	Variables     map[string]any `json:"variables,omitempty"`
+	Query         string         `json:"query" example:"{ schedule(session: \"Sem 1, 2024/2025\") { subjects { courseCode slots { day start end } } } }"`
+	OperationName string         `json:"operationName,omitempty"`
+}
@@ -0,0 +1,16 @@
+package dtos
+
+// Job is a periodic background job, times are unix seconds
+type Job struct {
+	LastRunAt *int64  `json:"last_run_at,omitempty"`
+	LastError *string `json:"last_error,omitempty"`
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	// Key is what the job runs for, the matric number of sync jobs
+	Key             string `json:"key"`
+	IntervalSeconds int64  `json:"interval_seconds"`
+	NextRunAt       int64  `json:"next_run_at"`
+	LastDurationMs  int64  `json:"last_duration_ms"`
+	CreatedAt       int64  `json:"created_at"`
+	Paused          bool   `json:"paused"`
+}
@@ -0,0 +1,23 @@
+package dtos
+
+// Me gathers what the first screen of an app needs. Sections not included
+// are left out.
+type Me struct {
+	Profile   *MeSection `json:"profile,omitempty"`
+	Schedule  *MeSection `json:"schedule,omitempty"`
+	Result    *MeSection `json:"result,omitempty"`
+	Starpoint *MeSection `json:"starpoint,omitempty"`
+}
+
+// MeSection holds either the data of a section, in the shape of its
+// /api/v2 endpoint, or why it could not be fetched
+type MeSection struct {
+	Data  any           `json:"data,omitempty"`
+	Error *SectionError `json:"error,omitempty"`
+}
+
+type SectionError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
@@ -0,0 +1,50 @@
+package dtos
+
+// PushSubscriptionRequest is the JSON of a browser PushSubscription
+type PushSubscriptionRequest struct {
+	Endpoint string   `json:"endpoint"`
+	Keys     PushKeys `json:"keys"`
+}
+
+type PushKeys struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
+type PushUnsubscribeRequest struct {
+	Endpoint string `json:"endpoint"`
+}
+
+type PushSubscription struct {
+	Endpoint  string `json:"endpoint"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type PushPublicKey struct {
+	// PublicKey is the applicationServerKey for pushManager.subscribe()
+	PublicKey string `json:"public_key"`
+}
+
+// PushNotification is the payload of every push message, for the service
+// worker to show
+type PushNotification struct {
+	Data      any    `json:"data,omitempty"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	URL       string `json:"url,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type PushTestResult struct {
+	Delivered int `json:"delivered"`
+}
+
+// ScheduleChange lists the course codes that changed in a session timetable
+type ScheduleChange struct {
+	SessionName string   `json:"session_name"`
+	Added       []string `json:"added,omitempty"`
+	Removed     []string `json:"removed,omitempty"`
+	// Changed courses moved to another time, venue or lecturer
+	Changed []string `json:"changed,omitempty"`
+}
@@ -19,3 +19,102 @@ type Result struct {
 	CourseGrade  string `json:"course_grade"`
 	CourseCredit string `json:"course_credit"`
 }
+
+// Grade is a course grade as printed on the i-Ma'luum result page
+type Grade string
+
+const (
+	GradeA      Grade = "A"
+	GradeAMinus Grade = "A-"
+	GradeBPlus  Grade = "B+"
+	GradeB      Grade = "B"
+	GradeBMinus Grade = "B-"
+	GradeCPlus  Grade = "C+"
+	GradeC      Grade = "C"
+	GradeD      Grade = "D"
+	GradeDMinus Grade = "D-"
+	GradeE      Grade = "E"
+	GradeF      Grade = "F"
+	// Pass/fail courses carry no grade points
+	GradePass       Grade = "P"
+	GradeIncomplete Grade = "I"
+	GradeWithdrawn  Grade = "W"
+	// GradePending is a course whose grade has not been released yet
+	GradePending Grade = "PENDING"
+	// GradeUnknown is anything else, see the raw text
+	GradeUnknown Grade = "UNKNOWN"
+)
+
+// ResultResponseV2 is ResultResponse with the numbers parsed.
+// Numbers are null when i-Ma'luum did not print them, Raw keeps the text as scraped.
+type ResultResponseV2 struct {
+	Gpa          *float64         `json:"gpa"`
+	Cgpa         *float64         `json:"cgpa"`
+	CreditHours  *float64         `json:"credit_hours"`
+	Status       *string          `json:"status"`
+	ID           string           `json:"id"`
+	SessionName  string           `json:"session_name"`
+	SessionQuery string           `json:"session_query"`
+	GpaCheck     *GpaCheck        `json:"gpa_check,omitempty"`
+	Raw          ResultSummaryRaw `json:"raw"`
+	Result       []ResultV2       `json:"result"`
+}
+
+type ResultSummaryRaw struct {
+	Gpa         string `json:"gpa"`
+	Cgpa        string `json:"cgpa"`
+	CreditHours string `json:"credit_hours"`
+	Status      string `json:"status"`
+}
+
+type ResultV2 struct {
+	Credit     *float64  `json:"credit"`
+	ID         string    `json:"id"`
+	CourseCode string    `json:"course_code"`
+	CourseName string    `json:"course_name"`
+	Grade      Grade     `json:"grade"`
+	Raw        ResultRaw `json:"raw"`
+}
+
+type ResultRaw struct {
+	Grade  string `json:"grade"`
+	Credit string `json:"credit"`
+}
+
+// GpaCheckStatus is the outcome of recomputing a session GPA from its course rows
+type GpaCheckStatus string
+
+const (
+	GpaCheckMatch    GpaCheckStatus = "MATCH"
+	GpaCheckMismatch GpaCheckStatus = "MISMATCH"
+	// GpaCheckUnverified is a session without a scraped GPA or without graded courses
+	GpaCheckUnverified GpaCheckStatus = "UNVERIFIED"
+)
+
+// ExclusionReason says why a course does not count towards the GPA
+type ExclusionReason string
+
+const (
+	ExcludedPending    ExclusionReason = "PENDING"
+	ExcludedIncomplete ExclusionReason = "INCOMPLETE"
+	ExcludedWithdrawn  ExclusionReason = "WITHDRAWN"
+	ExcludedPassFail   ExclusionReason = "PASS_FAIL"
+	ExcludedUnknown    ExclusionReason = "UNKNOWN_GRADE"
+	ExcludedNoCredit   ExclusionReason = "NO_CREDIT"
+)
+
+// GpaCheck compares the GPA i-Ma'luum printed with the GPA recomputed from the course rows
+type GpaCheck struct {
+	ScrapedGpa      *float64         `json:"scraped_gpa"`
+	ComputedGpa     *float64         `json:"computed_gpa"`
+	Difference      *float64         `json:"difference"`
+	Status          GpaCheckStatus   `json:"status"`
+	Excluded        []ExcludedCourse `json:"excluded"`
+	ComputedCredits float64          `json:"computed_credits"`
+}
+
+type ExcludedCourse struct {
+	CourseCode string          `json:"course_code"`
+	Grade      Grade           `json:"grade"`
+	Reason     ExclusionReason `json:"reason"`
+}
@@ -25,3 +25,31 @@ type ScheduleResponse struct {
 	SessionQuery string            `json:"session_query"`
 	Schedule     []ScheduleSubject `json:"schedule"`
 }
+
+// ScheduleResponseV2 is ScheduleResponse with the slots in 24-hour time and
+// no timestamps tied to the day of the scrape
+type ScheduleResponseV2 struct {
+	ID           string              `json:"id"`
+	SessionName  string              `json:"session_name"`
+	SessionQuery string              `json:"session_query"`
+	Schedule     []ScheduleSubjectV2 `json:"schedule"`
+}
+
+type ScheduleSubjectV2 struct {
+	ID          string         `json:"id"`
+	CourseCode  string         `json:"course_code"`
+	CourseName  string         `json:"course_name"`
+	Venue       string         `json:"venue"`
+	Lecturer    string         `json:"lecturer"`
+	Slots       []ScheduleSlot `json:"slots"`
+	CreditHours float64        `json:"credit_hours"`
+	Section     uint32         `json:"section"`
+}
+
+// ScheduleSlot is a weekly class meeting. Day counts from Sunday at 0, 7 is a
+// day i-Ma'luum printed but could not be read.
+type ScheduleSlot struct {
+	Start string `json:"start" example:"08:30"`
+	End   string `json:"end" example:"09:50"`
+	Day   uint8  `json:"day"`
+}
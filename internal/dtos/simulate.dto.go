@@ -0,0 +1,42 @@
+package dtos
+
+type SimulateRequest struct {
+	// TargetCgpa asks for the average needed to reach this CGPA
+	TargetCgpa *float64 `json:"target_cgpa,omitempty"`
+	// RemainingCredits are credit hours still to be taken after Courses.
+	// Without it the target is worked out over Courses alone.
+	RemainingCredits *float64 `json:"remaining_credits,omitempty"`
+	// Results is the history to simulate on, scraped from i-Ma'luum when empty
+	Results []ResultResponse  `json:"results,omitempty"`
+	Courses []SimulatedCourse `json:"courses"`
+}
+
+type SimulatedCourse struct {
+	CourseCode string  `json:"course_code,omitempty"`
+	Grade      Grade   `json:"grade"`
+	Credit     float64 `json:"credit"`
+}
+
+type SimulateResponse struct {
+	Target      *SimulatedTarget  `json:"target,omitempty"`
+	GradePoints map[Grade]float64 `json:"grade_points"`
+	Current     Projection        `json:"current"`
+	Projected   Projection        `json:"projected"`
+}
+
+// Projection is a GPA position. Gpa covers the simulated courses only and is
+// not set for the current position.
+type Projection struct {
+	Gpa         *float64 `json:"gpa,omitempty"`
+	Cgpa        *float64 `json:"cgpa"`
+	CreditHours float64  `json:"credit_hours"`
+}
+
+type SimulatedTarget struct {
+	// RequiredAverage is the lowest average grade point over RemainingCredits
+	// that reaches Cgpa, zero if it is already reached
+	RequiredAverage  float64 `json:"required_average"`
+	Cgpa             float64 `json:"cgpa"`
+	RemainingCredits float64 `json:"remaining_credits"`
+	Achievable       bool    `json:"achievable"`
+}
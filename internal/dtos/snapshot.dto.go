@@ -0,0 +1,19 @@
+package dtos
+
+import "encoding/json"
+
+// Snapshot is a stored scrape. Identical scrapes in a row share one snapshot,
+// FetchedAt moves forward while CreatedAt is when the content first appeared.
+type Snapshot struct {
+	Payload   json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	Kind      string          `json:"kind"`
+	Hash      string          `json:"hash"`
+	ID        int64           `json:"id"`
+	CreatedAt int64           `json:"created_at"`
+	FetchedAt int64           `json:"fetched_at"`
+}
+
+type SnapshotHistory struct {
+	Snapshots []Snapshot `json:"snapshots"`
+	Enabled   bool       `json:"enabled"`
+}
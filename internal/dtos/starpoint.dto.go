@@ -16,3 +16,11 @@ type StarpointProgram struct {
 	Level     string  `json:"level"`
 	Points    float32 `json:"points"`
 }
+
+// StarpointV2 is Starpoint with the average spelled right
+type StarpointV2 struct {
+	ID                string             `json:"id"`
+	CumulativeAverage float64            `json:"cumulative_average"`
+	TotalPoints       float64            `json:"total_points"`
+	Programs          []StarpointProgram `json:"programs"`
+}
@@ -0,0 +1,43 @@
+package dtos
+
+// TranscriptSummary aggregates the results of every session.
+// GradeDistribution counts every attempt, including failed ones that were repeated.
+type TranscriptSummary struct {
+	GradeDistribution map[Grade]int    `json:"grade_distribution"`
+	Cgpa              *float64         `json:"cgpa"`
+	Repeated          []RepeatedCourse `json:"repeated"`
+	Trend             []SemesterTrend  `json:"trend"`
+	Courses           CourseCounts     `json:"courses"`
+	CreditHoursEarned float64          `json:"credit_hours_earned"`
+}
+
+// CourseCounts counts distinct course codes by the outcome of their latest attempt
+type CourseCounts struct {
+	Total     int `json:"total"`
+	Passed    int `json:"passed"`
+	Failed    int `json:"failed"`
+	Withdrawn int `json:"withdrawn"`
+	Pending   int `json:"pending"`
+	Unknown   int `json:"unknown"`
+	Repeated  int `json:"repeated"`
+}
+
+// RepeatedCourse is a course code taken in more than one session, oldest attempt first
+type RepeatedCourse struct {
+	CourseCode string          `json:"course_code"`
+	CourseName string          `json:"course_name"`
+	Attempts   []CourseAttempt `json:"attempts"`
+}
+
+type CourseAttempt struct {
+	SessionName string `json:"session_name"`
+	Grade       Grade  `json:"grade"`
+}
+
+// SemesterTrend is the GPA and CGPA i-Ma'luum printed for a session
+type SemesterTrend struct {
+	Gpa         *float64 `json:"gpa"`
+	Cgpa        *float64 `json:"cgpa"`
+	CreditHours *float64 `json:"credit_hours"`
+	SessionName string   `json:"session_name"`
+}
@@ -0,0 +1,11 @@
+package dtos
+
+// SyncProgress is a step of a schedule or result scrape, as streamed by
+// /api/sync/{kind}
+type SyncProgress struct {
+	Stage   string `json:"stage"`
+	Message string `json:"message"`
+	Session string `json:"session,omitempty"`
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+}
@@ -0,0 +1,42 @@
+package dtos
+
+type WebhookRequest struct {
+	URL string `json:"url"`
+}
+
+type Webhook struct {
+	LastPolledAt *int64 `json:"last_polled_at,omitempty"`
+	URL          string `json:"url"`
+	// Secret signs every delivery, it is only returned when the webhook is registered
+	Secret    string `json:"secret,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// WebhookEvent is the body of every webhook delivery
+type WebhookEvent struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	MatricNo  string         `json:"matric_no"`
+	Releases  []GradeRelease `json:"releases"`
+	CreatedAt int64          `json:"created_at"`
+}
+
+// GradeRelease is a course grade that appeared or changed since the last poll
+type GradeRelease struct {
+	SessionName string `json:"session_name"`
+	CourseCode  string `json:"course_code"`
+	CourseName  string `json:"course_name"`
+	Grade       Grade  `json:"grade"`
+	// Previous is the grade before the change, empty for a course not seen before
+	Previous Grade `json:"previous,omitempty"`
+}
+
+// DeadLetter is a delivery that failed every attempt
+type DeadLetter struct {
+	URL       string `json:"url"`
+	Event     string `json:"event"`
+	Error     string `json:"error"`
+	ID        int64  `json:"id"`
+	Attempts  int    `json:"attempts"`
+	CreatedAt int64  `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package errors
+
+var (
+	ErrAdminUnauthorized = &CustomError{
+		Message:    "Invalid admin token",
+		StatusCode: 401,
+		Code:       CodeInvalidToken,
+	}
+
+	ErrJobNotFound = &CustomError{
+		Message:    "Job not found",
+		StatusCode: 404,
+		Code:       CodeNotFound,
+	}
+)
@@ -4,10 +4,12 @@ var (
 	ErrFailedToQueryDB = &CustomError{
 		Message:    "Failed to query database",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 
 	ErrFailedToMapDBRows = &CustomError{
 		Message:    "Failed to map database rows",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 )
@@ -4,30 +4,36 @@ var (
 	ErrLoginFailed = &CustomError{
 		Message:    "Username or password is incorrect",
 		StatusCode: 401,
+		Code:       CodeInvalidCredentials,
 	}
 
 	ErrURLParseFailed = &CustomError{
 		Message:    "Failed to parse URL",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 
 	ErrCookieJarCreationFailed = &CustomError{
 		Message:    "Failed to create cookie jar",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 
 	ErrEncryptionFailed = &CustomError{
 		Message:    "Failed to encrypt password",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 
 	ErrFailedToCloseRequestBody = &CustomError{
 		Message:    "Failed to close request body",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 
 	ErrFailedToCloseResponseBody = &CustomError{
 		Message:    "Failed to close response body",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 )
@@ -3,4 +3,5 @@ package errors
 var ErrDownloadFailed = &CustomError{
 	Message:    "Failed to download the file",
 	StatusCode: 500,
+	Code:       CodeUpstreamError,
 }
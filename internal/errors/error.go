@@ -1,14 +1,51 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"strings"
 
+	"github.com/bytedance/sonic"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
+// Codes tell failures apart for clients, they never change once released
+const (
+	CodeOK                      = "OK"
+	CodeInternal                = "INTERNAL_ERROR"
+	CodeInvalidRequest          = "INVALID_REQUEST"
+	CodeUnauthenticated         = "UNAUTHENTICATED"
+	CodeInvalidToken            = "INVALID_TOKEN"
+	CodeInvalidCredentials      = "INVALID_CREDENTIALS"
+	CodeSessionExpired          = "SESSION_EXPIRED"
+	CodeUpstreamUnavailable     = "UPSTREAM_UNAVAILABLE"
+	CodeUpstreamError           = "UPSTREAM_ERROR"
+	CodeNotFound                = "NOT_FOUND"
+	CodeScheduleEmpty           = "SCHEDULE_EMPTY"
+	CodeResultEmpty             = "RESULT_EMPTY"
+	CodeStarpointEmpty          = "STARPOINT_EMPTY"
+	CodeInvalidGrade            = "INVALID_GRADE"
+	CodeNothingToSimulate       = "NOTHING_TO_SIMULATE"
+	CodeInvalidSnapshotKind     = "INVALID_SNAPSHOT_KIND"
+	CodeInvalidSection          = "INVALID_SECTION"
+	CodeInvalidWebhookURL       = "INVALID_WEBHOOK_URL"
+	CodeInvalidPushSubscription = "INVALID_PUSH_SUBSCRIPTION"
+	CodePushDisabled            = "PUSH_DISABLED"
+	CodePushFailed              = "PUSH_FAILED"
+	CodeInvalidSyncKind         = "INVALID_SYNC_KIND"
+)
+
+// ProblemContentType is the RFC 7807 media type errors are rendered in when
+// the client accepts it
+const ProblemContentType = "application/problem+json"
+
 type CustomError struct {
 	OriginalErr error  `json:"-"`
+	Details     any    `json:"details,omitempty"`
+	Code        string `json:"code"`
 	Message     string `json:"message,omitempty"`
 	StatusCode  int    `json:"status,omitempty"`
 }
@@ -21,6 +58,11 @@ func (e *CustomError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the original error
+func (e *CustomError) Unwrap() error {
+	return e.OriginalErr
+}
+
 // GetStatusCode returns the status code
 func (e *CustomError) GetStatusCode() int {
 	return e.StatusCode
@@ -35,44 +77,142 @@ func (e *CustomError) Render(w http.ResponseWriter, r *http.Request) error {
 func Wrap(predefError *CustomError, originalErr error) *CustomError {
 	return &CustomError{
 		OriginalErr: originalErr,
+		Details:     predefError.Details,
+		Code:        predefError.Code,
 		Message:     predefError.Message,
 		StatusCode:  predefError.StatusCode,
 	}
 }
 
+// WithDetails returns a copy of a predefined CustomError carrying details,
+// such as the offending field, for the client
+func WithDetails(predefError *CustomError, details any) *CustomError {
+	err := Wrap(predefError, predefError.OriginalErr)
+	err.Details = details
+	return err
+}
+
+// From returns the CustomError in err's chain, or ErrInternal wrapping err
+func From(err error) *CustomError {
+	var customErr *CustomError
+	if stderrors.As(err, &customErr) && customErr != nil {
+		return customErr
+	}
+	return Wrap(ErrInternal, err)
+}
+
+// Body is the JSON body of every error response
+type Body struct {
+	Details   any    `json:"details,omitempty"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	Status    int    `json:"status"`
+}
+
+// Problem is the RFC 7807 body of an error response. Code, request_id and
+// details are extension members shared with Body.
+type Problem struct {
+	Details   any    `json:"details,omitempty"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Detail    string `json:"detail"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	Status    int    `json:"status"`
+}
+
+// Render writes err as JSON, or as problem+json when the Accept header asks
+// for it. Errors that are not a CustomError are reported as INTERNAL_ERROR
+// without leaking their message.
 func Render(w http.ResponseWriter, r *http.Request, err error) {
-	re, ok := err.(*CustomError)
-	if !ok {
-		render.Status(r, http.StatusInternalServerError)
-		render.Render(w, r, re)
+	customErr := From(err)
+
+	status := customErr.GetStatusCode()
+	if status == 0 {
+		status = http.StatusInternalServerError
 	}
-	render.Status(r, re.GetStatusCode())
-	render.Render(w, r, re)
+	requestID := middleware.GetReqID(r.Context())
+
+	var body any = &Body{
+		Details:   customErr.Details,
+		Code:      customErr.Code,
+		Message:   customErr.Message,
+		RequestID: requestID,
+		Status:    status,
+	}
+	contentType := "application/json"
+
+	if acceptsProblem(r) {
+		body = &Problem{
+			Details:   customErr.Details,
+			Type:      "urn:gomaluum:error:" + strings.ToLower(customErr.Code),
+			Title:     http.StatusText(status),
+			Detail:    customErr.Message,
+			Instance:  r.URL.Path,
+			Code:      customErr.Code,
+			RequestID: requestID,
+			Status:    status,
+		}
+		contentType = ProblemContentType
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = sonic.ConfigFastest.NewEncoder(w).Encode(body)
+}
+
+func acceptsProblem(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == ProblemContentType {
+			return true
+		}
+	}
+	return false
 }
 
 var (
+	ErrInternal = &CustomError{
+		Message:    "Something went wrong, please try again later",
+		StatusCode: 500,
+		Code:       CodeInternal,
+	}
+
 	ErrInvalidRequest = &CustomError{
 		Message:    "Invalid request body",
 		StatusCode: 400,
+		Code:       CodeInvalidRequest,
+	}
+
+	ErrMissingToken = &CustomError{
+		Message:    "Authorization header must carry a Bearer token",
+		StatusCode: 401,
+		Code:       CodeUnauthenticated,
 	}
 
 	ErrInvalidToken = &CustomError{
 		Message:    "Invalid token",
 		StatusCode: 401,
+		Code:       CodeInvalidToken,
 	}
 
 	ErrFailedToGoToURL = &CustomError{
 		Message:    "Failed to go to URL",
 		StatusCode: 500,
+		Code:       CodeUpstreamError,
 	}
 
 	ErrFailedToEncodeResponse = &CustomError{
 		Message:    "Failed to encode response",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 
 	ErrFailedToCreateHTTPClient = &CustomError{
 		Message:    "Failed to create HTTP client",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 )
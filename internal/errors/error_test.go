@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderError(t *testing.T, accept string, err error) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/result", nil)
+	req.Header.Set("Accept", accept)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.RequestIDKey, "req-1"))
+
+	rec := httptest.NewRecorder()
+	Render(rec, req, err)
+
+	var body map[string]any
+	require.NoError(t, sonic.Unmarshal(rec.Body.Bytes(), &body))
+	return rec, body
+}
+
+func TestRender(t *testing.T) {
+	rec, body := renderError(t, "", WithDetails(ErrInvalidSnapshotKind, map[string]any{"kinds": []string{"profile"}}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, CodeInvalidSnapshotKind, body["code"])
+	assert.Equal(t, ErrInvalidSnapshotKind.Message, body["message"])
+	assert.Equal(t, "req-1", body["request_id"])
+	assert.EqualValues(t, http.StatusBadRequest, body["status"])
+	assert.Equal(t, map[string]any{"kinds": []any{"profile"}}, body["details"])
+
+	// The predefined error is left alone
+	assert.Nil(t, ErrInvalidSnapshotKind.Details)
+}
+
+func TestRenderWrapped(t *testing.T) {
+	err := fmt.Errorf("refresh: %w", Wrap(ErrUpstreamUnavailable, stderrors.New("circuit open")))
+
+	rec, body := renderError(t, "", err)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, CodeUpstreamUnavailable, body["code"])
+}
+
+func TestRenderPlainError(t *testing.T) {
+	rec, body := renderError(t, "", stderrors.New("database is locked"))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, CodeInternal, body["code"])
+	assert.Equal(t, ErrInternal.Message, body["message"])
+}
+
+func TestRenderProblem(t *testing.T) {
+	rec, body := renderError(t, "application/problem+json, application/json;q=0.9", ErrLoginFailed)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "urn:gomaluum:error:invalid_credentials", body["type"])
+	assert.Equal(t, "Unauthorized", body["title"])
+	assert.Equal(t, ErrLoginFailed.Message, body["detail"])
+	assert.Equal(t, "/api/result", body["instance"])
+	assert.Equal(t, CodeInvalidCredentials, body["code"])
+	assert.Equal(t, "req-1", body["request_id"])
+}
@@ -0,0 +1,7 @@
+package errors
+
+var ErrUnknownSession = &CustomError{
+	Message:    "No such session, pick one listed in sessions",
+	StatusCode: 404,
+	Code:       CodeNotFound,
+}
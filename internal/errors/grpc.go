@@ -0,0 +1,119 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/bytedance/sonic"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain names this API in the ErrorInfo of gRPC errors
+const Domain = "gomaluum"
+
+// GRPCStatus lets gRPC send e as a status whose code follows StatusCode and
+// whose ErrorInfo carries Code as the reason, the same code HTTP clients get.
+// Details, when any, travel JSON encoded in the metadata.
+func (e *CustomError) GRPCStatus() *status.Status {
+	st := status.New(grpcCode(e.StatusCode), e.Message)
+
+	info := &errdetails.ErrorInfo{
+		Reason:   e.Code,
+		Domain:   Domain,
+		Metadata: map[string]string{"http_status": strconv.Itoa(e.StatusCode)},
+	}
+	if e.Details != nil {
+		if details, err := sonic.ConfigFastest.MarshalToString(e.Details); err == nil {
+			info.Metadata["details"] = details
+		}
+	}
+
+	if withInfo, err := st.WithDetails(info); err == nil {
+		return withInfo
+	}
+	return st
+}
+
+// UnaryServerInterceptor sends the errors of handlers that are not a
+// CustomError as INTERNAL_ERROR, without their message
+func UnaryServerInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		// Already a status, either set by the handler or a CustomError
+		return resp, err
+	}
+	return resp, From(err)
+}
+
+// FromGRPC turns an error returned by a gRPC call back into the CustomError
+// the server sent, or ErrInternal when the server sent none
+func FromGRPC(err error) *CustomError {
+	st, ok := status.FromError(err)
+	if !ok {
+		return From(err)
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != Domain {
+			continue
+		}
+
+		customErr := &CustomError{
+			OriginalErr: err,
+			Code:        info.GetReason(),
+			Message:     st.Message(),
+			StatusCode:  http.StatusInternalServerError,
+		}
+		if httpStatus, err := strconv.Atoi(info.GetMetadata()["http_status"]); err == nil {
+			customErr.StatusCode = httpStatus
+		}
+		if details, ok := info.GetMetadata()["details"]; ok {
+			var decoded any
+			if sonic.ConfigFastest.UnmarshalFromString(details, &decoded) == nil {
+				customErr.Details = decoded
+			}
+		}
+		return customErr
+	}
+
+	return Wrap(ErrInternal, err)
+}
+
+// grpcCode maps an HTTP status to the closest gRPC code
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	switch {
+	case httpStatus >= 400 && httpStatus < 500:
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCStatus(t *testing.T) {
+	tests := []struct {
+		err  *CustomError
+		code codes.Code
+	}{
+		{ErrInvalidRequest, codes.InvalidArgument},
+		{ErrLoginFailed, codes.Unauthenticated},
+		{ErrSessionExpired, codes.Unauthenticated},
+		{ErrWebhookNotFound, codes.NotFound},
+		{ErrUpstreamUnavailable, codes.Unavailable},
+		{ErrFailedToSendPush, codes.Unavailable},
+		{ErrCookieJarCreationFailed, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Code, func(t *testing.T) {
+			st := status.Convert(tt.err)
+			assert.Equal(t, tt.code, st.Code())
+			assert.Equal(t, tt.err.Message, st.Message())
+
+			require.Len(t, st.Details(), 1)
+			info := st.Details()[0].(*errdetails.ErrorInfo)
+			assert.Equal(t, tt.err.Code, info.GetReason())
+			assert.Equal(t, Domain, info.GetDomain())
+		})
+	}
+}
+
+func TestFromGRPC(t *testing.T) {
+	sent := WithDetails(ErrInvalidRequest, map[string]any{"field": "username"})
+
+	// What a client gets back once the status went over the wire
+	proto := status.Convert(sent).Proto()
+	received := FromGRPC(status.ErrorProto(proto))
+
+	assert.Equal(t, CodeInvalidRequest, received.Code)
+	assert.Equal(t, sent.Message, received.Message)
+	assert.Equal(t, 400, received.StatusCode)
+	assert.Equal(t, map[string]any{"field": "username"}, received.Details)
+
+	// Statuses from elsewhere are internal
+	assert.Equal(t, CodeInternal, FromGRPC(status.Error(codes.Unavailable, "no route")).Code)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	call := func(err error) error {
+		_, err = UnaryServerInterceptor(context.Background(), nil, nil, func(context.Context, any) (any, error) {
+			return nil, err
+		})
+		return err
+	}
+
+	assert.NoError(t, call(nil))
+	assert.Equal(t, codes.Unauthenticated, status.Code(call(ErrLoginFailed)))
+	assert.Equal(t, codes.NotFound, status.Code(call(status.Error(codes.NotFound, "gone"))))
+
+	plain := status.Convert(call(stderrors.New("database is locked")))
+	assert.Equal(t, codes.Internal, plain.Code())
+	assert.Equal(t, ErrInternal.Message, plain.Message())
+}
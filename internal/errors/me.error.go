@@ -0,0 +1,7 @@
+package errors
+
+var ErrInvalidSection = &CustomError{
+	Message:    "include must list profile, schedule, result or starpoint",
+	StatusCode: 400,
+	Code:       CodeInvalidSection,
+}
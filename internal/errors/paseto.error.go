@@ -4,25 +4,36 @@ var (
 	ErrFailedToGeneratePASETO = &CustomError{
 		Message:    "Failed to generate PASETO token",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 
 	ErrFailedToDecodePASETO = &CustomError{
 		Message:    "Failed to decode PASETO token",
 		StatusCode: 500,
+		Code:       CodeInternal,
+	}
+
+	ErrSessionExpired = &CustomError{
+		Message:    "i-Ma'luum refused to renew the session, please log in again",
+		StatusCode: 401,
+		Code:       CodeSessionExpired,
 	}
 
 	ErrInvalidPASETOIssuer = &CustomError{
 		Message:    "Invalid PASETO issuer",
 		StatusCode: 401,
+		Code:       CodeInvalidToken,
 	}
 
 	ErrFailedToCreatePASETOPublicKey = &CustomError{
 		Message:    "Failed to create PASETO public key",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 
 	ErrFailedToCreatePASETOPrivateKey = &CustomError{
 		Message:    "Failed to create PASETO private key",
 		StatusCode: 500,
+		Code:       CodeInternal,
 	}
 )
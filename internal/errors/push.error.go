@@ -0,0 +1,27 @@
+package errors
+
+var (
+	ErrPushDisabled = &CustomError{
+		Message:    "Push notifications are not configured on this server",
+		StatusCode: 503,
+		Code:       CodePushDisabled,
+	}
+
+	ErrInvalidPushSubscription = &CustomError{
+		Message:    "Invalid push subscription",
+		StatusCode: 400,
+		Code:       CodeInvalidPushSubscription,
+	}
+
+	ErrPushSubscriptionNotFound = &CustomError{
+		Message:    "Push subscription not found",
+		StatusCode: 404,
+		Code:       CodeNotFound,
+	}
+
+	ErrFailedToSendPush = &CustomError{
+		Message:    "Failed to send push notification",
+		StatusCode: 502,
+		Code:       CodePushFailed,
+	}
+)
@@ -3,4 +3,17 @@ package errors
 var ErrResultIsEmpty = &CustomError{
 	Message:    "Result is empty",
 	StatusCode: 500,
+	Code:       CodeResultEmpty,
+}
+
+var ErrInvalidGrade = &CustomError{
+	Message:    "Grade is not in the grade point table",
+	StatusCode: 400,
+	Code:       CodeInvalidGrade,
+}
+
+var ErrNothingToSimulate = &CustomError{
+	Message:    "Add at least one course or remaining credit hours to simulate",
+	StatusCode: 400,
+	Code:       CodeNothingToSimulate,
 }
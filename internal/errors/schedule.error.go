@@ -3,4 +3,5 @@ package errors
 var ErrScheduleIsEmpty = &CustomError{
 	Message:    "Schedule is empty",
 	StatusCode: 404,
+	Code:       CodeScheduleEmpty,
 }
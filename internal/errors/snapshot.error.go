@@ -0,0 +1,15 @@
+package errors
+
+var (
+	ErrSnapshotNotFound = &CustomError{
+		Message:    "No snapshot stored, enable snapshots and fetch it once while i-Ma'luum is up",
+		StatusCode: 404,
+		Code:       CodeNotFound,
+	}
+
+	ErrInvalidSnapshotKind = &CustomError{
+		Message:    "Snapshot kind must be one of profile, schedule, result or starpoint",
+		StatusCode: 400,
+		Code:       CodeInvalidSnapshotKind,
+	}
+)
@@ -3,4 +3,5 @@ package errors
 var ErrNoStarpoint = &CustomError{
 	Message:    "User has no starpoint",
 	StatusCode: 404,
+	Code:       CodeStarpointEmpty,
 }
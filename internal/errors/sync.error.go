@@ -0,0 +1,7 @@
+package errors
+
+var ErrInvalidSyncKind = &CustomError{
+	Message:    "Only schedule and result can be synced",
+	StatusCode: 400,
+	Code:       CodeInvalidSyncKind,
+}
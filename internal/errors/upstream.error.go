@@ -0,0 +1,19 @@
+package errors
+
+import "github.com/nrmnqdds/gomaluum/pkg/upstream"
+
+var ErrUpstreamUnavailable = &CustomError{
+	Message:    "i-Ma'luum is currently unavailable, please try again later",
+	StatusCode: 503,
+	Code:       CodeUpstreamUnavailable,
+}
+
+// WrapUpstream maps a failed call to i-Ma'luum to the error rendered to the client.
+// Calls rejected by the rate limiter or circuit breaker fail fast with a 503,
+// anything else falls back to the given error.
+func WrapUpstream(err error, fallback *CustomError) *CustomError {
+	if upstream.IsUnavailable(err) {
+		return Wrap(ErrUpstreamUnavailable, err)
+	}
+	return fallback
+}
@@ -0,0 +1,21 @@
+package errors
+
+var (
+	ErrInvalidWebhookURL = &CustomError{
+		Message:    "Webhook URL must be an absolute https URL on a public address",
+		StatusCode: 400,
+		Code:       CodeInvalidWebhookURL,
+	}
+
+	ErrWebhookNotFound = &CustomError{
+		Message:    "No webhook registered",
+		StatusCode: 404,
+		Code:       CodeNotFound,
+	}
+
+	ErrFailedToEncryptCredentials = &CustomError{
+		Message:    "Failed to store credentials for background polling",
+		StatusCode: 500,
+		Code:       CodeInternal,
+	}
+)
@@ -0,0 +1,204 @@
+// Package fakeimaluum serves captured, anonymised i-Ma'luum and CAS pages from
+// a local httptest server, so the scraper can be tested without reaching IIUM.
+//
+// Both i-Ma'luum and CAS are served from the same origin. Pages behind login
+// require a MOD_AUTH_CAS cookie issued through the CAS login form (or Login),
+// anything else is redirected to the CAS login page like the real site does.
+package fakeimaluum
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/lucsky/cuid"
+	"github.com/nrmnqdds/gomaluum/internal/constants"
+)
+
+// Credentials accepted by the fake CAS login form
+const (
+	Username = "2110000"
+	Password = "correct-horse-battery-staple"
+)
+
+const (
+	smartcardImagePath = "/smartcard/"
+	souqAdsPath        = "/embeded"
+)
+
+//go:embed fixtures/*
+var fixtures embed.FS
+
+// Fixture returns the raw content of a captured page, e.g. "schedule.html"
+func Fixture(name string) []byte {
+	content, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("fakeimaluum: unknown fixture %q", name))
+	}
+	return content
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	tickets  map[string]string // CAS service ticket -> username
+	sessions map[string]string // MOD_AUTH_CAS -> username
+	logins   int
+}
+
+// New starts a fake i-Ma'luum. Callers must Close it.
+func New() *Server {
+	s := &Server{
+		tickets:  make(map[string]string),
+		sessions: make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+
+	// CAS
+	mux.HandleFunc("GET "+constants.CasLoginPath, s.page("login.html", "text/html"))
+	mux.HandleFunc("POST "+constants.CasLoginPath, s.handleLogin)
+	mux.HandleFunc("GET "+constants.CasLogoutPath, s.handleLogout)
+
+	// i-Ma'luum
+	mux.HandleFunc("GET "+constants.ImaluumHomePath, s.handleHome)
+	mux.HandleFunc("GET "+constants.ImaluumProfilePath, s.authenticated(s.page("profile.html", "text/html")))
+	mux.HandleFunc("GET "+constants.ImaluumSchedulePath, s.authenticated(s.page("schedule.html", "text/html")))
+	mux.HandleFunc("GET "+constants.ImaluumResultPath, s.authenticated(s.page("result.html", "text/html")))
+	mux.HandleFunc("GET "+constants.ImaluumStarpointPath, s.authenticated(s.page("starpoint.html", "text/html")))
+	mux.HandleFunc("GET "+constants.ImaluumExamSlipPath, s.authenticated(s.page("exam-slip.pdf", "application/pdf")))
+	mux.HandleFunc("GET "+constants.ImaluumStudyPlanPath, s.authenticated(s.page("study-plan.pdf", "application/pdf")))
+
+	// Souq
+	mux.HandleFunc("GET "+souqAdsPath, s.page("ads.html", "text/html"))
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Upstream points every IIUM service at the fake server
+func (s *Server) Upstream() constants.Upstream {
+	return constants.Upstream{
+		ImaluumBaseURL:        s.URL,
+		CASBaseURL:            s.URL,
+		SmartcardImageBaseURL: s.URL + smartcardImagePath,
+		SouqAdsURL:            s.URL + souqAdsPath,
+	}
+}
+
+// Login issues a MOD_AUTH_CAS cookie for username without going through CAS
+func (s *Server) Login(username string) string {
+	cookie := cuid.New()
+
+	s.mu.Lock()
+	s.sessions[cookie] = username
+	s.mu.Unlock()
+
+	return cookie
+}
+
+// LoggedIn reports whether cookie is a live session
+func (s *Server) LoggedIn(cookie string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.sessions[cookie]
+	return ok
+}
+
+func (s *Server) page(name, contentType string) http.HandlerFunc {
+	content := Fixture(name)
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(content)
+	}
+}
+
+// authenticated redirects to CAS unless the request carries a live MOD_AUTH_CAS cookie
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("MOD_AUTH_CAS")
+		if err != nil || !s.LoggedIn(cookie.Value) {
+			http.Redirect(w, r, s.Upstream().ImaluumCasPage(), http.StatusFound)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Logins counts the CAS forms posted with the right credentials
+func (s *Server) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logins
+}
+
+// handleLogin checks the posted CAS form and redirects to the service with a ticket
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.PostForm.Get("username") != Username || r.PostForm.Get("password") != Password ||
+		r.PostForm.Get("execution") == "" || r.PostForm.Get("_eventId") != "submit" {
+		// CAS re-renders the form on bad credentials
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write(Fixture("login.html"))
+		return
+	}
+
+	ticket := "ST-" + cuid.New()
+
+	s.mu.Lock()
+	s.tickets[ticket] = r.PostForm.Get("username")
+	s.logins++
+	s.mu.Unlock()
+
+	http.Redirect(w, r, s.Upstream().ImaluumHomePage()+"?ticket="+url.QueryEscape(ticket), http.StatusFound)
+}
+
+// handleHome validates a service ticket and starts an i-Ma'luum session
+func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
+	if ticket := r.URL.Query().Get("ticket"); ticket != "" {
+		s.mu.Lock()
+		username, ok := s.tickets[ticket]
+		delete(s.tickets, ticket)
+		s.mu.Unlock()
+
+		if !ok {
+			http.Redirect(w, r, s.Upstream().ImaluumCasPage(), http.StatusFound)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     "MOD_AUTH_CAS",
+			Value:    s.Login(username),
+			Path:     "/",
+			HttpOnly: true,
+		})
+		s.page("home.html", "text/html")(w, r)
+		return
+	}
+
+	s.authenticated(s.page("home.html", "text/html"))(w, r)
+}
+
+// handleLogout ends the session of the MOD_AUTH_CAS cookie, if any
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie("MOD_AUTH_CAS"); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write(Fixture("login.html"))
+}
@@ -0,0 +1,242 @@
+// Package graph serves the scraped i-Ma'luum data over GraphQL.
+//
+// Resolvers scrape lazily, so a query only costs the upstream pages behind
+// the fields it selects, and share their scrapes within a request, so
+// sessions and schedule(session:) read the session dropdown once.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/gpa"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+)
+
+//go:embed schema.graphql
+var Schema string
+
+const (
+	maxDepth       = 8
+	maxQueryLength = 8 << 10
+)
+
+// Scraper is the part of imaluum.Client the resolvers use
+type Scraper interface {
+	Profile(ctx context.Context, cookie string) (*dtos.Profile, error)
+	Sessions(ctx context.Context, cookie string) ([]imaluum.Session, error)
+	ScheduleFor(ctx context.Context, cookie string, sessions []imaluum.Session) ([]dtos.ScheduleResponse, error)
+	ResultSessions(ctx context.Context, cookie string) ([]imaluum.Session, error)
+	ResultsFor(ctx context.Context, cookie string, sessions []imaluum.Session) ([]dtos.ResultResponse, error)
+	Starpoint(ctx context.Context, cookie string) (*dtos.Starpoint, error)
+}
+
+// Resolver resolves the Query type
+type Resolver struct {
+	Scraper     Scraper
+	GradePoints gpa.Table
+}
+
+// NewSchema binds Schema to the resolver
+func NewSchema(resolver *Resolver) (*graphql.Schema, error) {
+	return graphql.ParseSchema(Schema, resolver,
+		graphql.UseFieldResolvers(),
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(maxDepth),
+		graphql.MaxQueryLength(maxQueryLength),
+	)
+}
+
+// request holds the scrapes of one GraphQL request
+type request struct {
+	calls  map[string]*call
+	cookie string
+	mu     sync.Mutex
+}
+
+type call struct {
+	value any
+	err   error
+	done  chan struct{}
+}
+
+type requestKey struct{}
+
+// WithCookie makes queries executed with ctx scrape as the owner of the
+// MOD_AUTH_CAS cookie
+func WithCookie(ctx context.Context, cookie string) context.Context {
+	return context.WithValue(ctx, requestKey{}, &request{
+		calls:  make(map[string]*call),
+		cookie: cookie,
+	})
+}
+
+// once runs scrape the first time key is asked for in the request of ctx and
+// hands its outcome to every later caller
+func once[T any](ctx context.Context, key string, scrape func(cookie string) (T, error)) (T, error) {
+	var zero T
+
+	req, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return zero, queryError{errors.ErrMissingToken}
+	}
+
+	req.mu.Lock()
+	c, ok := req.calls[key]
+	if !ok {
+		c = &call{done: make(chan struct{})}
+		req.calls[key] = c
+		req.mu.Unlock()
+
+		c.value, c.err = scrape(req.cookie)
+		close(c.done)
+	} else {
+		req.mu.Unlock()
+		<-c.done
+	}
+
+	if c.err != nil {
+		return zero, queryError{errors.From(c.err)}
+	}
+	return c.value.(T), nil
+}
+
+// queryError reports a failure with the code and status the REST endpoints
+// use in its extensions, without the message of the underlying error
+type queryError struct {
+	*errors.CustomError
+}
+
+func (e queryError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the CustomError, whose Error carries the cause for logging
+func (e queryError) Unwrap() error {
+	return e.CustomError
+}
+
+func (e queryError) Extensions() map[string]any {
+	extensions := map[string]any{
+		"code":   e.Code,
+		"status": e.StatusCode,
+	}
+	if e.Details != nil {
+		extensions["details"] = e.Details
+	}
+	return extensions
+}
+
+func (r *Resolver) Profile(ctx context.Context) (*dtos.Profile, error) {
+	return once(ctx, "profile", func(cookie string) (*dtos.Profile, error) {
+		return r.Scraper.Profile(ctx, cookie)
+	})
+}
+
+func (r *Resolver) Sessions(ctx context.Context) (*[]imaluum.Session, error) {
+	sessions, err := r.sessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sessions, nil
+}
+
+func (r *Resolver) sessions(ctx context.Context) ([]imaluum.Session, error) {
+	return once(ctx, "sessions", func(cookie string) ([]imaluum.Session, error) {
+		return r.Scraper.Sessions(ctx, cookie)
+	})
+}
+
+type sessionArgs struct {
+	Session *string
+}
+
+func (r *Resolver) Schedule(ctx context.Context, args sessionArgs) (*[]schedule, error) {
+	sessions, err := r.sessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if sessions, err = pick(sessions, args.Session, errors.ErrScheduleIsEmpty); err != nil {
+		return nil, queryError{errors.From(err)}
+	}
+
+	schedules, err := once(ctx, "schedule "+sessionsKey(sessions), func(cookie string) ([]dtos.ScheduleResponse, error) {
+		return r.Scraper.ScheduleFor(ctx, cookie, sessions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	converted := imaluum.ScheduleV2(schedules)
+	resolved := make([]schedule, len(converted))
+	for i := range converted {
+		resolved[i] = schedule{converted[i]}
+	}
+	return &resolved, nil
+}
+
+func (r *Resolver) Results(ctx context.Context, args sessionArgs) (*[]result, error) {
+	sessions, err := once(ctx, "result sessions", func(cookie string) ([]imaluum.Session, error) {
+		return r.Scraper.ResultSessions(ctx, cookie)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sessions, err = pick(sessions, args.Session, errors.ErrResultIsEmpty); err != nil {
+		return nil, queryError{errors.From(err)}
+	}
+
+	results, err := once(ctx, "results "+sessionsKey(sessions), func(cookie string) ([]dtos.ResultResponse, error) {
+		return r.Scraper.ResultsFor(ctx, cookie, sessions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	converted := r.GradePoints.CheckAll(imaluum.ResultsV2(results))
+	resolved := make([]result, len(converted))
+	for i := range converted {
+		resolved[i] = result{converted[i]}
+	}
+	return &resolved, nil
+}
+
+func (r *Resolver) Starpoint(ctx context.Context) (*starpoint, error) {
+	scraped, err := once(ctx, "starpoint", func(cookie string) (*dtos.Starpoint, error) {
+		return r.Scraper.Starpoint(ctx, cookie)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &starpoint{*imaluum.StarpointV2(scraped)}, nil
+}
+
+// pick narrows sessions to the one named or queried by session, all of them
+// when it is nil. empty is the error for a student without any session.
+func pick(sessions []imaluum.Session, session *string, empty *errors.CustomError) ([]imaluum.Session, error) {
+	if session == nil {
+		if len(sessions) == 0 {
+			return nil, empty
+		}
+		return sessions, nil
+	}
+
+	for _, candidate := range sessions {
+		if candidate.Name == *session || candidate.Query == *session {
+			return []imaluum.Session{candidate}, nil
+		}
+	}
+	return nil, errors.WithDetails(errors.ErrUnknownSession, map[string]any{"session": *session})
+}
+
+func sessionsKey(sessions []imaluum.Session) string {
+	var key string
+	for _, session := range sessions {
+		key += session.Query + " "
+	}
+	return key
+}
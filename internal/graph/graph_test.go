@@ -0,0 +1,219 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/gpa"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSessions = []imaluum.Session{
+	{Name: "Sem 2, 2024/2025", Query: "?ses=2024/2025&sem=2"},
+	{Name: "Sem 1, 2024/2025", Query: "?ses=2024/2025&sem=1"},
+}
+
+// stubScraper serves canned data and counts the scrapes asked of it
+type stubScraper struct {
+	calls     map[string]int
+	scheduled [][]imaluum.Session
+	failing   error
+	mu        sync.Mutex
+}
+
+func (s *stubScraper) count(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls == nil {
+		s.calls = make(map[string]int)
+	}
+	s.calls[name]++
+}
+
+func (s *stubScraper) Profile(context.Context, string) (*dtos.Profile, error) {
+	s.count("profile")
+	if s.failing != nil {
+		return nil, s.failing
+	}
+	return &dtos.Profile{Name: "AHMAD", MatricNo: "2110000", ImageURL: "https://example.com/2110000.png"}, nil
+}
+
+func (s *stubScraper) Sessions(context.Context, string) ([]imaluum.Session, error) {
+	s.count("sessions")
+	return testSessions, nil
+}
+
+func (s *stubScraper) ScheduleFor(_ context.Context, _ string, sessions []imaluum.Session) ([]dtos.ScheduleResponse, error) {
+	s.count("schedule")
+	s.mu.Lock()
+	s.scheduled = append(s.scheduled, sessions)
+	s.mu.Unlock()
+
+	schedules := make([]dtos.ScheduleResponse, len(sessions))
+	for i, session := range sessions {
+		schedules[i] = dtos.ScheduleResponse{
+			ID:           "gomaluum:schedule:" + session.Query,
+			SessionName:  session.Name,
+			SessionQuery: session.Query,
+			Schedule: []dtos.ScheduleSubject{{
+				ID:         "gomaluum:subject:1",
+				CourseCode: "CSCI 3300",
+				Timestamps: []dtos.WeekTime{{Start: "0830", End: "0950", Day: 1}},
+				Chr:        3,
+				Section:    2,
+			}},
+		}
+	}
+	return schedules, nil
+}
+
+func (s *stubScraper) ResultSessions(context.Context, string) ([]imaluum.Session, error) {
+	s.count("result sessions")
+	return testSessions, nil
+}
+
+func (s *stubScraper) ResultsFor(_ context.Context, _ string, sessions []imaluum.Session) ([]dtos.ResultResponse, error) {
+	s.count("results")
+
+	results := make([]dtos.ResultResponse, len(sessions))
+	for i, session := range sessions {
+		results[i] = dtos.ResultResponse{
+			SessionName:  session.Name,
+			SessionQuery: session.Query,
+			GpaValue:     "4.00",
+			Result: []dtos.Result{
+				{CourseCode: "CSCI 3300", CourseGrade: "A", CourseCredit: "3"},
+				{CourseCode: "LM 1021", CourseGrade: "P", CourseCredit: "1"},
+			},
+		}
+	}
+	return results, nil
+}
+
+func (s *stubScraper) Starpoint(context.Context, string) (*dtos.Starpoint, error) {
+	s.count("starpoint")
+	return &dtos.Starpoint{
+		CummulativeAverage: 2.5,
+		Programs:           []dtos.StarpointProgram{{ID: "gomaluum:program:1", Semester: 1, Points: 2.5}},
+	}, nil
+}
+
+func exec(t *testing.T, scraper *stubScraper, query string) (*graphql.Response, map[string]any) {
+	t.Helper()
+
+	schema, err := NewSchema(&Resolver{Scraper: scraper, GradePoints: gpa.DefaultTable})
+	require.NoError(t, err)
+
+	response := schema.Exec(WithCookie(context.Background(), "cookie"), query, "", nil)
+
+	var data map[string]any
+	if response.Data != nil {
+		require.NoError(t, json.Unmarshal(response.Data, &data))
+	}
+	return response, data
+}
+
+func TestOnlySelectedFieldsAreScraped(t *testing.T) {
+	scraper := &stubScraper{}
+	response, data := exec(t, scraper, `{
+		sessions { name }
+		schedule(session: "Sem 1, 2024/2025") { subjects { courseCode section creditHours slots { day start end } } }
+	}`)
+	require.Empty(t, response.Errors)
+
+	// The dropdown is read once for both fields and only one session is scraped
+	assert.Equal(t, map[string]int{"sessions": 1, "schedule": 1}, scraper.calls)
+	assert.Equal(t, [][]imaluum.Session{{testSessions[1]}}, scraper.scheduled)
+
+	schedule := data["schedule"].([]any)
+	require.Len(t, schedule, 1)
+	subject := schedule[0].(map[string]any)["subjects"].([]any)[0].(map[string]any)
+	assert.Equal(t, "CSCI 3300", subject["courseCode"])
+	assert.EqualValues(t, 2, subject["section"])
+	assert.EqualValues(t, 3, subject["creditHours"])
+	assert.Equal(t, []any{map[string]any{"day": 1.0, "start": "08:30", "end": "09:50"}}, subject["slots"])
+}
+
+func TestQueryEverything(t *testing.T) {
+	scraper := &stubScraper{}
+	response, data := exec(t, scraper, `{
+		profile { name matricNo imageUrl }
+		schedule { id sessionName }
+		results(session: "?ses=2024/2025&sem=2") {
+			gpa
+			gpaCheck { status computedCredits excluded { courseCode grade reason } }
+			courses { courseCode grade credit }
+		}
+		starpoint { cumulativeAverage programs { id semester points } }
+	}`)
+	require.Empty(t, response.Errors)
+	assert.Equal(t, map[string]int{"profile": 1, "sessions": 1, "schedule": 1, "result sessions": 1, "results": 1, "starpoint": 1}, scraper.calls)
+
+	assert.Equal(t, map[string]any{"name": "AHMAD", "matricNo": "2110000", "imageUrl": "https://example.com/2110000.png"}, data["profile"])
+	assert.Len(t, data["schedule"], 2)
+
+	result := data["results"].([]any)[0].(map[string]any)
+	assert.EqualValues(t, 4, result["gpa"])
+	assert.Equal(t, map[string]any{
+		"status":          "MATCH",
+		"computedCredits": 3.0,
+		"excluded":        []any{map[string]any{"courseCode": "LM 1021", "grade": "P", "reason": "PASS_FAIL"}},
+	}, result["gpaCheck"])
+	assert.Equal(t, map[string]any{"courseCode": "CSCI 3300", "grade": "A", "credit": 3.0}, result["courses"].([]any)[0])
+
+	starpoint := data["starpoint"].(map[string]any)
+	assert.EqualValues(t, 2.5, starpoint["cumulativeAverage"])
+	assert.Equal(t, []any{map[string]any{"id": "gomaluum:program:1", "semester": 1.0, "points": 2.5}}, starpoint["programs"])
+}
+
+func TestQueryErrors(t *testing.T) {
+	scraper := &stubScraper{failing: errors.Wrap(errors.ErrUpstreamUnavailable, stderrors.New("circuit open"))}
+	response, data := exec(t, scraper, `{
+		profile { name }
+		starpoint { totalPoints }
+		schedule(session: "Sem 9, 1999/2000") { id }
+	}`)
+
+	// Failed fields are null and the rest is served
+	assert.Nil(t, data["profile"])
+	assert.Nil(t, data["schedule"])
+	assert.NotNil(t, data["starpoint"])
+
+	require.Len(t, response.Errors, 2)
+	var (
+		byPath   = make(map[string]map[string]any)
+		messages = make(map[string]string)
+	)
+	for _, err := range response.Errors {
+		byPath[err.Path[0].(string)] = err.Extensions
+		messages[err.Path[0].(string)] = err.Message
+	}
+
+	// The cause is only logged
+	assert.Equal(t, errors.ErrUpstreamUnavailable.Message, messages["profile"])
+	assert.ErrorContains(t, errors.From(firstResolverError(response, "profile")), "circuit open")
+	assert.Equal(t, errors.CodeUpstreamUnavailable, byPath["profile"]["code"])
+	assert.Equal(t, errors.ErrUpstreamUnavailable.StatusCode, byPath["profile"]["status"])
+
+	assert.Equal(t, errors.CodeNotFound, byPath["schedule"]["code"])
+	assert.Equal(t, map[string]any{"session": "Sem 9, 1999/2000"}, byPath["schedule"]["details"])
+	// The schedule of an unknown session is never scraped
+	assert.Zero(t, scraper.calls["schedule"])
+}
+
+func firstResolverError(response *graphql.Response, field string) error {
+	for _, err := range response.Errors {
+		if err.Path[0] == field {
+			return err.ResolverError
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package graph
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// The types below wrap the DTOs where a field name or Go type differs from
+// the schema, every other field resolves from the DTO itself.
+
+type schedule struct {
+	dtos.ScheduleResponseV2
+}
+
+func (s schedule) ID() graphql.ID {
+	return graphql.ID(s.ScheduleResponseV2.ID)
+}
+
+func (s schedule) Subjects() []subject {
+	subjects := make([]subject, len(s.Schedule))
+	for i := range s.Schedule {
+		subjects[i] = subject{s.Schedule[i]}
+	}
+	return subjects
+}
+
+type subject struct {
+	dtos.ScheduleSubjectV2
+}
+
+func (s subject) ID() graphql.ID {
+	return graphql.ID(s.ScheduleSubjectV2.ID)
+}
+
+func (s subject) Section() int32 {
+	return int32(s.ScheduleSubjectV2.Section)
+}
+
+func (s subject) Slots() []slot {
+	slots := make([]slot, len(s.ScheduleSubjectV2.Slots))
+	for i := range s.ScheduleSubjectV2.Slots {
+		slots[i] = slot{s.ScheduleSubjectV2.Slots[i]}
+	}
+	return slots
+}
+
+type slot struct {
+	dtos.ScheduleSlot
+}
+
+func (s slot) Day() int32 {
+	return int32(s.ScheduleSlot.Day)
+}
+
+type result struct {
+	dtos.ResultResponseV2
+}
+
+func (r result) ID() graphql.ID {
+	return graphql.ID(r.ResultResponseV2.ID)
+}
+
+func (r result) GpaCheck() *gpaCheck {
+	if r.ResultResponseV2.GpaCheck == nil {
+		return nil
+	}
+	return &gpaCheck{*r.ResultResponseV2.GpaCheck}
+}
+
+func (r result) Courses() []course {
+	courses := make([]course, len(r.Result))
+	for i := range r.Result {
+		courses[i] = course{r.Result[i]}
+	}
+	return courses
+}
+
+type course struct {
+	dtos.ResultV2
+}
+
+func (c course) ID() graphql.ID {
+	return graphql.ID(c.ResultV2.ID)
+}
+
+func (c course) Grade() string {
+	return string(c.ResultV2.Grade)
+}
+
+type gpaCheck struct {
+	dtos.GpaCheck
+}
+
+func (g gpaCheck) Excluded() []excludedCourse {
+	excluded := make([]excludedCourse, len(g.GpaCheck.Excluded))
+	for i := range g.GpaCheck.Excluded {
+		excluded[i] = excludedCourse{g.GpaCheck.Excluded[i]}
+	}
+	return excluded
+}
+
+type excludedCourse struct {
+	dtos.ExcludedCourse
+}
+
+func (e excludedCourse) Grade() string {
+	return string(e.ExcludedCourse.Grade)
+}
+
+type starpoint struct {
+	dtos.StarpointV2
+}
+
+func (s starpoint) ID() graphql.ID {
+	return graphql.ID(s.StarpointV2.ID)
+}
+
+func (s starpoint) Programs() []program {
+	programs := make([]program, len(s.StarpointV2.Programs))
+	for i := range s.StarpointV2.Programs {
+		programs[i] = program{s.StarpointV2.Programs[i]}
+	}
+	return programs
+}
+
+type program struct {
+	dtos.StarpointProgram
+}
+
+func (p program) ID() graphql.ID {
+	return graphql.ID(p.StarpointProgram.ID)
+}
+
+func (p program) Semester() int32 {
+	return int32(p.StarpointProgram.Semester)
+}
+
+func (p program) Points() float64 {
+	return float64(p.StarpointProgram.Points)
+}
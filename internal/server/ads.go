@@ -4,11 +4,10 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/bytedance/sonic"
 	"github.com/gocolly/colly/v2"
-	"github.com/lucsky/cuid"
 	"github.com/nrmnqdds/gomaluum/internal/dtos"
 	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
 )
 
 // @Title AdsHandler
@@ -25,15 +24,16 @@ func (s *Server) AdsHandler(w http.ResponseWriter, r *http.Request) {
 	c := colly.NewCollector()
 
 	c.OnHTML("div[style*='width:100%; clear:both;height:100px']", func(e *colly.HTMLElement) {
+		link := strings.TrimSpace(e.ChildAttr("a", "href"))
 		ads = append(ads, dtos.Ads{
 			Title:    strings.TrimSpace(e.ChildText("a")),
 			ImageURL: strings.TrimSpace(e.ChildAttr("img", "src")),
-			Link:     strings.TrimSpace(e.ChildAttr("a", "href")),
-			ID:       cuid.New(),
+			Link:     link,
+			ID:       imaluum.StableID("ad", link),
 		})
 	})
 
-	if err := c.Visit("https://souq.iium.edu.my/embeded"); err != nil {
+	if err := c.Visit(s.grpc.urls.SouqAdsURL); err != nil {
 		logger.Sugar().Errorf("Failed to visit ads page: %v", err)
 		errors.Render(w, r, errors.ErrFailedToGoToURL)
 		return
@@ -44,8 +44,5 @@ func (s *Server) AdsHandler(w http.ResponseWriter, r *http.Request) {
 		Data:    &ads,
 	}
 
-	if err := sonic.ConfigFastest.NewEncoder(w).Encode(response); err != nil {
-		logger.Sugar().Errorf("Failed to encode response: %v", err)
-		errors.Render(w, r, errors.ErrFailedToEncodeResponse)
-	}
+	s.respond(w, r, response)
 }
@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"sort"
 
-	"github.com/bytedance/sonic"
 	"github.com/nrmnqdds/gomaluum/internal/dtos"
 	"github.com/nrmnqdds/gomaluum/internal/errors"
 )
@@ -79,8 +78,5 @@ func (s *Server) GetAnalyticsSummaryHandler(w http.ResponseWriter, r *http.Reque
 		Data:    result,
 	}
 
-	if err := sonic.ConfigFastest.NewEncoder(w).Encode(response); err != nil {
-		s.log.GetLogger().Sugar().Errorf("Failed to encode response: %v", err)
-		errors.Render(w, r, errors.ErrFailedToEncodeResponse)
-	}
+	s.respond(w, r, response)
 }
@@ -6,9 +6,7 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 
-	"github.com/bytedance/sonic"
 	"github.com/mailru/easyjson"
-	"github.com/nrmnqdds/gomaluum/internal/constants"
 	"github.com/nrmnqdds/gomaluum/internal/dtos"
 	"github.com/nrmnqdds/gomaluum/internal/errors"
 	pb "github.com/nrmnqdds/gomaluum/internal/proto"
@@ -70,10 +68,7 @@ func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		Data:    result,
 	}
 
-	if err := sonic.ConfigFastest.NewEncoder(w).Encode(response); err != nil {
-		logger.Sugar().Errorf("Failed to encode response: %v", err)
-		errors.Render(w, r, errors.ErrFailedToEncodeResponse)
-	}
+	s.respond(w, r, response)
 }
 
 // @Title LogoutHandler
@@ -87,13 +82,14 @@ func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
 func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	logger := s.log.GetLogger()
-
 	jar, _ := cookiejar.New(nil)
 
 	cookie := r.Context().Value(ctxToken).(string)
 
-	urlObj, err := url.Parse(constants.ImaluumLogoutPage)
+	// Responses cached for the user are not served after they log out
+	s.cache.Forget(r.Context().Value(ctxUser).(*TokenPayload).username)
+
+	urlObj, err := url.Parse(s.grpc.urls.ImaluumLogoutPage())
 	if err != nil {
 		errors.Render(w, r, errors.ErrURLParseFailed)
 		return
@@ -110,7 +106,7 @@ func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 		Jar: jar,
 	}
 
-	req, _ := http.NewRequest("GET", constants.ImaluumCasLogoutPage, nil)
+	req, _ := http.NewRequest("GET", s.grpc.urls.ImaluumCasLogoutPage(), nil)
 	setHeaders(req)
 
 	resp, err := client.Do(req)
@@ -125,10 +121,7 @@ func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 		Data:    nil,
 	}
 
-	if err := sonic.ConfigFastest.NewEncoder(w).Encode(response); err != nil {
-		logger.Sugar().Errorf("Failed to encode response: %v", err)
-		errors.Render(w, r, errors.ErrFailedToEncodeResponse)
-	}
+	s.respond(w, r, response)
 }
 
 // Function to set headers for a request.
@@ -9,7 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/nrmnqdds/gomaluum/internal/constants"
 	"github.com/nrmnqdds/gomaluum/internal/errors"
 	auth_proto "github.com/nrmnqdds/gomaluum/internal/proto"
 )
@@ -27,7 +26,7 @@ func (s *GRPCServer) Login(_ context.Context, req *auth_proto.LoginRequest) (*au
 		Timeout:   time.Second * 10, // Indicates i-Ma'luum server is slow
 	}
 
-	urlObj, err := url.Parse(constants.ImaluumPage)
+	urlObj, err := url.Parse(s.urls.ImaluumPage())
 	if err != nil {
 		log.Printf("Failed to parse Imaluum Page: %v", err)
 		return nil, errors.ErrURLParseFailed
@@ -42,7 +41,7 @@ func (s *GRPCServer) Login(_ context.Context, req *auth_proto.LoginRequest) (*au
 	}
 
 	// First request
-	reqFirst, err := http.NewRequest("GET", constants.ImaluumCasPage, nil)
+	reqFirst, err := http.NewRequest("GET", s.urls.ImaluumCasPage(), nil)
 	if err != nil {
 		log.Printf("Failed to create first request: %v", err)
 		if err := reqFirst.Body.Close(); err != nil {
@@ -57,21 +56,14 @@ func (s *GRPCServer) Login(_ context.Context, req *auth_proto.LoginRequest) (*au
 	respFirst, err := client.Do(reqFirst)
 	if err != nil {
 		log.Printf("Failed to do first request: %v", err)
-		if err := reqFirst.Body.Close(); err != nil {
-			log.Printf("Failed to close request body: %v", err)
-			return nil, errors.ErrFailedToCloseRequestBody
-		}
-		if err := respFirst.Body.Close(); err != nil {
-			log.Printf("Failed to close response body: %v", err)
-			return nil, errors.ErrFailedToCloseResponseBody
-		}
-		return nil, errors.ErrURLParseFailed
+		// The client already closed the request body and there is no response to close
+		return nil, errors.Wrap(errors.ErrUpstreamUnavailable, err)
 	}
 
 	client.Jar.SetCookies(urlObj, respFirst.Cookies())
 
 	// Second request
-	reqSecond, err := http.NewRequest("POST", constants.ImaluumLoginPage, strings.NewReader(formVal.Encode()))
+	reqSecond, err := http.NewRequest("POST", s.urls.ImaluumLoginPage(), strings.NewReader(formVal.Encode()))
 	if err != nil {
 		log.Printf("Failed to create second request: %v", err)
 		if err := reqSecond.Body.Close(); err != nil {
@@ -86,15 +78,8 @@ func (s *GRPCServer) Login(_ context.Context, req *auth_proto.LoginRequest) (*au
 	respSecond, err := client.Do(reqSecond)
 	if err != nil {
 		log.Printf("Failed to do second request: %v", err)
-		if err := reqSecond.Body.Close(); err != nil {
-			log.Printf("Failed to close request body: %v", err)
-			return nil, errors.ErrFailedToCloseRequestBody
-		}
-		if err := respSecond.Body.Close(); err != nil {
-			log.Printf("Failed to close response body: %v", err)
-			return nil, errors.ErrFailedToCloseResponseBody
-		}
-		return nil, errors.ErrURLParseFailed
+		// The client already closed the request body and there is no response to close
+		return nil, errors.Wrap(errors.ErrUpstreamUnavailable, err)
 	}
 	if err := respSecond.Body.Close(); err != nil {
 		log.Printf("Failed to close response body: %v", err)
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/nrmnqdds/gomaluum/pkg/cache"
+)
+
+// Scraped endpoints with their own cache policy. Timetables and profiles
+// rarely change within a semester, results are refreshed sooner around
+// release week.
+var defaultCachePolicies = map[string]cache.Policy{
+	"profile":   {TTL: 6 * time.Hour, Stale: 24 * time.Hour},
+	"schedule":  {TTL: time.Hour, Stale: 24 * time.Hour},
+	"result":    {TTL: 15 * time.Minute, Stale: 6 * time.Hour},
+	"starpoint": {TTL: 30 * time.Minute, Stale: 6 * time.Hour},
+}
+
+// loadCachePolicies applies the CACHE_<ENDPOINT>_* overrides to the defaults
+func loadCachePolicies() map[string]cache.Policy {
+	policies := make(map[string]cache.Policy, len(defaultCachePolicies))
+	for endpoint, policy := range defaultCachePolicies {
+		policies[endpoint] = cache.PolicyFromEnv(endpoint, policy)
+	}
+	return policies
+}
+
+// cached caches the responses of an authenticated route per user. Responses
+// filling the cache are replayed to other requests, so they are rendered
+// without a request_id, X-Request-Id names the request being answered.
+func (s *Server) cached(endpoint string) func(http.Handler) http.Handler {
+	caching := s.cache.Middleware(s.cachePolicies[endpoint], cacheScope)
+	return func(next http.Handler) http.Handler {
+		return caching(withoutSharedRequestID(next))
+	}
+}
+
+func withoutSharedRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cache.Filling(r.Context()) {
+			r = r.WithContext(context.WithValue(r.Context(), middleware.RequestIDKey, ""))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cacheScope keys responses by matric number. Reads from stored snapshots
+// are cheap already and skip the cache.
+func cacheScope(r *http.Request) string {
+	if r.URL.Query().Get("source") == sourceCache {
+		return ""
+	}
+	user, ok := r.Context().Value(ctxUser).(*TokenPayload)
+	if !ok {
+		return ""
+	}
+	return user.username
+}
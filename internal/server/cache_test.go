@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, body := do(t, http.MethodGet, api.URL+path, token, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		return resp
+	}
+
+	resp := get("/api/schedule")
+	assert.Equal(t, cache.Miss, resp.Header.Get("X-Cache"))
+	assert.Equal(t, "private, max-age=3600, stale-while-revalidate=86400", resp.Header.Get("Cache-Control"))
+
+	resp = get("/api/schedule")
+	assert.Equal(t, cache.Hit, resp.Header.Get("X-Cache"))
+	assert.NotEmpty(t, resp.Header.Get("Age"))
+	assert.NotEmpty(t, resp.Header.Get("X-Request-Id"))
+
+	// A replayed body names no request, the header names the current one
+	resp, body := do(t, http.MethodGet, api.URL+"/api/schedule", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.NotContains(t, string(body), "request_id")
+
+	assert.Equal(t, cache.Refresh, get("/api/schedule?fresh=true").Header.Get("X-Cache"))
+	assert.Equal(t, cache.Miss, get("/api/result").Header.Get("X-Cache"))
+
+	// Logging out forgets what was cached for the user
+	resp, body = do(t, http.MethodGet, api.URL+"/api/auth/logout", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, cache.Miss, get("/api/schedule").Header.Get("X-Cache"))
+}
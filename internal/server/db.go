@@ -0,0 +1,96 @@
+package server
+
+import (
+	"database/sql"
+	"slices"
+
+	"github.com/nrmnqdds/gomaluum/pkg/scheduler"
+)
+
+// schema is applied on every start, so every statement must be idempotent.
+// Timestamps of new tables are unix seconds, which scan the same from remote
+// libsql and local sqlite.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS analytics (
+		matric_no TEXT NOT NULL PRIMARY KEY,
+		batch AS (substr(matric_no, 1, 2) + 2000) STORED,
+		level AS (
+			CASE length(matric_no)
+				WHEN 7 THEN 'DEGREE'
+				WHEN 6 THEN 'CFS'
+			END
+		) STORED,
+		timestamp DATETIME DEFAULT current_timestamp
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_batch ON analytics(batch)`,
+	`CREATE INDEX IF NOT EXISTS idx_level ON analytics(level)`,
+	`CREATE INDEX IF NOT EXISTS idx_batch_level ON analytics(batch, level)`,
+
+	// Users polled in the background, the password is kept encrypted for
+	// logging in as them
+	`CREATE TABLE IF NOT EXISTS watchers (
+		matric_no TEXT NOT NULL PRIMARY KEY,
+		password TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		last_polled_at INTEGER
+	)`,
+	// The latest scrape of each kind, to diff the next poll against
+	`CREATE TABLE IF NOT EXISTS snapshots (
+		matric_no TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (matric_no, kind)
+	)`,
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		matric_no TEXT NOT NULL PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	)`,
+	// Deliveries that failed every attempt
+	`CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		matric_no TEXT NOT NULL,
+		url TEXT NOT NULL,
+		event TEXT NOT NULL,
+		error TEXT NOT NULL,
+		attempts INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_matric_no ON webhook_dead_letters(matric_no)`,
+	`CREATE TABLE IF NOT EXISTS push_subscriptions (
+		endpoint TEXT NOT NULL PRIMARY KEY,
+		matric_no TEXT NOT NULL,
+		p256dh TEXT NOT NULL,
+		auth TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_push_subscriptions_matric_no ON push_subscriptions(matric_no)`,
+
+	// Users who opted in to keeping a history of their scrapes
+	`CREATE TABLE IF NOT EXISTS snapshot_users (
+		matric_no TEXT NOT NULL PRIMARY KEY,
+		created_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS snapshot_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		matric_no TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		fetched_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_snapshot_history_matric_no_kind ON snapshot_history(matric_no, kind, id)`,
+}
+
+func migrate(db *sql.DB) error {
+	for _, stmt := range slices.Concat(schema, scheduler.Schema) {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+)
+
+// parseWarnings returns the rows skipped while serving the request and counts
+// them in the X-Parse-Warnings header, so clients can tell the data is partial
+func parseWarnings(w http.ResponseWriter, diagnostics *imaluum.Diagnostics) []dtos.ParseWarning {
+	warnings := diagnostics.Warnings()
+	if len(warnings) > 0 {
+		w.Header().Set("X-Parse-Warnings", strconv.Itoa(len(warnings)))
+	}
+	return warnings
+}
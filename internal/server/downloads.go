@@ -2,10 +2,8 @@ package server
 
 import (
 	"io"
-	"log"
 	"net/http"
 
-	"github.com/nrmnqdds/gomaluum/internal/constants"
 	"github.com/nrmnqdds/gomaluum/internal/errors"
 )
 
@@ -22,31 +20,18 @@ func (s *Server) ExamSlipHandler(w http.ResponseWriter, r *http.Request) {
 	var (
 		logger = s.log.GetLogger()
 		cookie = r.Context().Value(ctxToken).(string)
-		client = s.httpClient
 	)
 
-	req, err := http.NewRequest("GET", constants.ImaluumExamSlipPage, nil)
-	if err != nil {
-		log.Printf("Failed to create first request: %v", err)
-		if err := req.Body.Close(); err != nil {
-			log.Printf("Failed to close request body: %v", err)
-			errors.Render(w, r, errors.ErrFailedToCloseRequestBody)
-		}
-		errors.Render(w, r, errors.ErrURLParseFailed)
-	}
-
-	setHeadersWithCookie(req, cookie)
-
-	resp, err := client.Do(req)
+	body, err := s.imaluum.ExamSlip(r.Context(), cookie)
 	if err != nil {
 		logger.Sugar().Errorf("Failed to do request: %v", err)
-		errors.Render(w, r, errors.ErrURLParseFailed)
+		errors.Render(w, r, err)
 		return
 	}
-	defer resp.Body.Close() // Use defer to close the body after we're done with it
+	defer body.Close() // Use defer to close the body after we're done with it
 
 	// Stream to response
-	if _, err := io.Copy(w, resp.Body); err != nil {
+	if _, err := io.Copy(w, body); err != nil {
 		logger.Sugar().Errorf("Failed to copy response body: %v", err)
 		errors.Render(w, r, errors.ErrDownloadFailed)
 		return
@@ -66,41 +51,20 @@ func (s *Server) StudyPlanHandler(w http.ResponseWriter, r *http.Request) {
 	var (
 		logger = s.log.GetLogger()
 		cookie = r.Context().Value(ctxToken).(string)
-		client = s.httpClient
 	)
 
-	req, err := http.NewRequest("GET", constants.ImaluumStudyPlanPage, nil)
-	if err != nil {
-		log.Printf("Failed to create first request: %v", err)
-		if err := req.Body.Close(); err != nil {
-			log.Printf("Failed to close request body: %v", err)
-			errors.Render(w, r, errors.ErrFailedToCloseRequestBody)
-		}
-		errors.Render(w, r, errors.ErrURLParseFailed)
-	}
-
-	setHeadersWithCookie(req, cookie)
-
-	resp, err := client.Do(req)
+	body, err := s.imaluum.StudyPlan(r.Context(), cookie)
 	if err != nil {
 		logger.Sugar().Errorf("Failed to do request: %v", err)
-		errors.Render(w, r, errors.ErrURLParseFailed)
+		errors.Render(w, r, err)
 		return
 	}
-	defer resp.Body.Close() // Use defer to close the body after we're done with it
+	defer body.Close() // Use defer to close the body after we're done with it
 
 	// Stream to response
-	if _, err := io.Copy(w, resp.Body); err != nil {
+	if _, err := io.Copy(w, body); err != nil {
 		logger.Sugar().Errorf("Failed to copy response body: %v", err)
 		errors.Render(w, r, errors.ErrDownloadFailed)
 		return
 	}
 }
-
-// Function to set headers for a request.
-func setHeadersWithCookie(req *http.Request, cookie string) {
-	req.Header.Set("Connection", "Keep-Alive")
-	req.Header.Set("Accept-Language", "en-US")
-	req.Header.Set("User-Agent", "Mozilla/5.0")
-	req.Header.Set("Cookie", "MOD_AUTH_CAS="+cookie)
-}
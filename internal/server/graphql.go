@@ -0,0 +1,63 @@
+package server
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/bytedance/sonic"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/internal/graph"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+)
+
+// @Title GraphQLHandler
+// @Description Query profile, sessions, schedule, results and starpoint over GraphQL, scraping only the fields selected.
+// @Description The schema is in internal/graph/schema.graphql. Failed fields come back in errors with their code and status in extensions.
+// @Tags scraper
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param body body dtos.GraphQLRequest true "GraphQL query"
+// @Success 200 {object} map[string]any
+// @Router /api/graphql [post]
+func (s *Server) GraphQLHandler() http.HandlerFunc {
+	schema, err := graph.NewSchema(&graph.Resolver{
+		Scraper:     s.imaluum,
+		GradePoints: s.gradePoints,
+	})
+	if err != nil {
+		log.Fatalf("Failed to parse GraphQL schema: %v", err)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			logger      = s.log.GetLogger()
+			cookie      = r.Context().Value(ctxToken).(string)
+			diagnostics = imaluum.NewDiagnostics()
+			req         dtos.GraphQLRequest
+		)
+
+		if err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" {
+			logger.Sugar().Errorf("Failed to decode GraphQL request: %v", err)
+			errors.Render(w, r, errors.ErrInvalidRequest)
+			return
+		}
+
+		ctx := graph.WithCookie(imaluum.WithDiagnostics(r.Context(), diagnostics), cookie)
+		response := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+		for _, queryErr := range response.Errors {
+			if queryErr.ResolverError != nil {
+				logger.Sugar().Errorf("Failed to resolve %v: %v", queryErr.Path, errors.From(queryErr.ResolverError))
+			}
+		}
+		if warnings := parseWarnings(w, diagnostics); len(warnings) > 0 {
+			response.Extensions = map[string]any{"warnings": warnings}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := sonic.ConfigFastest.NewEncoder(w).Encode(response); err != nil {
+			logger.Sugar().Errorf("Failed to encode GraphQL response: %v", err)
+		}
+	}
+}
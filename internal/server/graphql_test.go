@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQL(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	query, err := json.Marshal(map[string]any{
+		"query": `query Dashboard($session: String) {
+			profile { matricNo }
+			sessions { name query }
+			schedule(session: $session) { sessionName subjects { courseCode slots { start } } }
+			results { gpa courses { grade } }
+		}`,
+		"variables": map[string]any{"session": "Sem 1, 2024/2025"},
+	})
+	require.NoError(t, err)
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/graphql", token, string(query))
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response struct {
+		Errors []json.RawMessage `json:"errors"`
+		Data   struct {
+			Profile  struct{ MatricNo string } `json:"profile"`
+			Sessions []struct{ Name string }   `json:"sessions"`
+			Schedule []struct {
+				SessionName string `json:"sessionName"`
+				Subjects    []struct {
+					CourseCode string `json:"courseCode"`
+				} `json:"subjects"`
+			} `json:"schedule"`
+			Results []struct {
+				Gpa *float64 `json:"gpa"`
+			} `json:"results"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.Empty(t, response.Errors)
+
+	assert.Equal(t, fakeimaluum.Username, response.Data.Profile.MatricNo)
+	assert.Len(t, response.Data.Sessions, 2)
+	require.Len(t, response.Data.Schedule, 1)
+	assert.Equal(t, "Sem 1, 2024/2025", response.Data.Schedule[0].SessionName)
+	assert.Len(t, response.Data.Schedule[0].Subjects, 4)
+	require.Len(t, response.Data.Results, 2)
+	assert.NotNil(t, response.Data.Results[0].Gpa)
+}
+
+func TestGraphQLBadRequests(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/graphql", token, `{"query": ""}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var failure testResponse
+	require.NoError(t, json.Unmarshal(body, &failure))
+	assert.Equal(t, errors.CodeInvalidRequest, failure.Code)
+
+	resp, _ = do(t, http.MethodPost, api.URL+"/api/graphql", "", `{"query": "{ profile { name } }"}`)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Invalid queries are reported the GraphQL way
+	resp, body = do(t, http.MethodPost, api.URL+"/api/graphql", token, `{"query": "{ profile { password } }"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `Cannot query field \"password\"`)
+}
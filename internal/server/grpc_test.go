@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	pb "github.com/nrmnqdds/gomaluum/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCLogin(t *testing.T) {
+	s, fake := newTestBackend(t)
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(errors.UnaryServerInterceptor))
+	pb.RegisterAuthServer(grpcServer, s.grpc)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := pb.NewAuthClient(conn)
+
+	resp, err := client.Login(context.Background(), &pb.LoginRequest{Username: fakeimaluum.Username, Password: fakeimaluum.Password})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+
+	_, err = client.Login(context.Background(), &pb.LoginRequest{Username: fakeimaluum.Username, Password: "wrong"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, errors.CodeInvalidCredentials, errors.FromGRPC(err).Code)
+
+	// i-Ma'luum going down is a retryable failure
+	fake.Close()
+	_, err = client.Login(context.Background(), &pb.LoginRequest{Username: fakeimaluum.Username, Password: fakeimaluum.Password})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, errors.CodeUpstreamUnavailable, errors.FromGRPC(err).Code)
+}
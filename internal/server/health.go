@@ -2,12 +2,16 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/alexliesenfeld/health"
 	"github.com/cloudflare/cloudflare-go"
+	"github.com/nrmnqdds/gomaluum/pkg/upstream"
 )
 
 // @Title HealthHandler
@@ -55,10 +59,34 @@ func (s *Server) HealthHandler() http.HandlerFunc {
 			},
 		}),
 
+		// Reports down while the circuit breaker is rejecting calls to i-Ma'luum
+		health.WithCheck(health.Check{
+			Name: "i-Ma'luum Circuit Breaker",
+			Check: func(_ context.Context) error {
+				stats := s.upstream.Breaker.Stats()
+				if stats.State != upstream.StateClosed {
+					return fmt.Errorf("circuit breaker is %s after %d consecutive failures", stats.State, stats.ConsecutiveFailures)
+				}
+				return nil
+			},
+		}),
+
+		// Reports down while an endpoint keeps parsing zero rows from pages that used to parse,
+		// which usually means i-Ma'luum changed its markup
+		health.WithCheck(health.Check{
+			Name: "i-Ma'luum Markup",
+			Check: func(_ context.Context) error {
+				if drifting := s.imaluum.ParseHealth().Drifting(); len(drifting) > 0 {
+					return fmt.Errorf("no rows parsed lately from: %s", strings.Join(drifting, ", "))
+				}
+				return nil
+			},
+		}),
+
 		health.WithCheck(health.Check{
 			Name: "i-Ma'luum Official Website",
 			Check: func(_ context.Context) error {
-				_, err := http.Get("https://imaluum.iium.edu.my")
+				_, err := http.Get(s.grpc.urls.ImaluumPage())
 				return err
 			},
 		}),
@@ -70,7 +98,23 @@ func (s *Server) HealthHandler() http.HandlerFunc {
 		// }),
 	)
 
-	handler := health.NewHandler(checker)
+	handler := health.NewHandler(checker, health.WithMiddleware(s.upstreamHealthInfo))
 
 	return handler
 }
+
+// upstreamHealthInfo adds the live circuit breaker state and the
+// per-endpoint parse counters to every health result
+func (s *Server) upstreamHealthInfo(next health.MiddlewareFunc) health.MiddlewareFunc {
+	return func(r *http.Request) health.CheckerResult {
+		result := next(r)
+
+		info := make(map[string]any, len(result.Info)+2)
+		maps.Copy(info, result.Info)
+		info["upstream_circuit_breaker"] = s.upstream.Breaker.Stats()
+		info["parse_health"] = s.imaluum.ParseHealth().Snapshot()
+		result.Info = info
+
+		return result
+	}
+}
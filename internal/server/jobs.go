@@ -0,0 +1,139 @@
+package server
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/scheduler"
+)
+
+// @Title ListJobsHandler
+// @Description List the background jobs in the order they are due. Requires ADMIN_TOKEN.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Insert the admin token" default(Bearer <Add admin token here>)
+// @Param kind query string false "Only jobs of this kind, e.g. sync.result"
+// @Success 200 {object} dtos.ResponseDTO{data=[]dtos.Job}
+// @Router /api/admin/jobs [get]
+func (s *Server) ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	logger := s.log.GetLogger()
+
+	jobs, err := s.scheduler.List(r.Context(), r.URL.Query().Get("kind"))
+	if err != nil {
+		logger.Sugar().Errorf("Failed to list jobs: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	data := make([]dtos.Job, len(jobs))
+	for i, job := range jobs {
+		data[i] = jobDTO(job)
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Successfully fetched jobs",
+		Data:    data,
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title PauseJobHandler
+// @Description Pause a background job, a running job finishes. Requires ADMIN_TOKEN.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Insert the admin token" default(Bearer <Add admin token here>)
+// @Param name path string true "Job name, e.g. sync.result:2110000"
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.Job}
+// @Router /api/admin/jobs/{name}/pause [post]
+func (s *Server) PauseJobHandler(w http.ResponseWriter, r *http.Request) {
+	s.updateJob(w, r, "Job paused", func(name string) error {
+		return s.scheduler.SetPaused(r.Context(), name, true)
+	})
+}
+
+// @Title ResumeJobHandler
+// @Description Resume a paused background job. Requires ADMIN_TOKEN.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Insert the admin token" default(Bearer <Add admin token here>)
+// @Param name path string true "Job name, e.g. sync.result:2110000"
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.Job}
+// @Router /api/admin/jobs/{name}/resume [post]
+func (s *Server) ResumeJobHandler(w http.ResponseWriter, r *http.Request) {
+	s.updateJob(w, r, "Job resumed", func(name string) error {
+		return s.scheduler.SetPaused(r.Context(), name, false)
+	})
+}
+
+// @Title RunJobHandler
+// @Description Make a background job due now, it runs on the next scheduler tick unless paused. Requires ADMIN_TOKEN.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Insert the admin token" default(Bearer <Add admin token here>)
+// @Param name path string true "Job name, e.g. sync.result:2110000"
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.Job}
+// @Router /api/admin/jobs/{name}/run [post]
+func (s *Server) RunJobHandler(w http.ResponseWriter, r *http.Request) {
+	s.updateJob(w, r, "Job scheduled to run", func(name string) error {
+		return s.scheduler.Trigger(r.Context(), name)
+	})
+}
+
+// updateJob applies update to the job named in the path and responds with the job
+func (s *Server) updateJob(w http.ResponseWriter, r *http.Request, message string, update func(name string) error) {
+	w.Header().Set("Content-Type", "application/json")
+
+	name := chi.URLParam(r, "name")
+
+	if err := update(name); err != nil {
+		s.renderJobError(w, r, name, err)
+		return
+	}
+
+	job, err := s.scheduler.Get(r.Context(), name)
+	if err != nil {
+		s.renderJobError(w, r, name, err)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: message,
+		Data:    jobDTO(job),
+	}
+
+	s.respond(w, r, response)
+}
+
+func (s *Server) renderJobError(w http.ResponseWriter, r *http.Request, name string, err error) {
+	if stderrors.Is(err, scheduler.ErrJobNotFound) {
+		errors.Render(w, r, errors.ErrJobNotFound)
+		return
+	}
+	s.log.GetLogger().Sugar().Errorf("Failed to update job %s: %v", name, err)
+	errors.Render(w, r, errors.ErrFailedToQueryDB)
+}
+
+func jobDTO(job scheduler.Job) dtos.Job {
+	data := dtos.Job{
+		Name:            job.Name,
+		Kind:            job.Kind,
+		Key:             job.Key,
+		IntervalSeconds: int64(job.Interval.Seconds()),
+		NextRunAt:       job.NextRunAt.Unix(),
+		LastDurationMs:  job.LastDuration.Milliseconds(),
+		LastError:       job.LastError,
+		CreatedAt:       job.CreatedAt.Unix(),
+		Paused:          job.Paused,
+	}
+	if job.LastRunAt != nil {
+		lastRunAt := job.LastRunAt.Unix()
+		data.LastRunAt = &lastRunAt
+	}
+	return data
+}
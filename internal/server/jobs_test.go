@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listJobs(t *testing.T, api *httptest.Server, query string) []dtos.Job {
+	t.Helper()
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/admin/jobs"+query, testAdminToken, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+
+	var jobs []dtos.Job
+	require.NoError(t, json.Unmarshal(response.Data, &jobs))
+	return jobs
+}
+
+func TestAdminJobs(t *testing.T) {
+	s, _ := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(receiver.Close)
+
+	resp, _ := do(t, http.MethodGet, api.URL+"/api/admin/jobs", "", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, _ = do(t, http.MethodGet, api.URL+"/api/admin/jobs", "wrong", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	assert.Empty(t, listJobs(t, api, ""))
+
+	token := login(t, api)
+	registerWebhook(t, api, token, receiver.URL)
+
+	jobs := listJobs(t, api, "")
+	require.Len(t, jobs, 3)
+	for _, job := range jobs {
+		assert.Equal(t, fakeimaluum.Username, job.Key)
+		assert.Nil(t, job.LastRunAt, job.Name)
+	}
+
+	jobs = listJobs(t, api, "?kind="+jobSyncResult)
+	require.Len(t, jobs, 1)
+	name := jobs[0].Name
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/admin/jobs/"+name+"/pause", testAdminToken, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), `"paused":true`)
+
+	// A paused job is skipped even when due
+	runJobs(t, s)
+	assert.Nil(t, listJobs(t, api, "?kind="+jobSyncResult)[0].LastRunAt)
+
+	resp, body = do(t, http.MethodPost, api.URL+"/api/admin/jobs/"+name+"/resume", testAdminToken, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), `"paused":false`)
+
+	resp, body = do(t, http.MethodPost, api.URL+"/api/admin/jobs/"+name+"/run", testAdminToken, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	runJobs(t, s)
+	job := listJobs(t, api, "?kind="+jobSyncResult)[0]
+	assert.NotNil(t, job.LastRunAt)
+	assert.Nil(t, job.LastError)
+
+	resp, _ = do(t, http.MethodPost, api.URL+"/api/admin/jobs/"+jobSyncResult+":unknown/run", testAdminToken, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAdminJobsDisabled(t *testing.T) {
+	s, _ := newTestBackend(t)
+	s.adminToken = ""
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	resp, _ := do(t, http.MethodGet, api.URL+"/api/admin/jobs", testAdminToken, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+)
+
+// fetchSection scrapes a section of /api/me. It returns the scrape as stored
+// in snapshots and the data sent to the client.
+type fetchSection func(ctx context.Context, cookie string) (scraped, data any, err error)
+
+func (s *Server) meSections() map[string]fetchSection {
+	return map[string]fetchSection{
+		snapshotProfile: func(ctx context.Context, cookie string) (any, any, error) {
+			profile, err := s.imaluum.Profile(ctx, cookie)
+			if err != nil {
+				return nil, nil, err
+			}
+			return profile, profile, nil
+		},
+		snapshotSchedule: func(ctx context.Context, cookie string) (any, any, error) {
+			schedules, err := s.imaluum.Schedule(ctx, cookie)
+			if err != nil {
+				return nil, nil, err
+			}
+			return schedules, imaluum.ScheduleV2(schedules), nil
+		},
+		snapshotResult: func(ctx context.Context, cookie string) (any, any, error) {
+			results, err := s.imaluum.Results(ctx, cookie)
+			if err != nil {
+				return nil, nil, err
+			}
+			return results, s.gradePoints.CheckAll(imaluum.ResultsV2(results)), nil
+		},
+		snapshotStarpoint: func(ctx context.Context, cookie string) (any, any, error) {
+			starpoint, err := s.imaluum.Starpoint(ctx, cookie)
+			if err != nil {
+				return nil, nil, err
+			}
+			return starpoint, imaluum.StarpointV2(starpoint), nil
+		},
+	}
+}
+
+// parseInclude reads the comma separated sections of ?include=, every section
+// when it is empty
+func parseInclude(include string) ([]string, error) {
+	if strings.TrimSpace(include) == "" {
+		return snapshotKinds, nil
+	}
+
+	var sections []string
+	for _, section := range strings.Split(include, ",") {
+		section = strings.TrimSpace(section)
+		if !slices.Contains(snapshotKinds, section) {
+			return nil, errors.WithDetails(errors.ErrInvalidSection, map[string]any{"sections": snapshotKinds})
+		}
+		if !slices.Contains(sections, section) {
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+// @Title MeHandler
+// @Description Get profile, schedule, result and starpoint in one call, scraped concurrently with one session.
+// @Description Each section holds its data in the shape of its /api/v2 endpoint, or the error that kept it from being fetched.
+// @Description The call only fails when every section does.
+// @Tags scraper
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param include query string false "Comma separated sections to fetch, all of them by default" example(profile,schedule)
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.Me}
+// @Router /api/me [get]
+func (s *Server) MeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	sections, err := parseInclude(r.URL.Query().Get("include"))
+	if err != nil {
+		errors.Render(w, r, err)
+		return
+	}
+
+	var (
+		logger      = s.log.GetLogger()
+		cookie      = r.Context().Value(ctxToken).(string)
+		user        = r.Context().Value(ctxUser).(*TokenPayload)
+		diagnostics = imaluum.NewDiagnostics()
+		ctx         = imaluum.WithDiagnostics(r.Context(), diagnostics)
+		fetchers    = s.meSections()
+		fetched     = make([]*dtos.MeSection, len(sections))
+		failures    = make([]error, len(sections))
+		wg          sync.WaitGroup
+	)
+
+	for i, section := range sections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			scraped, data, err := fetchers[section](ctx, cookie)
+			if err != nil {
+				logger.Sugar().Errorf("Failed to get %s: %v", section, err)
+				customErr := errors.From(err)
+				failures[i] = customErr
+				fetched[i] = &dtos.MeSection{Error: &dtos.SectionError{
+					Code:    customErr.Code,
+					Message: customErr.Message,
+					Status:  customErr.GetStatusCode(),
+				}}
+				return
+			}
+
+			s.keepSnapshot(r.Context(), user.username, section, scraped)
+			fetched[i] = &dtos.MeSection{Data: data}
+		}()
+	}
+	wg.Wait()
+
+	var (
+		me     = &dtos.Me{}
+		failed = 0
+	)
+	for i, section := range sections {
+		if failures[i] != nil {
+			failed++
+		}
+		switch section {
+		case snapshotProfile:
+			me.Profile = fetched[i]
+		case snapshotSchedule:
+			me.Schedule = fetched[i]
+		case snapshotResult:
+			me.Result = fetched[i]
+		case snapshotStarpoint:
+			me.Starpoint = fetched[i]
+		}
+	}
+
+	if failed == len(sections) {
+		errors.Render(w, r, failures[0])
+		return
+	}
+
+	message := "Successfully fetched " + strings.Join(sections, ", ")
+	if failed > 0 {
+		message = fmt.Sprintf("Fetched %d of %d sections", len(sections)-failed, len(sections))
+	}
+
+	response := &dtos.ResponseDTO{
+		Message:  message,
+		Data:     me,
+		Warnings: parseWarnings(w, diagnostics),
+	}
+
+	s.respond(w, r, response)
+}
+
+// keepSnapshot records a scrape for users who opted in to snapshots, like
+// tagResponse does for the single endpoints
+func (s *Server) keepSnapshot(ctx context.Context, matricNo, kind string, scraped any) {
+	payload, hash, err := snapshot(scraped)
+	if err == nil {
+		_, err = s.recordSnapshot(ctx, matricNo, kind, payload, hash)
+	}
+	if err != nil {
+		s.log.GetLogger().Sugar().Warnf("Failed to keep %s snapshot of %s: %v", kind, matricNo, err)
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/constants"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMe struct {
+	Profile   *testMeSection `json:"profile"`
+	Schedule  *testMeSection `json:"schedule"`
+	Result    *testMeSection `json:"result"`
+	Starpoint *testMeSection `json:"starpoint"`
+}
+
+type testMeSection struct {
+	Error *dtos.SectionError `json:"error"`
+	Data  json.RawMessage    `json:"data"`
+}
+
+func getMe(t *testing.T, api *httptest.Server, token, query string) (testResponse, testMe) {
+	t.Helper()
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/me"+query, token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+
+	var me testMe
+	require.NoError(t, json.Unmarshal(response.Data, &me))
+	return response, me
+}
+
+func TestMe(t *testing.T) {
+	s, _ := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	token := login(t, api)
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/snapshots", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	response, me := getMe(t, api, token, "")
+	assert.Equal(t, "Successfully fetched profile, schedule, result, starpoint", response.Message)
+
+	for name, section := range map[string]*testMeSection{
+		"profile": me.Profile, "schedule": me.Schedule, "result": me.Result, "starpoint": me.Starpoint,
+	} {
+		require.NotNil(t, section, name)
+		assert.Nil(t, section.Error, name)
+		assert.NotEmpty(t, section.Data, name)
+	}
+	assert.Contains(t, string(me.Profile.Data), fakeimaluum.Username)
+	assertScheduleV2(t, me.Schedule.Data)
+	assert.Contains(t, string(me.Starpoint.Data), "cumulative_average")
+
+	// Every section was kept as a snapshot
+	assert.Equal(t, 4, countRows(t, s, `SELECT COUNT(*) FROM snapshot_history`))
+
+	_, me = getMe(t, api, token, "?include=starpoint,profile,starpoint")
+	assert.NotNil(t, me.Profile)
+	assert.NotNil(t, me.Starpoint)
+	assert.Nil(t, me.Schedule)
+	assert.Nil(t, me.Result)
+
+	resp, body = do(t, http.MethodGet, api.URL+"/api/me?include=profile,exam", token, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var failure testResponse
+	require.NoError(t, json.Unmarshal(body, &failure))
+	assert.Equal(t, errors.CodeInvalidSection, failure.Code)
+}
+
+// brokenPage serves i-Ma'luum from fake, except for an empty page at path
+func brokenPage(t *testing.T, fake *fakeimaluum.Server, path string) constants.Upstream {
+	t.Helper()
+
+	target, err := url.Parse(fake.URL)
+	require.NoError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == path {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><body></body></html>"))
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(broken.Close)
+
+	urls := fake.Upstream()
+	urls.ImaluumBaseURL = broken.URL
+	return urls
+}
+
+func TestMePartial(t *testing.T) {
+	s, fake := newTestBackend(t)
+	s.imaluum = imaluum.New(s.httpClient, brokenPage(t, fake, constants.ImaluumStarpointPath), s.log)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	token := login(t, api)
+
+	response, me := getMe(t, api, token, "")
+	assert.Equal(t, "Fetched 3 of 4 sections", response.Message)
+	assert.Equal(t, errors.CodeOK, response.Code)
+
+	require.NotNil(t, me.Starpoint)
+	assert.Empty(t, me.Starpoint.Data)
+	require.NotNil(t, me.Starpoint.Error)
+	assert.Equal(t, errors.ErrNoStarpoint.Code, me.Starpoint.Error.Code)
+	assert.Equal(t, errors.ErrNoStarpoint.StatusCode, me.Starpoint.Error.Status)
+
+	require.NotNil(t, me.Result)
+	assert.Nil(t, me.Result.Error)
+	assert.NotEmpty(t, me.Result.Data)
+
+	// With nothing fetched the call fails as the section did
+	resp, body := do(t, http.MethodGet, api.URL+"/api/me?include=starpoint", token, "")
+	assert.Equal(t, errors.ErrNoStarpoint.StatusCode, resp.StatusCode, string(body))
+}
@@ -2,14 +2,23 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
+	stderrors "errors"
 	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
 )
 
 type originCookie int
 
 const (
 	ctxToken originCookie = iota
+	// ctxUser holds the decoded *TokenPayload
+	ctxUser
 )
 
 func (s *Server) PasetoAuthenticator() func(http.Handler) http.Handler {
@@ -27,23 +36,33 @@ func (s *Server) PasetoAuthenticator() func(http.Handler) http.Handler {
 
 			if fullAuthHeader == "" || len(fullAuthHeader) < 7 || fullAuthHeader[:7] != "Bearer " {
 				logger.Sugar().Warn("Authorization header is missing or invalid")
-				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				errors.Render(w, r, errors.ErrMissingToken)
 				return
 			}
 
 			authHeader := fullAuthHeader[7:]
 
 			token, err := s.DecodePasetoToken(authHeader)
+			if stderrors.Is(err, errSessionRefresh) {
+				// The token is genuine, only the i-Ma'luum session is missing,
+				// which stored snapshots do without
+				if r.URL.Query().Get("source") != sourceCache {
+					logger.Sugar().Warnf("Failed to refresh session: %v", err)
+					renderSessionRefreshError(w, r, err)
+					return
+				}
+				err = nil
+			}
 			if err != nil {
 				logger.Sugar().Errorf("Failed to decode token: %v", err)
 
-				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				errors.Render(w, r, errors.ErrInvalidToken)
 				return
 			}
 
 			if token == nil {
 				logger.Sugar().Warn("Token is empty")
-				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				errors.Render(w, r, errors.ErrInvalidToken)
 				return
 			}
 
@@ -51,6 +70,8 @@ func (s *Server) PasetoAuthenticator() func(http.Handler) http.Handler {
 
 			// Create a new context from the request context and add the token to it
 			ctx := context.WithValue(r.Context(), ctxToken, token.imaluumCookie)
+			ctx = context.WithValue(ctx, ctxUser, token)
+			ctx = imaluum.WithMatricNo(ctx, token.username)
 
 			// Token is authenticated, pass it through
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -58,3 +79,132 @@ func (s *Server) PasetoAuthenticator() func(http.Handler) http.Handler {
 		return http.HandlerFunc(hfn)
 	}
 }
+
+// AdminAuthenticator guards operator endpoints with the ADMIN_TOKEN bearer
+// token. The endpoints do not exist while ADMIN_TOKEN is unset.
+func (s *Server) AdminAuthenticator() func(http.Handler) http.Handler {
+	logger := s.log.GetLogger()
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			if s.adminToken == "" {
+				http.NotFound(w, r)
+				return
+			}
+
+			token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+				logger.Sugar().Warnf("Rejected admin request to %s", r.URL.Path)
+				errors.Render(w, r, errors.ErrAdminUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+const requestIDHeader = "X-Request-Id"
+
+// exposeRequestID echoes the ID middleware.RequestID gave the request, which
+// error bodies also carry, so clients can quote it in bug reports
+func exposeRequestID(next http.Handler) http.Handler {
+	hfn := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestIDHeader, middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(hfn)
+}
+
+// renderSessionRefreshError reports a session i-Ma'luum refused to renew,
+// such as after a password change, as expired and passes on other failures,
+// reporting i-Ma'luum unavailable when the cause is unknown
+func renderSessionRefreshError(w http.ResponseWriter, r *http.Request, err error) {
+	var customErr *errors.CustomError
+	switch {
+	case !stderrors.As(err, &customErr):
+		customErr = errors.ErrUpstreamUnavailable
+	case customErr.Code == errors.CodeInvalidCredentials:
+		customErr = errors.Wrap(errors.ErrSessionExpired, err)
+	}
+	errors.Render(w, r, customErr)
+}
+
+// conditional answers with 304 Not Modified when the 200 response about to be
+// sent carries an ETag listed in If-None-Match, or a Last-Modified no later
+// than If-Modified-Since. The handler still runs, only the body is dropped.
+func conditional(next http.Handler) http.Handler {
+	hfn := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == "" && r.Header.Get("If-Modified-Since") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(&conditionalWriter{ResponseWriter: w, r: r}, r)
+	}
+	return http.HandlerFunc(hfn)
+}
+
+type conditionalWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	wroteHeader bool
+	notModified bool
+}
+
+func (cw *conditionalWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	if status == http.StatusOK && notModified(cw.r, cw.Header()) {
+		cw.notModified = true
+		cw.Header().Del("Content-Type")
+		cw.Header().Del("Content-Length")
+		cw.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *conditionalWriter) Write(p []byte) (int, error) {
+	cw.WriteHeader(http.StatusOK)
+	if cw.notModified {
+		return len(p), nil
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// notModified evaluates If-None-Match with weak comparison and falls back to
+// If-Modified-Since only when no If-None-Match was sent, as in RFC 9110
+func notModified(r *http.Request, header http.Header) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		tag := header.Get("ETag")
+		if tag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
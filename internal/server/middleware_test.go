@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	conditionalGet := func(path, header, value string) (*http.Response, []byte) {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodGet, api.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(header, value)
+
+		return doRequest(t, req)
+	}
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/schedule", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	tag := resp.Header.Get("ETag")
+	require.NotEmpty(t, tag)
+	// Not opted in to snapshots, so there is no modification time to tell
+	assert.Empty(t, resp.Header.Get("Last-Modified"))
+
+	resp, body = conditionalGet("/api/schedule", "If-None-Match", tag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, body)
+
+	// A fresh scrape of the same timetable keeps its ETag
+	resp, body = conditionalGet("/api/schedule?fresh=true", "If-None-Match", `"other", `+tag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, body)
+
+	resp, body = conditionalGet("/api/schedule", "If-None-Match", `"other"`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, body)
+
+	// Snapshots date the content
+	resp, body = do(t, http.MethodPost, api.URL+"/api/snapshots", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	resp, body = do(t, http.MethodGet, api.URL+"/api/result?fresh=true", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	modified := resp.Header.Get("Last-Modified")
+	require.NotEmpty(t, modified)
+
+	resp, _ = conditionalGet("/api/result", "If-Modified-Since", modified)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp, _ = conditionalGet("/api/result?source=cache", "If-None-Match", resp.Header.Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp, _ = conditionalGet("/api/result", "If-Modified-Since", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestNotModified(t *testing.T) {
+	header := http.Header{}
+	header.Set("ETag", `W/"abc"`)
+	header.Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   bool
+	}{
+		{"weak match", "If-None-Match", `W/"abc"`, true},
+		{"strong tag matches weakly", "If-None-Match", `"abc"`, true},
+		{"listed", "If-None-Match", `"x", W/"abc"`, true},
+		{"any", "If-None-Match", `*`, true},
+		{"other tag", "If-None-Match", `"abd"`, false},
+		{"not modified since", "If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT", true},
+		{"modified since", "If-Modified-Since", "Mon, 02 Jan 2006 15:04:04 GMT", false},
+		{"unparseable date", "If-Modified-Since", "yesterday", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(tt.header, tt.value)
+			assert.Equal(t, tt.want, notModified(r, header))
+		})
+	}
+}
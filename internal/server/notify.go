@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/pkg/push"
+)
+
+// notify pushes notification to every subscription of matricNo and returns
+// how many received it. Subscriptions the push service reports gone are
+// deleted.
+func (s *Server) notify(ctx context.Context, matricNo string, notification dtos.PushNotification) (int, error) {
+	if s.pusher == nil {
+		return 0, nil
+	}
+
+	subscriptions, err := s.pushSubscriptions(ctx, matricNo)
+	if err != nil {
+		return 0, err
+	}
+
+	payload, err := sonic.Marshal(notification)
+	if err != nil {
+		return 0, err
+	}
+
+	// Topics are limited to the base64url alphabet
+	topic := strings.ReplaceAll(notification.Type, ".", "-")
+
+	var (
+		delivered int
+		errs      []error
+		gone      bool
+	)
+	for _, subscription := range subscriptions {
+		err := s.pusher.Send(ctx, subscription, payload, topic)
+		switch {
+		case err == nil:
+			delivered++
+		case stderrors.Is(err, push.ErrGone):
+			gone = true
+			if _, err := s.db.ExecContext(ctx, `DELETE FROM push_subscriptions WHERE endpoint = ?`, subscription.Endpoint); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	if gone {
+		errs = append(errs, s.unwatchIfUnused(ctx, matricNo))
+	}
+
+	return delivered, stderrors.Join(errs...)
+}
+
+func (s *Server) pushSubscriptions(ctx context.Context, matricNo string) ([]push.Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+			SELECT endpoint, p256dh, auth FROM push_subscriptions WHERE matric_no = ?
+		`, matricNo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []push.Subscription
+	for rows.Next() {
+		var subscription push.Subscription
+		if err := rows.Scan(&subscription.Endpoint, &subscription.P256dh, &subscription.Auth); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
@@ -2,7 +2,8 @@ package server
 
 import (
 	"context"
-	"log"
+	stderrors "errors"
+	"fmt"
 	"time"
 
 	"github.com/cristalhq/base64"
@@ -11,6 +12,10 @@ import (
 	pb "github.com/nrmnqdds/gomaluum/internal/proto"
 )
 
+// errSessionRefresh comes with the verified payload of an expired token when
+// i-Ma'luum could not issue a new session for it
+var errSessionRefresh = stderrors.New("failed to refresh i-Ma'luum session")
+
 type TokenPayload struct {
 	username      string
 	password      string
@@ -110,7 +115,10 @@ func (s *Server) DecodePasetoToken(token string) (*TokenPayload, error) {
 		newToken, err := s.tokenManager.GetToken(username, refresh)
 		if err != nil {
 			logger.Sugar().Errorf("Failed to get token: %v", err)
-			log.Fatal(err)
+			return &TokenPayload{
+				username: username,
+				password: string(decodedPassword),
+			}, fmt.Errorf("%w: %w", errSessionRefresh, err)
 		}
 
 		logger.Sugar().Infof("Refreshed token: %s with origin for user: %s", newToken, username)
@@ -129,10 +137,16 @@ func (s *Server) DecodePasetoToken(token string) (*TokenPayload, error) {
 	// If token not expired yet
 	imaluumCookie, _ := decodedToken.GetString("imaluumCookie")
 
+	decodedPassword, err := base64.StdEncoding.DecodeString(password)
+	if err != nil {
+		logger.Sugar().Errorf("Failed to decode password: %v", err)
+		return nil, err
+	}
+
 	go s.UpdateAnalytics(username)
 	return &TokenPayload{
 		username:      username,
-		password:      password,
+		password:      string(decodedPassword),
 		imaluumCookie: imaluumCookie,
 	}, nil
 }
@@ -3,7 +3,6 @@ package server
 import (
 	"net/http"
 
-	"github.com/bytedance/sonic"
 	"github.com/nrmnqdds/gomaluum/internal/dtos"
 	"github.com/nrmnqdds/gomaluum/internal/errors"
 )
@@ -13,30 +12,61 @@ import (
 // @Tags scraper
 // @Produce json
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param source query string false "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots" Enums(cache)
+// @Param fresh query bool false "Skip the response cache and refetch from i-Ma'luum"
+// @Param If-None-Match header string false "ETag of an earlier response, answered with 304 Not Modified while unchanged"
 // @Success 200 {object} dtos.ResponseDTO
+// @Header 200 {string} ETag "Fingerprint of the data"
+// @Header 200 {string} Last-Modified "When the data first appeared, once snapshots are enabled"
+// @Header 200 {string} Deprecation "When this route was deprecated, in favour of /api/v2/profile"
+// @Header 200 {string} Sunset "When this route may be removed"
+// @Deprecated
 // @Router /api/profile [get]
 func (s *Server) ProfileHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	if r.URL.Query().Get("source") == sourceCache {
+		s.serveSnapshot(w, r, snapshotProfile, "Successfully fetched profile from cache", nil)
+		return
+	}
+
 	var (
 		logger = s.log.GetLogger()
 		cookie = r.Context().Value(ctxToken).(string)
 	)
 
-	profile, err := s.Profile(cookie)
+	profile, err := s.imaluum.Profile(r.Context(), cookie)
 	if err != nil {
 		logger.Sugar().Errorf("Failed to get profile: %v", err)
 		errors.Render(w, r, err)
 		return
 	}
 
+	s.tagResponse(w, r, snapshotProfile, func() (string, string, error) {
+		return snapshot(profile)
+	})
+
 	response := &dtos.ResponseDTO{
 		Message: "Successfully fetched profile",
 		Data:    profile,
 	}
 
-	if err := sonic.ConfigFastest.NewEncoder(w).Encode(response); err != nil {
-		logger.Sugar().Errorf("Failed to encode response: %v", err)
-		errors.Render(w, r, errors.ErrFailedToEncodeResponse)
-	}
+	s.respond(w, r, response)
+}
+
+// @Title ProfileV2Handler
+// @Description Get i-Ma'luum profile, the same as /api/profile
+// @Tags v2
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param source query string false "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots" Enums(cache)
+// @Param fresh query bool false "Skip the response cache and refetch from i-Ma'luum"
+// @Param If-None-Match header string false "ETag of an earlier response, answered with 304 Not Modified while unchanged"
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.Profile}
+// @Header 200 {string} ETag "Fingerprint of the data"
+// @Header 200 {string} Last-Modified "When the data first appeared, once snapshots are enabled"
+// @Router /api/v2/profile [get]
+func (s *Server) ProfileV2Handler(w http.ResponseWriter, r *http.Request) {
+	// The profile kept its v1 shape
+	s.ProfileHandler(w, r)
 }
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/push"
+)
+
+// @Title PushPublicKeyHandler
+// @Description Get the VAPID public key, the applicationServerKey for pushManager.subscribe()
+// @Tags push
+// @Produce json
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.PushPublicKey}
+// @Router /api/push/public-key [get]
+func (s *Server) PushPublicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.pusher == nil {
+		errors.Render(w, r, errors.ErrPushDisabled)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Successfully fetched public key",
+		Data:    dtos.PushPublicKey{PublicKey: s.pusher.PublicKey},
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title SubscribePushHandler
+// @Description Registers a browser push subscription. Results, timetable and starpoints are polled in the background and pushed when they change, the first poll only records a baseline.
+// @Tags push
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param body body dtos.PushSubscriptionRequest true "PushSubscription.toJSON()"
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.PushSubscription}
+// @Router /api/push/subscriptions [post]
+func (s *Server) SubscribePushHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+		req    dtos.PushSubscriptionRequest
+	)
+
+	if s.pusher == nil {
+		errors.Render(w, r, errors.ErrPushDisabled)
+		return
+	}
+
+	if err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Sugar().Errorf("Failed to decode request body: %v", err)
+		errors.Render(w, r, errors.ErrInvalidRequest)
+		return
+	}
+
+	subscription := push.Subscription{
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+	}
+	if err := s.pusher.Validate(subscription); err != nil {
+		errors.Render(w, r, errors.Wrap(errors.ErrInvalidPushSubscription, err))
+		return
+	}
+
+	if err := s.watchUser(r.Context(), user); err != nil {
+		logger.Sugar().Errorf("Failed to watch user: %v", err)
+		errors.Render(w, r, watchUserError(err))
+		return
+	}
+
+	registered := dtos.PushSubscription{
+		Endpoint:  subscription.Endpoint,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	// A browser keeps its endpoint across logins, the latest user owns it
+	if _, err := s.db.ExecContext(r.Context(), `
+			INSERT INTO push_subscriptions (endpoint, matric_no, p256dh, auth, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(endpoint)
+			DO UPDATE SET matric_no = excluded.matric_no, p256dh = excluded.p256dh, auth = excluded.auth, created_at = excluded.created_at
+		`, subscription.Endpoint, user.username, subscription.P256dh, subscription.Auth, registered.CreatedAt); err != nil {
+		logger.Sugar().Errorf("Failed to save push subscription: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Push subscription registered",
+		Data:    registered,
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title UnsubscribePushHandler
+// @Description Removes a browser push subscription of the user. Background polling stops once the user has no subscription or webhook left.
+// @Tags push
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param body body dtos.PushUnsubscribeRequest true "Subscription endpoint"
+// @Success 200 {object} dtos.ResponseDTO
+// @Router /api/push/subscriptions [delete]
+func (s *Server) UnsubscribePushHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+		req    dtos.PushUnsubscribeRequest
+	)
+
+	if err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Sugar().Errorf("Failed to decode request body: %v", err)
+		errors.Render(w, r, errors.ErrInvalidRequest)
+		return
+	}
+
+	result, err := s.db.ExecContext(r.Context(), `
+			DELETE FROM push_subscriptions WHERE endpoint = ? AND matric_no = ?
+		`, req.Endpoint, user.username)
+	if err != nil {
+		logger.Sugar().Errorf("Failed to delete push subscription: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		errors.Render(w, r, errors.ErrPushSubscriptionNotFound)
+		return
+	}
+
+	if err := s.unwatchIfUnused(r.Context(), user.username); err != nil {
+		logger.Sugar().Errorf("Failed to unwatch user: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Push subscription removed",
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title TestPushHandler
+// @Description Sends a test notification to every push subscription of the user
+// @Tags push
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.PushTestResult}
+// @Router /api/push/test [post]
+func (s *Server) TestPushHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+	)
+
+	if s.pusher == nil {
+		errors.Render(w, r, errors.ErrPushDisabled)
+		return
+	}
+
+	delivered, err := s.notify(r.Context(), user.username, dtos.PushNotification{
+		Type:      "test",
+		Title:     "Notifications are working",
+		Body:      "You will be notified when your results, timetable or starpoints change.",
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		logger.Sugar().Warnf("Failed to send test push: %v", err)
+		if delivered == 0 {
+			errors.Render(w, r, errors.ErrFailedToSendPush)
+			return
+		}
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Test notification sent",
+		Data:    dtos.PushTestResult{Delivered: delivered},
+	}
+
+	s.respond(w, r, response)
+}
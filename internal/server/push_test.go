@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/push"
+	"github.com/nrmnqdds/gomaluum/pkg/push/pushtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPushTestServer serves the API with Web Push delivering to a local push service
+func newPushTestServer(t *testing.T) (*Server, *httptest.Server, *pushtest.Server, *fakeimaluum.Server) {
+	t.Helper()
+
+	s, fake := newTestBackend(t)
+
+	publicKey, privateKey, err := push.GenerateKeys()
+	require.NoError(t, err)
+	s.pusher = &push.Sender{
+		Client:     http.DefaultClient,
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		Subject:    "mailto:test@example.com",
+		AllowHTTP:  true,
+	}
+
+	service := pushtest.NewServer()
+	t.Cleanup(service.Close)
+
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	return s, api, service, fake
+}
+
+func subscribeBody(sub push.Subscription) string {
+	return `{"endpoint":"` + sub.Endpoint + `","keys":{"p256dh":"` + sub.P256dh + `","auth":"` + sub.Auth + `"}}`
+}
+
+func pushNotifications(t *testing.T, service *pushtest.Server) []dtos.PushNotification {
+	t.Helper()
+
+	var notifications []dtos.PushNotification
+	for _, message := range service.Messages() {
+		var notification dtos.PushNotification
+		require.NoError(t, json.Unmarshal(message.Payload, &notification))
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+func countRows(t *testing.T, s *Server, query string, args ...any) int {
+	t.Helper()
+
+	var count int
+	require.NoError(t, s.db.QueryRow(query, args...).Scan(&count))
+	return count
+}
+
+func TestPushRoutes(t *testing.T) {
+	s, api, service, _ := newPushTestServer(t)
+	token := login(t, api)
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/push/public-key", "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), s.pusher.PublicKey)
+
+	sub := service.Subscribe()
+	invalid := sub
+	invalid.Auth = "short"
+	resp, _ = do(t, http.MethodPost, api.URL+"/api/push/subscriptions", token, subscribeBody(invalid))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, body = do(t, http.MethodPost, api.URL+"/api/push/subscriptions", token, subscribeBody(sub))
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, 1, countRows(t, s, `SELECT COUNT(*) FROM watchers WHERE matric_no = ?`, fakeimaluum.Username))
+
+	resp, body = do(t, http.MethodPost, api.URL+"/api/push/test", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), `"delivered":1`)
+
+	notifications := pushNotifications(t, service)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, "test", notifications[0].Type)
+
+	resp, body = do(t, http.MethodDelete, api.URL+"/api/push/subscriptions", token, `{"endpoint":"`+sub.Endpoint+`"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Zero(t, countRows(t, s, `SELECT COUNT(*) FROM watchers`))
+
+	resp, _ = do(t, http.MethodDelete, api.URL+"/api/push/subscriptions", token, `{"endpoint":"`+sub.Endpoint+`"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestPushDisabled(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	resp, _ := do(t, http.MethodGet, api.URL+"/api/push/public-key", "", "")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, _ = do(t, http.MethodPost, api.URL+"/api/push/test", token, "")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestPushNotifications(t *testing.T) {
+	s, api, service, fake := newPushTestServer(t)
+	token := login(t, api)
+
+	sub := service.Subscribe()
+	resp, body := do(t, http.MethodPost, api.URL+"/api/push/subscriptions", token, subscribeBody(sub))
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	// The first poll only records a baseline, its three jobs log in once
+	logins := fake.Logins()
+	runJobs(t, s)
+	assert.Empty(t, service.Messages())
+	assert.Equal(t, logins+1, fake.Logins())
+	assert.Equal(t, 3, countRows(t, s, `SELECT COUNT(*) FROM snapshots WHERE matric_no = ?`, fakeimaluum.Username))
+
+	// Rewind every snapshot as if the latest scrape brought news
+	forgetGrade(t, s, fakeimaluum.Username)
+
+	var schedules []dtos.ScheduleResponse
+	loadSnapshot(t, s, fakeimaluum.Username, snapshotSchedule, &schedules)
+	require.NotEmpty(t, schedules[0].Schedule)
+	added := schedules[0].Schedule[0].CourseCode
+	schedules[0].Schedule = schedules[0].Schedule[1:]
+	storeStaleSnapshot(t, s, fakeimaluum.Username, snapshotSchedule, schedules)
+
+	var starpoint dtos.Starpoint
+	loadSnapshot(t, s, fakeimaluum.Username, snapshotStarpoint, &starpoint)
+	require.NotEmpty(t, starpoint.Programs)
+	starpoint.Programs = starpoint.Programs[1:]
+	storeStaleSnapshot(t, s, fakeimaluum.Username, snapshotStarpoint, starpoint)
+
+	runJobs(t, s)
+
+	notifications := pushNotifications(t, service)
+	require.Len(t, notifications, 3)
+
+	byType := make(map[string]dtos.PushNotification)
+	for _, notification := range notifications {
+		byType[notification.Type] = notification
+	}
+	assert.Equal(t, "New grades released", byType[EventResultReleased].Title)
+	assert.Contains(t, byType[EventScheduleChanged].Body, schedules[0].SessionName+": 1 added")
+	assert.Contains(t, fmt.Sprint(byType[EventScheduleChanged].Data), added)
+	assert.Contains(t, byType[EventStarpointAdded].Body, "(+")
+
+	// A revoked subscription is forgotten, and with it the watched user
+	service.Expire(sub)
+	forgetGrade(t, s, fakeimaluum.Username)
+	runJobs(t, s)
+
+	assert.Zero(t, countRows(t, s, `SELECT COUNT(*) FROM push_subscriptions`))
+	assert.Zero(t, countRows(t, s, `SELECT COUNT(*) FROM watchers`))
+	assert.Zero(t, countRows(t, s, `SELECT COUNT(*) FROM snapshots`))
+}
+
+func TestScheduleChanges(t *testing.T) {
+	subject := func(code, venue string) dtos.ScheduleSubject {
+		return dtos.ScheduleSubject{CourseCode: code, Venue: venue, Section: 1}
+	}
+	previous := []dtos.ScheduleResponse{{
+		SessionName: "Sem 1, 2024/2025",
+		Schedule:    []dtos.ScheduleSubject{subject("CSCI 1000", "LT1"), subject("CSCI 1001", "LT2")},
+	}}
+	latest := []dtos.ScheduleResponse{
+		{
+			SessionName: "Sem 1, 2024/2025",
+			Schedule:    []dtos.ScheduleSubject{subject("CSCI 1000", "LT3"), subject("CSCI 1002", "LT2")},
+		},
+		{
+			SessionName: "Sem 2, 2024/2025",
+			Schedule:    []dtos.ScheduleSubject{subject("CSCI 2000", "LT1")},
+		},
+	}
+
+	assert.Equal(t, []dtos.ScheduleChange{
+		{SessionName: "Sem 1, 2024/2025", Added: []string{"CSCI 1002"}, Removed: []string{"CSCI 1001"}, Changed: []string{"CSCI 1000"}},
+		{SessionName: "Sem 2, 2024/2025", Added: []string{"CSCI 2000"}},
+	}, scheduleChanges(previous, latest))
+
+	assert.Empty(t, scheduleChanges(latest, latest))
+}
+
+func TestScheduleChangesAcrossDays(t *testing.T) {
+	s, fake := newTestBackend(t)
+	cookie := fake.Login(fakeimaluum.Username)
+
+	today := time.Date(2024, time.October, 7, 12, 0, 0, 0, time.UTC)
+	yesterday := scrapeScheduleOn(t, s, cookie, today.AddDate(0, 0, -1))
+	latest := scrapeScheduleOn(t, s, cookie, today)
+
+	// The unix times follow the day of the scrape, the timetable is the same
+	require.NotEqual(t, yesterday[0].Schedule[0].Timestamps[0].StartUnix, latest[0].Schedule[0].Timestamps[0].StartUnix)
+	assert.Empty(t, scheduleChanges(yesterday, latest))
+}
+
+func TestAddedStarpoints(t *testing.T) {
+	program := func(id, event string) dtos.StarpointProgram {
+		return dtos.StarpointProgram{ID: id, EventName: event, Points: 2}
+	}
+
+	added := addedStarpoints(
+		&dtos.Starpoint{Programs: []dtos.StarpointProgram{program("1", "Talk")}},
+		&dtos.Starpoint{Programs: []dtos.StarpointProgram{program("2", "Talk"), program("3", "Talk"), program("4", "Run")}},
+	)
+	require.Len(t, added, 2)
+	assert.Equal(t, "Talk", added[0].EventName)
+	assert.Equal(t, "Run", added[1].EventName)
+}
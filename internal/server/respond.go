@@ -0,0 +1,182 @@
+package server
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/bytedance/sonic"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/klauspost/compress/zstd"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const compressionLevel = 5
+
+// compressedTypes are the content types worth compressing
+var compressedTypes = []string{
+	"application/json",
+	"application/msgpack",
+	"application/cbor",
+	"application/problem+json",
+	"text/html",
+	"text/css",
+	"text/plain",
+	"text/javascript",
+	"application/javascript",
+}
+
+// compress encodes responses with zstd, brotli, gzip or deflate, in that order
+// of preference among what the client accepts
+func compress() func(http.Handler) http.Handler {
+	compressor := middleware.NewCompressor(compressionLevel, compressedTypes...)
+	compressor.SetEncoder("br", func(w io.Writer, level int) io.Writer {
+		return brotli.NewWriterLevel(w, level)
+	})
+	compressor.SetEncoder("zstd", func(w io.Writer, level int) io.Writer {
+		encoder, err := zstd.NewWriter(w,
+			zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+			zstd.WithEncoderConcurrency(1),
+		)
+		if err != nil {
+			return nil
+		}
+		return encoder
+	})
+
+	return compressor.Handler
+}
+
+// responseFormat is an encoding of dtos.ResponseDTO a client may ask for
+type responseFormat struct {
+	// name tells the format apart in ETags
+	name        string
+	contentType string
+	encode      func(w io.Writer, v any) error
+}
+
+var (
+	formatJSON = &responseFormat{
+		name:        "json",
+		contentType: "application/json",
+		encode: func(w io.Writer, v any) error {
+			return sonic.ConfigFastest.NewEncoder(w).Encode(v)
+		},
+	}
+
+	formatMsgpack = &responseFormat{
+		name:        "msgpack",
+		contentType: "application/msgpack",
+		encode: func(w io.Writer, v any) error {
+			plain, err := plainValue(v)
+			if err != nil {
+				return err
+			}
+			body, err := msgpack.Marshal(plain)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(body)
+			return err
+		},
+	}
+
+	formatCBOR = &responseFormat{
+		name:        "cbor",
+		contentType: "application/cbor",
+		encode: func(w io.Writer, v any) error {
+			plain, err := plainValue(v)
+			if err != nil {
+				return err
+			}
+			body, err := cbor.Marshal(plain)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(body)
+			return err
+		},
+	}
+)
+
+// mediaTypes maps the accepted media types to their format
+var mediaTypes = map[string]*responseFormat{
+	"application/json":        formatJSON,
+	mediaTypeV2:               formatJSON,
+	"application/*":           formatJSON,
+	"*/*":                     formatJSON,
+	"application/msgpack":     formatMsgpack,
+	"application/x-msgpack":   formatMsgpack,
+	"application/vnd.msgpack": formatMsgpack,
+	"application/cbor":        formatCBOR,
+}
+
+// negotiate picks the format the Accept header prefers, JSON when it names
+// none that is supported
+func negotiate(r *http.Request) *responseFormat {
+	var (
+		best    = formatJSON
+		bestQ   = 0.0
+		accepts = strings.Split(r.Header.Get("Accept"), ",")
+	)
+
+	for _, accept := range accepts {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		format, ok := mediaTypes[mediaType]
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+
+	return best
+}
+
+// plainValue turns v into maps, slices and scalars the way it encodes to
+// JSON, so every format shares the JSON field names and embedded raw JSON
+// is decoded rather than sent as bytes
+func plainValue(v any) (any, error) {
+	body, err := sonic.ConfigFastest.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain any
+	err = sonic.Config{UseInt64: true}.Froze().Unmarshal(body, &plain)
+	return plain, err
+}
+
+// respond writes response in the format negotiated from the Accept header.
+// Its code is OK, where errors.Render writes the failure's code, next to the
+// same request ID as errors.
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, response *dtos.ResponseDTO) {
+	response.Code = errors.CodeOK
+	response.RequestID = middleware.GetReqID(r.Context())
+
+	format := negotiate(r)
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Add("Vary", "Accept")
+
+	if err := format.encode(w, response); err != nil {
+		s.log.GetLogger().Sugar().Errorf("Failed to encode response: %v", err)
+		errors.Render(w, r, errors.ErrFailedToEncodeResponse)
+	}
+}
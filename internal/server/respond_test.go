@@ -0,0 +1,136 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   *responseFormat
+	}{
+		{"", formatJSON},
+		{"*/*", formatJSON},
+		{"text/html", formatJSON},
+		{"application/msgpack", formatMsgpack},
+		{"application/x-msgpack", formatMsgpack},
+		{"application/cbor", formatCBOR},
+		{"application/json, application/cbor;q=0.5", formatJSON},
+		{"application/json;q=0.5, application/cbor", formatCBOR},
+		{"application/cbor;q=bad, application/msgpack;q=0.1", formatMsgpack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.accept)
+			assert.Equal(t, tt.want.name, negotiate(req).name)
+		})
+	}
+}
+
+func TestResponseFormats(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	get := func(accept string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, api.URL+"/api/starpoint", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", accept)
+		return doRequest(t, req)
+	}
+
+	resp, body := get("application/json")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	var fromJSON struct {
+		Data struct {
+			Programs []struct {
+				EventName string `json:"event_name"`
+				Semester  int    `json:"semester"`
+			} `json:"programs"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &fromJSON))
+	require.NotEmpty(t, fromJSON.Data.Programs)
+	program := fromJSON.Data.Programs[0]
+
+	cborDecoder, err := cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]any{})}.DecMode()
+	require.NoError(t, err)
+
+	decoders := map[string]func([]byte, any) error{
+		"application/msgpack": msgpack.Unmarshal,
+		"application/cbor":    cborDecoder.Unmarshal,
+	}
+	for contentType, unmarshal := range decoders {
+		t.Run(contentType, func(t *testing.T) {
+			resp, body := get(contentType)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, contentType, resp.Header.Get("Content-Type"))
+			assert.Contains(t, resp.Header.Values("Vary"), "Accept")
+			assert.Equal(t, "MISS", resp.Header.Get("X-Cache"), "formats are cached apart")
+
+			var decoded map[string]any
+			require.NoError(t, unmarshal(body, &decoded))
+			assert.NotEmpty(t, decoded["message"])
+
+			// Field names follow JSON and integers stay integers
+			data := decoded["data"].(map[string]any)
+			first := data["programs"].([]any)[0].(map[string]any)
+			assert.Equal(t, program.EventName, first["event_name"])
+			assert.EqualValues(t, program.Semester, first["semester"])
+		})
+	}
+}
+
+func TestCompression(t *testing.T) {
+	api, _ := newTestServer(t)
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		"zstd": func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	}
+	for encoding, decode := range decoders {
+		t.Run(encoding, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, api.URL+"/api/ads", nil)
+			require.NoError(t, err)
+			// Setting the header ourselves stops the client from decompressing
+			req.Header.Set("Accept-Encoding", encoding)
+
+			resp, body := doRequest(t, req)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, encoding, resp.Header.Get("Content-Encoding"))
+
+			reader, err := decode(bytes.NewReader(body))
+			require.NoError(t, err)
+			plain, err := io.ReadAll(reader)
+			require.NoError(t, err)
+
+			var response testResponse
+			require.NoError(t, json.Unmarshal(plain, &response))
+			assert.Contains(t, string(response.Data), "gomaluum:ad:")
+		})
+	}
+
+	// zstd is preferred over the rest
+	req, err := http.NewRequest(http.MethodGet, api.URL+"/api/ads", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br, zstd")
+	resp, _ := doRequest(t, req)
+	assert.Equal(t, "zstd", resp.Header.Get("Content-Encoding"))
+}
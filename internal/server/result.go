@@ -1,299 +1,144 @@
 package server
 
 import (
-	"fmt"
 	"net/http"
-	"slices"
-	"sort"
-	"strings"
-	"sync"
 
-	"github.com/PuerkitoBio/goquery"
-	"github.com/bytedance/sonic"
-	"github.com/gocolly/colly/v2"
-	"github.com/lucsky/cuid"
-	"github.com/nrmnqdds/gomaluum/internal/constants"
 	"github.com/nrmnqdds/gomaluum/internal/dtos"
 	"github.com/nrmnqdds/gomaluum/internal/errors"
-	"github.com/nrmnqdds/gomaluum/pkg/utils"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
 )
 
-// Object pools for result processing
-var resultPool = sync.Pool{
-	New: func() any {
-		return &dtos.Result{}
-	},
-}
-
-var resultStringSlicePool = sync.Pool{
-	New: func() any {
-		return make([]string, 0, 10)
-	},
-}
-
-// Worker pool structures for results
-type resultJob struct {
-	query string
-	name  string
-}
-
-type resultWorkerResult struct {
-	result dtos.ResultResponse
-	err    error
-}
+// @Title ResultHandler
+// @Description Get result from i-Ma'luum
+// @Tags scraper
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param source query string false "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots" Enums(cache)
+// @Param fresh query bool false "Skip the response cache and refetch from i-Ma'luum"
+// @Param If-None-Match header string false "ETag of an earlier response, answered with 304 Not Modified while unchanged"
+// @Success 200 {object} dtos.ResponseDTO
+// @Header 200 {string} ETag "Fingerprint of the data"
+// @Header 200 {string} Last-Modified "When the data first appeared, once snapshots are enabled"
+// @Header 200 {string} Deprecation "When this route was deprecated, in favour of /api/v2/result"
+// @Header 200 {string} Sunset "When this route may be removed"
+// @Deprecated
+// @Router /api/result [get]
+func (s *Server) ResultHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
 
-// Parse result table row with object pooling
-func parseResultRow(tds []string, subjects *[]dtos.Result, gpaInfo *map[string]string, mu *sync.Mutex) {
-	if len(tds) < 4 {
+	if r.URL.Query().Get("source") == sourceCache {
+		s.serveSnapshot(w, r, snapshotResult, "Successfully fetched results from cache", nil)
 		return
 	}
 
-	courseCode := strings.TrimSpace(tds[0])
-	courseName := strings.TrimSpace(tds[1])
-	courseGrade := strings.TrimSpace(tds[2])
-	courseCredit := strings.TrimSpace(tds[3])
+	var (
+		logger      = s.log.GetLogger()
+		cookie      = r.Context().Value(ctxToken).(string)
+		diagnostics = imaluum.NewDiagnostics()
+	)
 
-	words := strings.Fields(courseCode)
-	if len(words) == 0 {
+	results, err := s.imaluum.Results(imaluum.WithDiagnostics(r.Context(), diagnostics), cookie)
+	if err != nil {
+		logger.Sugar().Errorf("Failed to get results: %v", err)
+		errors.Render(w, r, err)
 		return
 	}
 
-	// Handle GPA information row
-	if words[0] == "Total" {
-		mu.Lock()
-		gpaWords := strings.Fields(courseName)
-
-		if len(gpaWords) > 1 {
-			(*gpaInfo)["chr"] = strings.TrimSpace(gpaWords[1])
-		}
-		if len(gpaWords) > 2 {
-			(*gpaInfo)["gpa"] = strings.TrimSpace(gpaWords[2])
-		}
-		if len(gpaWords) > 3 {
-			(*gpaInfo)["status"] = strings.TrimSpace(gpaWords[3])
-		}
+	s.tagResponse(w, r, snapshotResult, func() (string, string, error) {
+		return snapshot(results)
+	})
 
-		cgpaWords := strings.Fields(courseCredit)
-		if len(cgpaWords) > 2 {
-			(*gpaInfo)["cgpa"] = strings.TrimSpace(cgpaWords[2])
-		}
-		mu.Unlock()
-		return
+	response := &dtos.ResponseDTO{
+		Message:  "Successfully fetched results",
+		Data:     results,
+		Warnings: parseWarnings(w, diagnostics),
 	}
 
-	// Create result object
-	result := resultPool.Get().(*dtos.Result)
-	*result = dtos.Result{} // Reset
-
-	result.ID = fmt.Sprintf("gomaluum:subject:%s", cuid.Slug())
-	result.CourseCode = courseCode
-	result.CourseName = courseName
-	result.CourseGrade = courseGrade
-	result.CourseCredit = courseCredit
-
-	mu.Lock()
-	*subjects = append(*subjects, *result)
-	mu.Unlock()
-
-	resultPool.Put(result)
+	s.respond(w, r, response)
 }
 
-// Worker function for processing result sessions
-func (s *Server) resultWorker(jobs <-chan resultJob, results chan<- resultWorkerResult, cookie string) {
-	cookieStr := "MOD_AUTH_CAS=" + cookie
-
-	for job := range jobs {
-		func() {
-			defer utils.CatchPanic("result worker")
-
-			c := colly.NewCollector()
-			c.WithTransport(s.httpClient.Transport)
-
-			var (
-				mu       sync.Mutex
-				subjects []dtos.Result
-				gpaInfo  = map[string]string{
-					"gpa":    "0",
-					"cgpa":   "0",
-					"chr":    "0",
-					"status": "0",
-				}
-			)
-
-			c.OnRequest(func(r *colly.Request) {
-				r.Headers.Set("Cookie", cookieStr)
-				r.Headers.Set("User-Agent", cuid.New())
-			})
-
-			c.OnHTML("table.table-hover tbody tr", func(e *colly.HTMLElement) {
-				cells := e.DOM.Find("td")
-				if cells.Length() == 0 {
-					return
-				}
-
-				tds := resultStringSlicePool.Get().([]string)
-				tds = tds[:0] // Reset slice
-
-				cells.Each(func(_ int, s *goquery.Selection) {
-					tds = append(tds, s.Text())
-				})
-
-				parseResultRow(tds, &subjects, &gpaInfo, &mu)
-				resultStringSlicePool.Put(tds)
-			})
-
-			url := constants.ImaluumResultPage + job.query
-			if err := c.Visit(url); err != nil {
-				results <- resultWorkerResult{
-					err: errors.ErrFailedToGoToURL,
-				}
-				return
-			}
-
-			response := dtos.ResultResponse{
-				ID:           fmt.Sprintf("gomaluum:result:%s", cuid.Slug()),
-				SessionName:  job.name,
-				SessionQuery: job.query,
-				GpaValue:     gpaInfo["gpa"],
-				CgpaValue:    gpaInfo["cgpa"],
-				CreditHours:  gpaInfo["chr"],
-				Status:       gpaInfo["status"],
-				Result:       subjects,
-			}
+// @Title ResultV2Handler
+// @Description Get result from i-Ma'luum with GPA, CGPA and credits as numbers and grades as an enum.
+// @Description Each session carries a GPA recomputed from its courses, compared with the one i-Ma'luum printed.
+// @Tags v2
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param source query string false "cache serves the latest stored snapshot instead of i-Ma'luum, see /api/snapshots" Enums(cache)
+// @Param fresh query bool false "Skip the response cache and refetch from i-Ma'luum"
+// @Param If-None-Match header string false "ETag of an earlier response, answered with 304 Not Modified while unchanged"
+// @Success 200 {object} dtos.ResponseDTO{data=[]dtos.ResultResponseV2}
+// @Header 200 {string} ETag "Fingerprint of the data"
+// @Header 200 {string} Last-Modified "When the data first appeared, once snapshots are enabled"
+// @Router /api/v2/result [get]
+func (s *Server) ResultV2Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
 
-			results <- resultWorkerResult{
-				result: response,
-				err:    nil,
-			}
-		}()
+	if r.URL.Query().Get("source") == sourceCache {
+		s.serveSnapshot(w, r, snapshotResult, "Successfully fetched results from cache", s.resultSnapshotV2)
+		return
 	}
-}
-
-// Process results using worker pool pattern
-func (s *Server) processResultsWithWorkerPool(queries, names []string, cookie string) ([]dtos.ResultResponse, error) {
-	const maxWorkers = 5
 
-	jobs := make(chan resultJob, len(queries))
-	results := make(chan resultWorkerResult, len(queries))
+	var (
+		logger      = s.log.GetLogger()
+		cookie      = r.Context().Value(ctxToken).(string)
+		diagnostics = imaluum.NewDiagnostics()
+	)
 
-	// Start workers
-	for range maxWorkers {
-		go s.resultWorker(jobs, results, cookie)
+	results, err := s.imaluum.Results(imaluum.WithDiagnostics(r.Context(), diagnostics), cookie)
+	if err != nil {
+		logger.Sugar().Errorf("Failed to get results: %v", err)
+		errors.Render(w, r, err)
+		return
 	}
 
-	// Send jobs
-	go func() {
-		defer close(jobs)
-		for i := range queries {
-			jobs <- resultJob{
-				query: queries[i],
-				name:  names[i],
-			}
-		}
-	}()
-
-	// Collect results
-	var resultResponses []dtos.ResultResponse
-	var errorList []error
+	s.tagResponse(w, r, snapshotResult, func() (string, string, error) {
+		return snapshot(results)
+	})
 
-	for range queries {
-		result := <-results
-		if result.err != nil {
-			errorList = append(errorList, result.err)
-		} else {
-			resultResponses = append(resultResponses, result.result)
+	resultsV2 := s.gradePoints.CheckAll(imaluum.ResultsV2(results))
+	for _, result := range resultsV2 {
+		if result.GpaCheck.Status == dtos.GpaCheckMismatch {
+			logger.Sugar().Warnf("Computed GPA %.2f differs from i-Ma'luum GPA %.2f (%s)", *result.GpaCheck.ComputedGpa, *result.GpaCheck.ScrapedGpa, result.SessionName)
 		}
 	}
 
-	if len(errorList) > 0 {
-		return nil, errorList[0] // Return first error
+	response := &dtos.ResponseDTO{
+		Message:  "Successfully fetched results",
+		Data:     resultsV2,
+		Warnings: parseWarnings(w, diagnostics),
 	}
 
-	return resultResponses, nil
+	s.respond(w, r, response)
 }
 
-// @Title ResultHandler
-// @Description Get result from i-Ma'luum
-// @Tags scraper
+// @Title ResultSummaryHandler
+// @Description Aggregates the results of every session into credit hours earned, passed, failed and repeated courses, grade distribution and CGPA trend
+// @Tags result
 // @Produce json
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
-// @Success 200 {object} dtos.ResponseDTO
-// @Router /api/result [get]
-func (s *Server) ResultHandler(w http.ResponseWriter, r *http.Request) {
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.TranscriptSummary}
+// @Router /api/result/summary [get]
+func (s *Server) ResultSummaryHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var (
-		logger         = s.log.GetLogger()
-		cookie         = r.Context().Value(ctxToken).(string)
-		sessionQueries []string
-		sessionNames   []string
+		logger      = s.log.GetLogger()
+		cookie      = r.Context().Value(ctxToken).(string)
+		diagnostics = imaluum.NewDiagnostics()
 	)
 
-	// Pre-build cookie string once
-	cookieStr := "MOD_AUTH_CAS=" + cookie
-
-	c := colly.NewCollector()
-	c.WithTransport(s.httpClient.Transport)
-
-	c.OnRequest(func(r *colly.Request) {
-		r.Headers.Set("Cookie", cookieStr)
-		r.Headers.Set("User-Agent", cuid.New())
-	})
-
-	c.OnHTML(".box.box-primary .box-header.with-border .dropdown ul.dropdown-menu", func(e *colly.HTMLElement) {
-		sessionQueries = e.ChildAttrs("li[style*='font-size:16px'] a", "href")
-		sessionNames = e.ChildTexts("li[style*='font-size:16px'] a")
-	})
-
-	if err := c.Visit(constants.ImaluumResultPage); err != nil {
-		logger.Sugar().Errorf("Failed to go to URL: %v", err)
-		errors.Render(w, r, errors.ErrFailedToGoToURL)
-		return
-	}
-
-	// Filter out unwanted sessions with pre-allocated slices
-	filteredQueries := make([]string, 0, len(sessionQueries))
-	filteredNames := make([]string, 0, len(sessionNames))
-
-	for i := range sessionQueries {
-		if !slices.Contains(UnwantedSessionQueries[:], sessionQueries[i]) {
-			filteredQueries = append(filteredQueries, sessionQueries[i])
-			filteredNames = append(filteredNames, sessionNames[i])
-		}
-	}
-
-	if len(filteredQueries) == 0 {
-		logger.Sugar().Error("No valid sessions found")
-		errors.Render(w, r, errors.ErrResultIsEmpty)
-		return
-	}
-
-	// Use worker pool for concurrent processing
-	results, err := s.processResultsWithWorkerPool(filteredQueries, filteredNames, cookie)
+	results, err := s.imaluum.Results(imaluum.WithDiagnostics(r.Context(), diagnostics), cookie)
 	if err != nil {
-		logger.Sugar().Errorf("Failed to process results: %v", err)
+		logger.Sugar().Errorf("Failed to get results: %v", err)
 		errors.Render(w, r, err)
 		return
 	}
 
-	if len(results) == 0 {
-		logger.Sugar().Error("Result is empty")
-		errors.Render(w, r, errors.ErrResultIsEmpty)
-		return
-	}
-
-	// Sort results
-	sort.Slice(results, func(i, j int) bool {
-		return utils.SortSessionNames(results[i].SessionName, results[j].SessionName)
-	})
-
 	response := &dtos.ResponseDTO{
-		Message: "Successfully fetched results",
-		Data:    results,
+		Message:  "Successfully summarised results",
+		Data:     s.gradePoints.Summary(imaluum.ResultsV2(results)),
+		Warnings: parseWarnings(w, diagnostics),
 	}
 
-	if err := sonic.ConfigFastest.NewEncoder(w).Encode(response); err != nil {
-		logger.Sugar().Errorf("Failed to encode response: %v", err)
-		errors.Render(w, r, errors.ErrFailedToEncodeResponse)
-	}
+	s.respond(w, r, response)
 }
@@ -12,13 +12,19 @@ import (
 var DocsPath embed.FS
 
 func (s *Server) RegisterRoutes() http.Handler {
-	r := chi.NewRouter()
+	var (
+		r      = chi.NewRouter()
+		v2     = s.v2Routes()
+		sunset = apiV1Sunset()
+	)
+	r.Use(middleware.RequestID, exposeRequestID)
 	r.Use(middleware.Logger)
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST"},
+		AllowedMethods:   []string{"GET", "POST", "DELETE"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		ExposedHeaders:   []string{requestIDHeader, apiVersionHeader, "Deprecation", "Sunset", "Link"},
 		AllowCredentials: true,
 		// MaxAge:           300,
 	}))
@@ -26,9 +32,15 @@ func (s *Server) RegisterRoutes() http.Handler {
 	// Recoverer middleware recovers from panics, logs the panic (and a backtrace), and returns a HTTP 500 (Internal Server Error) status if possible.
 	r.Use(middleware.Recoverer)
 
+	// Compress responses with zstd, brotli, gzip or deflate as the client accepts
+	r.Use(compress())
+
 	// RedirectSlashes middleware is a simple middleware that will match request paths with a trailing slash, strip it, and redirect.
 	r.Use(middleware.RedirectSlashes)
 
+	// Serve /api/... from /api/v2/... for clients that ask for v2 in Accept
+	r.Use(negotiateVersion(v2))
+
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/api/reference", http.StatusMovedPermanently)
 	})
@@ -57,17 +69,56 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 		r.Get("/ads", s.AdsHandler)
 
+		r.Get("/push/public-key", s.PushPublicKeyHandler)
+
+		r.Mount("/v2", v2)
+
+		// Operator endpoints, only served while ADMIN_TOKEN is set
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(s.AdminAuthenticator())
+
+			r.Get("/jobs", s.ListJobsHandler)
+			r.Post("/jobs/{name}/pause", s.PauseJobHandler)
+			r.Post("/jobs/{name}/resume", s.ResumeJobHandler)
+			r.Post("/jobs/{name}/run", s.RunJobHandler)
+		})
+
 		// All routes in this group require authentication
 		r.Group(func(r chi.Router) {
 			// Check for PASETO token in Authorization header
 			r.Use(s.PasetoAuthenticator())
 
-			r.Get("/profile", s.ProfileHandler)
-			r.Get("/schedule", s.ScheduleHandler)
-			r.Get("/result", s.ResultHandler)
-			r.Get("/starpoint", s.StarpointHandler)
+			r.With(deprecated("/api/v2/profile", sunset), conditional, s.cached("profile")).Get("/profile", s.ProfileHandler)
+			r.With(deprecated("/api/v2/schedule", sunset), conditional, s.cached("schedule")).Get("/schedule", s.ScheduleHandler)
+			r.With(deprecated("/api/v2/result", sunset), conditional, s.cached("result")).Get("/result", s.ResultHandler)
+			r.Get("/me", s.MeHandler)
+			r.Get("/sync/{kind}", s.SyncStreamHandler)
+			r.Post("/graphql", s.GraphQLHandler())
+			r.Get("/result/summary", s.ResultSummaryHandler)
+			r.Post("/result/simulate", s.SimulateHandler)
+			r.With(deprecated("/api/v2/starpoint", sunset), conditional, s.cached("starpoint")).Get("/starpoint", s.StarpointHandler)
 			r.Get("/logout", s.LogoutHandler)
 
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Get("/", s.GetWebhookHandler)
+				r.Post("/", s.RegisterWebhookHandler)
+				r.Delete("/", s.DeleteWebhookHandler)
+				r.Get("/dead-letters", s.WebhookDeadLettersHandler)
+			})
+
+			r.Route("/push", func(r chi.Router) {
+				r.Post("/subscriptions", s.SubscribePushHandler)
+				r.Delete("/subscriptions", s.UnsubscribePushHandler)
+				r.Post("/test", s.TestPushHandler)
+			})
+
+			r.Route("/snapshots", func(r chi.Router) {
+				r.Get("/", s.SnapshotHistoryHandler)
+				r.Post("/", s.EnableSnapshotsHandler)
+				r.Delete("/", s.DisableSnapshotsHandler)
+				r.Get("/{id}", s.GetSnapshotHandler)
+			})
+
 			r.Route("/download", func(r chi.Router) {
 				r.Get("/exam-slip", s.ExamSlipHandler)
 				r.Get("/study-plan", s.StudyPlanHandler)
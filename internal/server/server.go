@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
@@ -10,10 +11,18 @@ import (
 	"os"
 	"time"
 
+	"github.com/nrmnqdds/gomaluum/internal/constants"
 	auth_proto "github.com/nrmnqdds/gomaluum/internal/proto"
+	"github.com/nrmnqdds/gomaluum/pkg/cache"
+	"github.com/nrmnqdds/gomaluum/pkg/gpa"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
 	"github.com/nrmnqdds/gomaluum/pkg/logger"
 	"github.com/nrmnqdds/gomaluum/pkg/paseto"
+	"github.com/nrmnqdds/gomaluum/pkg/push"
+	"github.com/nrmnqdds/gomaluum/pkg/scheduler"
 	"github.com/nrmnqdds/gomaluum/pkg/sf"
+	"github.com/nrmnqdds/gomaluum/pkg/upstream"
+	"github.com/nrmnqdds/gomaluum/pkg/webhook"
 
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
 )
@@ -29,6 +38,7 @@ type Handlers interface {
 type GRPCServer struct {
 	auth_proto.UnimplementedAuthServer
 	httpClient *http.Client
+	urls       constants.Upstream
 }
 
 func NewGRPCServer() *GRPCServer {
@@ -41,17 +51,29 @@ func NewGRPCServer() *GRPCServer {
 
 	return &GRPCServer{
 		httpClient: httpClient,
+		urls:       constants.LoadUpstream(),
 	}
 }
 
 type Server struct {
-	log          *logger.AppLogger
-	paseto       *paseto.AppPaseto
-	grpc         *GRPCServer
-	httpClient   *http.Client
-	port         int
-	tokenManager *sf.TokenManager
-	db           *sql.DB
+	log           *logger.AppLogger
+	paseto        *paseto.AppPaseto
+	grpc          *GRPCServer
+	httpClient    *http.Client
+	upstream      *upstream.Transport
+	imaluum       *imaluum.Client
+	gradePoints   gpa.Table
+	webhooks      *webhook.Sender
+	pusher        *push.Sender
+	scheduler     *scheduler.Scheduler
+	adminToken    string
+	snapshotLimit int
+	cache         *cache.Cache
+	cachePolicies map[string]cache.Policy
+	port          int
+	tokenManager  *sf.TokenManager
+	pollTokens    *sf.TokenManager
+	db            *sql.DB
 }
 
 func NewServer(port int, grpc *GRPCServer) *http.Server {
@@ -61,12 +83,9 @@ func NewServer(port int, grpc *GRPCServer) *http.Server {
 		return nil
 	}
 
-	// Create the HTTP client with proper certificate handling
-	httpClient, err := createHTTPClient()
-	if err != nil {
-		log.Fatalf("Failed to create HTTP client: %v", err)
-		return nil
-	}
+	// Share the gRPC server's client so login and scraping draw from the
+	// same rate limiter and trip the same circuit breaker
+	httpClient := grpc.httpClient
 
 	db, err := sql.Open("libsql", os.Getenv("DB_PATH"))
 	if err != nil {
@@ -74,41 +93,40 @@ func NewServer(port int, grpc *GRPCServer) *http.Server {
 		return nil
 	}
 
-	schema := []string{
-		`CREATE TABLE IF NOT EXISTS analytics (
-			matric_no TEXT NOT NULL PRIMARY KEY,
-			batch AS (substr(matric_no, 1, 2) + 2000) STORED,
-			level AS (
-				CASE length(matric_no)
-					WHEN 7 THEN 'DEGREE'
-					WHEN 6 THEN 'CFS'
-				END
-			) STORED,
-			timestamp DATETIME DEFAULT current_timestamp
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_batch ON analytics(batch)`,
-		`CREATE INDEX IF NOT EXISTS idx_level ON analytics(level)`,
-		`CREATE INDEX IF NOT EXISTS idx_batch_level ON analytics(batch, level)`,
-	}
-
-	for _, stmt := range schema {
-		if _, err := db.Exec(stmt); err != nil {
-			return nil
-		}
+	if err := migrate(db); err != nil {
+		log.Printf("Failed to migrate database: %v", err)
+		return nil
 	}
 
 	tm := sf.NewTokenManager()
+	appLogger := logger.New()
 
 	NewServer := &Server{
-		port:         port,
-		log:          logger.New(),
-		paseto:       paseto,
-		grpc:         grpc,
-		httpClient:   httpClient,
-		tokenManager: tm,
-		db:           db,
+		port:          port,
+		log:           appLogger,
+		paseto:        paseto,
+		grpc:          grpc,
+		httpClient:    httpClient,
+		upstream:      httpClient.Transport.(*upstream.Transport),
+		imaluum:       imaluum.New(httpClient, grpc.urls, appLogger),
+		gradePoints:   gpa.TableFromEnv(),
+		webhooks:      webhook.NewSender(),
+		pusher:        push.NewSender(),
+		scheduler:     scheduler.New(db),
+		adminToken:    os.Getenv("ADMIN_TOKEN"),
+		snapshotLimit: snapshotHistoryLimit(),
+		cache:         cache.New(),
+		cachePolicies: loadCachePolicies(),
+		tokenManager:  tm,
+		pollTokens:    sf.NewTokenManager(),
+		db:            db,
 	}
 
+	// Sync users with a webhook or push subscription in the background
+	NewServer.registerJobs()
+	jobs, stopJobs := context.WithCancel(context.Background())
+	go NewServer.scheduler.Run(jobs)
+
 	// Declare Server config
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", NewServer.port),
@@ -117,6 +135,8 @@ func NewServer(port int, grpc *GRPCServer) *http.Server {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
+	// Hand the scheduler lease to another replica on shutdown
+	server.RegisterOnShutdown(stopJobs)
 
 	return server
 }
@@ -144,9 +164,16 @@ func createHTTPClient() (*http.Client, error) {
 		},
 	}
 
-	// Return a client with the custom transport
+	// Record or replay upstream traffic when asked to, see upstream.WithRecording
+	base, err := upstream.WithRecording(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return a client with the custom transport, guarded by the upstream
+	// rate limiter and circuit breaker
 	return &http.Client{
-		Transport: transport,
+		Transport: upstream.New(base),
 		Timeout:   30 * time.Second,
 	}, nil
 }
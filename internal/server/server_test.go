@@ -0,0 +1,432 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/cache"
+	"github.com/nrmnqdds/gomaluum/pkg/gpa"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/logger"
+	apppaseto "github.com/nrmnqdds/gomaluum/pkg/paseto"
+	"github.com/nrmnqdds/gomaluum/pkg/scheduler"
+	"github.com/nrmnqdds/gomaluum/pkg/sf"
+	"github.com/nrmnqdds/gomaluum/pkg/upstream"
+	"github.com/nrmnqdds/gomaluum/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+const testAdminToken = "test-admin-token"
+
+// newTestServer serves the API routes backed by a fake i-Ma'luum
+func newTestServer(t *testing.T) (*httptest.Server, *fakeimaluum.Server) {
+	t.Helper()
+
+	s, fake := newTestBackend(t)
+
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	return api, fake
+}
+
+// newTestBackend builds a server backed by a fake i-Ma'luum and a local
+// sqlite database, for tests that call into the server directly
+func newTestBackend(t *testing.T) (*Server, *fakeimaluum.Server) {
+	t.Helper()
+
+	t.Setenv("ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+
+	fake := fakeimaluum.New()
+	t.Cleanup(fake.Close)
+
+	httpClient, err := createHTTPClient()
+	require.NoError(t, err)
+
+	// libsql opens file: URLs through the registered sqlite driver. Analytics
+	// are written in the background, so wait for locks instead of failing.
+	db, err := sql.Open("libsql", "file:"+t.TempDir()+"/test.db?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	require.NoError(t, migrate(db))
+
+	secretKey := paseto.NewV4AsymmetricSecretKey()
+	publicKey := secretKey.Public()
+	token := paseto.NewToken()
+	appLogger := logger.New()
+
+	s := &Server{
+		log: appLogger,
+		paseto: &apppaseto.AppPaseto{
+			PublicKey:  &publicKey,
+			PrivateKey: &secretKey,
+			Token:      &token,
+		},
+		grpc: &GRPCServer{
+			httpClient: httpClient,
+			urls:       fake.Upstream(),
+		},
+		httpClient:   httpClient,
+		upstream:     httpClient.Transport.(*upstream.Transport),
+		imaluum:      imaluum.New(httpClient, fake.Upstream(), appLogger),
+		gradePoints:  gpa.DefaultTable,
+		tokenManager: sf.NewTokenManager(),
+		pollTokens:   sf.NewTokenManager(),
+		db:           db,
+		webhooks: &webhook.Sender{
+			Client:      http.DefaultClient,
+			MaxAttempts: 2,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    time.Millisecond,
+			AllowHTTP:   true,
+		},
+		scheduler:     scheduler.New(db),
+		adminToken:    testAdminToken,
+		cache:         cache.New(),
+		cachePolicies: loadCachePolicies(),
+	}
+	s.scheduler.Jitter = 0
+	s.registerJobs()
+	// Tests run jobs back to back, faster than the limiter refills
+	s.scheduler.Ready = nil
+
+	return s, fake
+}
+
+// runJobs runs every background job now, as if all of them were due
+func runJobs(t *testing.T, s *Server) {
+	t.Helper()
+
+	_, err := s.db.Exec(`UPDATE jobs SET next_run_at = 0`)
+	require.NoError(t, err)
+	for {
+		started, err := s.scheduler.RunPending(context.Background())
+		require.NoError(t, err)
+		if started == 0 {
+			return
+		}
+	}
+}
+
+type testResponse struct {
+	Data      json.RawMessage `json:"data"`
+	Details   json.RawMessage `json:"details"`
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	RequestID string          `json:"request_id"`
+	Status    int             `json:"status"`
+}
+
+func do(t *testing.T, method, url, token, body string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return doRequest(t, req)
+}
+
+func doRequest(t *testing.T, req *http.Request) (*http.Response, []byte) {
+	t.Helper()
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, content
+}
+
+func login(t *testing.T, api *httptest.Server) string {
+	t.Helper()
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/auth/login", "",
+		`{"username":"`+fakeimaluum.Username+`","password":"`+fakeimaluum.Password+`"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	assert.Equal(t, errors.CodeOK, response.Code)
+	// Successes share the envelope of errors
+	assert.NotEmpty(t, response.RequestID)
+	assert.Equal(t, resp.Header.Get("X-Request-Id"), response.RequestID)
+
+	var data struct {
+		Token    string `json:"token"`
+		Username string `json:"username"`
+	}
+	require.NoError(t, json.Unmarshal(response.Data, &data))
+	require.NotEmpty(t, data.Token)
+	assert.Equal(t, fakeimaluum.Username, data.Username)
+
+	return data.Token
+}
+
+func TestLogin(t *testing.T) {
+	api, _ := newTestServer(t)
+
+	login(t, api)
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/auth/login", "",
+		`{"username":"`+fakeimaluum.Username+`","password":"wrong"}`)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	assert.Equal(t, errors.CodeInvalidCredentials, response.Code)
+	assert.Equal(t, http.StatusUnauthorized, response.Status)
+	assert.NotEmpty(t, response.RequestID)
+	assert.Equal(t, resp.Header.Get("X-Request-Id"), response.RequestID)
+}
+
+func TestAuthenticatedRoutes(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	tests := []struct {
+		check   func(t *testing.T, data json.RawMessage)
+		path    string
+		message string
+	}{
+		{
+			path:    "/api/profile",
+			message: "Successfully fetched profile",
+			check: func(t *testing.T, data json.RawMessage) {
+				var profile struct {
+					Name     string `json:"name"`
+					MatricNo string `json:"matric_no"`
+				}
+				require.NoError(t, json.Unmarshal(data, &profile))
+				assert.Equal(t, "AHMAD FULAN BIN ABDULLAH", profile.Name)
+				assert.Equal(t, fakeimaluum.Username, profile.MatricNo)
+			},
+		},
+		{
+			path:    "/api/schedule",
+			message: "Successfully fetched schedule",
+			check: func(t *testing.T, data json.RawMessage) {
+				var schedules []struct {
+					SessionName string            `json:"session_name"`
+					Schedule    []json.RawMessage `json:"schedule"`
+				}
+				require.NoError(t, json.Unmarshal(data, &schedules))
+				require.Len(t, schedules, 2)
+				assert.Equal(t, "Sem 1, 2024/2025", schedules[0].SessionName)
+				assert.Len(t, schedules[0].Schedule, 4)
+			},
+		},
+		{
+			path:    "/api/result",
+			message: "Successfully fetched results",
+			check: func(t *testing.T, data json.RawMessage) {
+				var results []struct {
+					SessionName string `json:"session_name"`
+					GpaValue    string `json:"gpa_value"`
+				}
+				require.NoError(t, json.Unmarshal(data, &results))
+				require.Len(t, results, 2)
+				assert.Equal(t, "3.67", results[0].GpaValue)
+			},
+		},
+		{
+			path:    "/api/v2/result",
+			message: "Successfully fetched results",
+			check: func(t *testing.T, data json.RawMessage) {
+				var results []struct {
+					Gpa      *float64      `json:"gpa"`
+					GpaCheck dtos.GpaCheck `json:"gpa_check"`
+					Result   []struct {
+						Grade  string   `json:"grade"`
+						Credit *float64 `json:"credit"`
+					} `json:"result"`
+				}
+				require.NoError(t, json.Unmarshal(data, &results))
+				require.Len(t, results, 2)
+				require.NotNil(t, results[0].Gpa)
+				assert.InDelta(t, 3.67, *results[0].Gpa, 0.001)
+				require.Len(t, results[0].Result, 3)
+				assert.Equal(t, "B+", results[0].Result[1].Grade)
+				require.NotNil(t, results[0].Result[1].Credit)
+				assert.InDelta(t, 3.0, *results[0].Result[1].Credit, 0.001)
+				assert.Equal(t, dtos.GpaCheckMatch, results[0].GpaCheck.Status)
+			},
+		},
+		{
+			path:    "/api/starpoint",
+			message: "Successfully fetched starpoints programs",
+			check: func(t *testing.T, data json.RawMessage) {
+				var starpoint struct {
+					Programs []json.RawMessage `json:"programs"`
+				}
+				require.NoError(t, json.Unmarshal(data, &starpoint))
+				assert.Len(t, starpoint.Programs, 2)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := do(t, http.MethodGet, api.URL+tt.path, token, "")
+			require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+			var response testResponse
+			require.NoError(t, json.Unmarshal(body, &response))
+			assert.Equal(t, tt.message, response.Message)
+			tt.check(t, response.Data)
+		})
+	}
+}
+
+func TestDownloadRoutes(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	for path, fixture := range map[string]string{
+		"/api/download/exam-slip":  "exam-slip.pdf",
+		"/api/download/study-plan": "study-plan.pdf",
+	} {
+		t.Run(path, func(t *testing.T) {
+			resp, body := do(t, http.MethodGet, api.URL+path, token, "")
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+			assert.Equal(t, fakeimaluum.Fixture(fixture), body)
+		})
+	}
+}
+
+func TestAdsRoute(t *testing.T) {
+	api, _ := newTestServer(t)
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/ads", "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+
+	var ads []struct {
+		Title string `json:"title"`
+		Link  string `json:"link"`
+	}
+	require.NoError(t, json.Unmarshal(response.Data, &ads))
+	require.Len(t, ads, 2)
+	assert.Equal(t, "Used Calculus textbook", ads[0].Title)
+	assert.Equal(t, "https://souq.iium.edu.my/item/2", ads[1].Link)
+}
+
+func TestResultSummaryRoute(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/result/summary", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+
+	var summary dtos.TranscriptSummary
+	require.NoError(t, json.Unmarshal(response.Data, &summary))
+	// The fake serves the same three courses for both sessions
+	assert.Equal(t, dtos.CourseCounts{Total: 3, Passed: 3, Repeated: 3}, summary.Courses)
+	assert.InDelta(t, 8, summary.CreditHoursEarned, 0.001)
+	require.Len(t, summary.Trend, 2)
+	assert.Equal(t, "Sem 1, 2023/2024", summary.Trend[0].SessionName)
+	assert.Equal(t, 2, summary.GradeDistribution[dtos.GradeBPlus])
+}
+
+func TestSimulateRoute(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	t.Run("scraped history", func(t *testing.T) {
+		resp, body := do(t, http.MethodPost, api.URL+"/api/result/simulate", token,
+			`{"courses":[{"course_code":"CSCI 4401","grade":"A","credit":4}],"target_cgpa":3.6}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+		var response testResponse
+		require.NoError(t, json.Unmarshal(body, &response))
+
+		var simulation dtos.SimulateResponse
+		require.NoError(t, json.Unmarshal(response.Data, &simulation))
+		// Both fixture sessions list the same 8 credit hours of courses and
+		// print a 3.52 CGPA, the repeats count once
+		assert.InDelta(t, 3.52, *simulation.Current.Cgpa, 0.001)
+		assert.InDelta(t, 8, simulation.Current.CreditHours, 0.001)
+		assert.InDelta(t, 3.68, *simulation.Projected.Cgpa, 0.001)
+		require.NotNil(t, simulation.Target)
+		assert.InDelta(t, 3.76, simulation.Target.RequiredAverage, 0.001)
+	})
+
+	t.Run("given history", func(t *testing.T) {
+		resp, body := do(t, http.MethodPost, api.URL+"/api/result/simulate", token,
+			`{"results":[{"cgpa_value":"3.00","credit_hours":"10"}],"courses":[{"grade":"B","credit":10}]}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+		var response testResponse
+		require.NoError(t, json.Unmarshal(body, &response))
+
+		var simulation dtos.SimulateResponse
+		require.NoError(t, json.Unmarshal(response.Data, &simulation))
+		assert.InDelta(t, 3.00, *simulation.Projected.Cgpa, 0.001)
+	})
+
+	for name, body := range map[string]string{
+		"unknown grade":  `{"courses":[{"grade":"Z","credit":3}]}`,
+		"no courses":     `{"target_cgpa":3.5}`,
+		"invalid credit": `{"courses":[{"grade":"A","credit":0}]}`,
+		"invalid body":   `{"courses":`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, _ := do(t, http.MethodPost, api.URL+"/api/result/simulate", token, body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/auth/logout", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), "Logout successful")
+}
+
+func TestUnauthenticatedRoutes(t *testing.T) {
+	api, _ := newTestServer(t)
+
+	for _, path := range []string{"/api/profile", "/api/schedule", "/api/result", "/api/starpoint", "/api/download/exam-slip"} {
+		t.Run(path, func(t *testing.T) {
+			resp, body := do(t, http.MethodGet, api.URL+path, "", "")
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			assert.Contains(t, string(body), errors.CodeUnauthenticated)
+
+			req, err := http.NewRequest(http.MethodGet, api.URL+path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer not-a-paseto-token")
+			resp, body = doRequest(t, req)
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			assert.Contains(t, string(body), errors.CodeInvalidToken)
+		})
+	}
+}
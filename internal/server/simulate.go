@@ -0,0 +1,84 @@
+package server
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bytedance/sonic"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/gpa"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+)
+
+// @Title SimulateHandler
+// @Description Projects GPA and CGPA for hypothetical grades, and the average needed to reach a target CGPA. Results are scraped from i-Ma'luum unless given in the body.
+// @Tags result
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param body body dtos.SimulateRequest true "Hypothetical courses and target"
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.SimulateResponse}
+// @Router /api/result/simulate [post]
+func (s *Server) SimulateHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		cookie = r.Context().Value(ctxToken).(string)
+		req    dtos.SimulateRequest
+	)
+
+	if err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Sugar().Errorf("Failed to decode request body: %v", err)
+		errors.Render(w, r, errors.ErrInvalidRequest)
+		return
+	}
+
+	if len(req.Courses) == 0 && req.RemainingCredits == nil {
+		errors.Render(w, r, errors.ErrNothingToSimulate)
+		return
+	}
+
+	for i, course := range req.Courses {
+		if course.Credit <= 0 {
+			errors.Render(w, r, errors.WithDetails(errors.ErrInvalidRequest, map[string]any{
+				"field":  fmt.Sprintf("courses[%d].credit", i),
+				"reason": "must be positive",
+			}))
+			return
+		}
+	}
+
+	results := req.Results
+	if len(results) == 0 {
+		scraped, err := s.imaluum.Results(r.Context(), cookie)
+		if err != nil {
+			logger.Sugar().Errorf("Failed to get results: %v", err)
+			errors.Render(w, r, err)
+			return
+		}
+		results = scraped
+	}
+
+	simulation, err := s.gradePoints.Simulate(imaluum.ResultsV2(results), req)
+	if err != nil {
+		// The only failure is a grade missing from the table
+		logger.Sugar().Errorf("Failed to simulate: %v", err)
+		customErr := errors.Wrap(errors.ErrInvalidGrade, err)
+		var unknown *gpa.ErrUnknownGrade
+		if stderrors.As(err, &unknown) {
+			customErr.Details = map[string]any{"grade": unknown.Grade}
+		}
+		errors.Render(w, r, customErr)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Successfully simulated results",
+		Data:    simulation,
+	}
+
+	s.respond(w, r, response)
+}
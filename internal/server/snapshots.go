@@ -0,0 +1,373 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stderrors "errors"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+)
+
+const (
+	// sourceCache in ?source= serves the latest snapshot instead of i-Ma'luum
+	sourceCache = "cache"
+
+	defaultSnapshotHistoryLimit = 30
+)
+
+var snapshotKinds = []string{snapshotProfile, snapshotSchedule, snapshotResult, snapshotStarpoint}
+
+// snapshotHistoryLimit reads SNAPSHOT_HISTORY_LIMIT, how many distinct
+// snapshots of each kind are kept per user, 0 keeps all of them
+func snapshotHistoryLimit() int {
+	if raw := os.Getenv("SNAPSHOT_HISTORY_LIMIT"); raw != "" {
+		if limit, err := strconv.Atoi(raw); err == nil && limit >= 0 {
+			return limit
+		}
+		log.Printf("Invalid SNAPSHOT_HISTORY_LIMIT=%q, using default %d", raw, defaultSnapshotHistoryLimit)
+	}
+	return defaultSnapshotHistoryLimit
+}
+
+// recordSnapshot adds a scrape to the history of a user who opted in. A scrape
+// identical to the latest one only moves its fetched_at forward. It returns
+// when the content was first stored, or zero for users who did not opt in,
+// who cost a single query.
+func (s *Server) recordSnapshot(ctx context.Context, matricNo, kind, payload, hash string) (time.Time, error) {
+	var (
+		now        = time.Now().Unix()
+		latestID   sql.NullInt64
+		latestHash sql.NullString
+		createdAt  sql.NullInt64
+	)
+
+	err := s.db.QueryRowContext(ctx, `
+			SELECT h.id, h.hash, h.created_at FROM snapshot_users u
+			LEFT JOIN snapshot_history h ON h.id = (
+				SELECT MAX(id) FROM snapshot_history WHERE matric_no = u.matric_no AND kind = ?
+			)
+			WHERE u.matric_no = ?
+		`, kind, matricNo).Scan(&latestID, &latestHash, &createdAt)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if latestHash.Valid && latestHash.String == hash {
+		if _, err := s.db.ExecContext(ctx, `
+				UPDATE snapshot_history SET fetched_at = ? WHERE id = ?
+			`, now, latestID.Int64); err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(createdAt.Int64, 0), nil
+	}
+
+	// Skip users who opted out since, their history is gone for good
+	result, err := s.db.ExecContext(ctx, `
+			INSERT INTO snapshot_history (matric_no, kind, payload, hash, created_at, fetched_at)
+			SELECT ?, ?, ?, ?, ?, ?
+			WHERE EXISTS (SELECT 1 FROM snapshot_users WHERE matric_no = ?)
+		`, matricNo, kind, payload, hash, now, now, matricNo)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return time.Time{}, err
+	}
+
+	if s.snapshotLimit > 0 {
+		if _, err := s.db.ExecContext(ctx, `
+				DELETE FROM snapshot_history
+				WHERE matric_no = ? AND kind = ? AND id NOT IN (
+					SELECT id FROM snapshot_history
+					WHERE matric_no = ? AND kind = ?
+					ORDER BY id DESC
+					LIMIT ?
+				)
+			`, matricNo, kind, matricNo, kind, s.snapshotLimit); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return time.Unix(now, 0), nil
+}
+
+// tagResponse sets the ETag of a scraped response and records it for users
+// who opted in, whose responses also get a Last-Modified of when the content
+// first appeared. Failures only cost the tags, the response is sent anyway.
+func (s *Server) tagResponse(w http.ResponseWriter, r *http.Request, kind string, encode func() (string, string, error)) {
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+	)
+
+	payload, hash, err := encode()
+	if err != nil {
+		logger.Sugar().Warnf("Failed to fingerprint %s: %v", kind, err)
+		return
+	}
+	w.Header().Set("ETag", etag(r, hash))
+
+	modified, err := s.recordSnapshot(r.Context(), user.username, kind, payload, hash)
+	if err != nil {
+		logger.Sugar().Warnf("Failed to keep %s snapshot of %s: %v", kind, user.username, err)
+		return
+	}
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// etag quotes a snapshot fingerprint, told apart per API version and
+// response format
+func etag(r *http.Request, hash string) string {
+	if version := requestAPIVersion(r); version != 1 {
+		hash += ".v" + strconv.Itoa(version)
+	}
+	if format := negotiate(r); format != formatJSON {
+		hash += "." + format.name
+	}
+	return `"` + hash + `"`
+}
+
+// serveSnapshot responds with the latest snapshot of kind, for reading while
+// i-Ma'luum is down. Last-Modified tells when its content first appeared.
+// Snapshots are stored as v1 responses, convert turns them into another
+// version and is nil for v1.
+func (s *Server) serveSnapshot(w http.ResponseWriter, r *http.Request, kind, message string, convert func(payload string) (any, error)) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger    = s.log.GetLogger()
+		user      = r.Context().Value(ctxUser).(*TokenPayload)
+		payload   string
+		hash      string
+		createdAt int64
+	)
+
+	err := s.db.QueryRowContext(r.Context(), `
+			SELECT payload, hash, created_at FROM snapshot_history
+			WHERE matric_no = ? AND kind = ?
+			ORDER BY id DESC
+			LIMIT 1
+		`, user.username, kind).Scan(&payload, &hash, &createdAt)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		errors.Render(w, r, errors.ErrSnapshotNotFound)
+		return
+	}
+	if err != nil {
+		logger.Sugar().Errorf("Failed to get %s snapshot: %v", kind, err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	var data any = json.RawMessage(payload)
+	if convert != nil {
+		if data, err = convert(payload); err != nil {
+			logger.Sugar().Errorf("Failed to convert %s snapshot: %v", kind, err)
+			errors.Render(w, r, errors.ErrInternal)
+			return
+		}
+	}
+
+	w.Header().Set("ETag", etag(r, hash))
+	w.Header().Set("Last-Modified", time.Unix(createdAt, 0).UTC().Format(http.TimeFormat))
+
+	response := &dtos.ResponseDTO{
+		Message: message,
+		Data:    data,
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title EnableSnapshotsHandler
+// @Description Opt in to storing every successful profile, schedule, result and starpoint scrape. Stored snapshots are served with ?source=cache while i-Ma'luum is down.
+// @Tags snapshot
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} dtos.ResponseDTO
+// @Router /api/snapshots [post]
+func (s *Server) EnableSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+	)
+
+	if _, err := s.db.ExecContext(r.Context(), `
+			INSERT INTO snapshot_users (matric_no, created_at) VALUES (?, ?)
+			ON CONFLICT(matric_no) DO NOTHING
+		`, user.username, time.Now().Unix()); err != nil {
+		logger.Sugar().Errorf("Failed to enable snapshots: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Snapshots enabled, every successful fetch from now on is stored",
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title DisableSnapshotsHandler
+// @Description Opt out of snapshots and delete every snapshot stored for the user
+// @Tags snapshot
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} dtos.ResponseDTO
+// @Router /api/snapshots [delete]
+func (s *Server) DisableSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+	)
+
+	for _, stmt := range []string{
+		`DELETE FROM snapshot_users WHERE matric_no = ?`,
+		`DELETE FROM snapshot_history WHERE matric_no = ?`,
+	} {
+		if _, err := s.db.ExecContext(r.Context(), stmt, user.username); err != nil {
+			logger.Sugar().Errorf("Failed to disable snapshots: %v", err)
+			errors.Render(w, r, errors.ErrFailedToQueryDB)
+			return
+		}
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Snapshots disabled and deleted",
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title SnapshotHistoryHandler
+// @Description List the stored snapshots of the user, newest first, without their payload
+// @Tags snapshot
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param kind query string false "Only snapshots of this kind" Enums(profile, schedule, result, starpoint)
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.SnapshotHistory}
+// @Router /api/snapshots [get]
+func (s *Server) SnapshotHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger  = s.log.GetLogger()
+		user    = r.Context().Value(ctxUser).(*TokenPayload)
+		kind    = r.URL.Query().Get("kind")
+		history = dtos.SnapshotHistory{Snapshots: []dtos.Snapshot{}}
+	)
+
+	if kind != "" && !slices.Contains(snapshotKinds, kind) {
+		errors.Render(w, r, errors.WithDetails(errors.ErrInvalidSnapshotKind, map[string]any{"kinds": snapshotKinds}))
+		return
+	}
+
+	err := s.db.QueryRowContext(r.Context(), `
+			SELECT EXISTS (SELECT 1 FROM snapshot_users WHERE matric_no = ?)
+		`, user.username).Scan(&history.Enabled)
+	if err != nil {
+		logger.Sugar().Errorf("Failed to get snapshot opt-in: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	rows, err := s.db.QueryContext(r.Context(), `
+			SELECT id, kind, hash, created_at, fetched_at
+			FROM snapshot_history
+			WHERE matric_no = ? AND (? = '' OR kind = ?)
+			ORDER BY id DESC
+		`, user.username, kind, kind)
+	if err != nil {
+		logger.Sugar().Errorf("Failed to get snapshots: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var snapshot dtos.Snapshot
+		if err := rows.Scan(&snapshot.ID, &snapshot.Kind, &snapshot.Hash, &snapshot.CreatedAt, &snapshot.FetchedAt); err != nil {
+			logger.Sugar().Errorf("Failed to scan snapshot: %v", err)
+			errors.Render(w, r, errors.ErrFailedToQueryDB)
+			return
+		}
+		history.Snapshots = append(history.Snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Sugar().Errorf("Failed to get snapshots: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Successfully fetched snapshots",
+		Data:    history,
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title GetSnapshotHandler
+// @Description Get a stored snapshot of the user with its payload
+// @Tags snapshot
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path int true "Snapshot ID from the history"
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.Snapshot}
+// @Router /api/snapshots/{id} [get]
+func (s *Server) GetSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger   = s.log.GetLogger()
+		user     = r.Context().Value(ctxUser).(*TokenPayload)
+		snapshot dtos.Snapshot
+		payload  string
+	)
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		errors.Render(w, r, errors.ErrSnapshotNotFound)
+		return
+	}
+
+	err = s.db.QueryRowContext(r.Context(), `
+			SELECT id, kind, payload, hash, created_at, fetched_at
+			FROM snapshot_history
+			WHERE id = ? AND matric_no = ?
+		`, id, user.username).Scan(&snapshot.ID, &snapshot.Kind, &payload, &snapshot.Hash, &snapshot.CreatedAt, &snapshot.FetchedAt)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		errors.Render(w, r, errors.ErrSnapshotNotFound)
+		return
+	}
+	if err != nil {
+		logger.Sugar().Errorf("Failed to get snapshot: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+	snapshot.Payload = json.RawMessage(payload)
+
+	response := &dtos.ResponseDTO{
+		Message: "Successfully fetched snapshot",
+		Data:    snapshot,
+	}
+
+	s.respond(w, r, response)
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotHistory(t *testing.T, api *httptest.Server, token, query string) dtos.SnapshotHistory {
+	t.Helper()
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/snapshots"+query, token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+
+	var history dtos.SnapshotHistory
+	require.NoError(t, json.Unmarshal(response.Data, &history))
+	return history
+}
+
+func TestSnapshots(t *testing.T) {
+	s, _ := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	token := login(t, api)
+
+	history := snapshotHistory(t, api, token, "")
+	assert.False(t, history.Enabled)
+	assert.Empty(t, history.Snapshots)
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/snapshots", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	resp, live := do(t, http.MethodGet, api.URL+"/api/profile", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(live))
+	resp, body = do(t, http.MethodGet, api.URL+"/api/result", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	assert.Equal(t, 2, countRows(t, s, `SELECT COUNT(*) FROM snapshot_history`))
+
+	history = snapshotHistory(t, api, token, "")
+	assert.True(t, history.Enabled)
+	require.Len(t, history.Snapshots, 2)
+	assert.Equal(t, snapshotResult, history.Snapshots[0].Kind)
+	assert.Empty(t, history.Snapshots[0].Payload)
+
+	profiles := snapshotHistory(t, api, token, "?kind="+snapshotProfile).Snapshots
+	require.Len(t, profiles, 1)
+
+	resp, body = do(t, http.MethodGet, api.URL+"/api/snapshots/"+strconv.FormatInt(profiles[0].ID, 10), token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), fakeimaluum.Username)
+
+	resp, _ = do(t, http.MethodGet, api.URL+"/api/snapshots?kind=exam", token, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = do(t, http.MethodGet, api.URL+"/api/snapshots/999", token, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, body = do(t, http.MethodDelete, api.URL+"/api/snapshots", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Zero(t, countRows(t, s, `SELECT COUNT(*) FROM snapshot_history`))
+	assert.Zero(t, countRows(t, s, `SELECT COUNT(*) FROM snapshot_users`))
+}
+
+func TestSnapshotCache(t *testing.T) {
+	s, fake := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	token := login(t, api)
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/snapshots", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	resp, live := do(t, http.MethodGet, api.URL+"/api/profile", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(live))
+	assert.Equal(t, 1, countRows(t, s, `SELECT COUNT(*) FROM snapshot_history`))
+
+	// With i-Ma'luum down the token cannot be refreshed, yet the cache is served
+	fake.Close()
+
+	resp, body = do(t, http.MethodGet, api.URL+"/api/profile?source=cache", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.NotEmpty(t, resp.Header.Get("Last-Modified"))
+
+	var cached, fresh testResponse
+	require.NoError(t, json.Unmarshal(body, &cached))
+	require.NoError(t, json.Unmarshal(live, &fresh))
+	assert.JSONEq(t, string(fresh.Data), string(cached.Data))
+
+	resp, body = do(t, http.MethodGet, api.URL+"/api/schedule?source=cache", token, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, string(body))
+
+	resp, body = do(t, http.MethodGet, api.URL+"/api/profile", token, "")
+	assert.GreaterOrEqual(t, resp.StatusCode, http.StatusInternalServerError, string(body))
+}
+
+// scrapeScheduleOn scrapes the fake schedule of the test user as if it were day
+func scrapeScheduleOn(t *testing.T, s *Server, cookie string, day time.Time) []dtos.ScheduleResponse {
+	t.Helper()
+
+	ctx := imaluum.WithMatricNo(imaluum.WithDay(context.Background(), day), fakeimaluum.Username)
+	schedules, err := s.imaluum.Schedule(ctx, cookie)
+	require.NoError(t, err)
+	return schedules
+}
+
+func TestScheduleSnapshotAcrossDays(t *testing.T) {
+	s, fake := newTestBackend(t)
+	cookie := fake.Login(fakeimaluum.Username)
+	ctx := context.Background()
+
+	_, err := s.db.Exec(`INSERT INTO snapshot_users (matric_no, created_at) VALUES (?, 0)`, fakeimaluum.Username)
+	require.NoError(t, err)
+
+	// The same timetable scraped on a later day is not a new snapshot
+	today := time.Date(2024, time.October, 7, 12, 0, 0, 0, time.UTC)
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		s.keepSnapshot(ctx, fakeimaluum.Username, snapshotSchedule, scrapeScheduleOn(t, s, cookie, day))
+	}
+	assert.Equal(t, 1, countRows(t, s, `SELECT COUNT(*) FROM snapshot_history WHERE kind = ?`, snapshotSchedule))
+}
+
+func TestScheduleETagAcrossDays(t *testing.T) {
+	s, fake := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	token := login(t, api)
+
+	// A scrape on another day fingerprints the same as today's response
+	_, hash, err := snapshot(scrapeScheduleOn(t, s, fake.Login(fakeimaluum.Username), time.Now().AddDate(0, 0, -1)))
+	require.NoError(t, err)
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/v2/schedule", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, `"`+hash+`.v2"`, resp.Header.Get("ETag"))
+
+	resp, body = do(t, http.MethodGet, api.URL+"/api/schedule", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, `"`+hash+`"`, resp.Header.Get("ETag"))
+}
+
+func TestRecordSnapshot(t *testing.T) {
+	s, _ := newTestBackend(t)
+	s.snapshotLimit = 2
+	ctx := context.Background()
+
+	// Nothing is kept before opting in
+	modified, err := s.recordSnapshot(ctx, fakeimaluum.Username, snapshotResult, `"a"`, "a")
+	require.NoError(t, err)
+	assert.True(t, modified.IsZero())
+	assert.Zero(t, countRows(t, s, `SELECT COUNT(*) FROM snapshot_history`))
+
+	_, err = s.db.Exec(`INSERT INTO snapshot_users (matric_no, created_at) VALUES (?, 0)`, fakeimaluum.Username)
+	require.NoError(t, err)
+
+	for _, hash := range []string{"a", "a", "b", "c", "c"} {
+		modified, err := s.recordSnapshot(ctx, fakeimaluum.Username, snapshotResult, `"`+hash+`"`, hash)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), modified, time.Minute)
+	}
+	_, err = s.recordSnapshot(ctx, fakeimaluum.Username, snapshotProfile, `"a"`, "a")
+	require.NoError(t, err)
+
+	rows, err := s.db.Query(`SELECT hash FROM snapshot_history WHERE kind = ? ORDER BY id`, snapshotResult)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		require.NoError(t, rows.Scan(&hash))
+		hashes = append(hashes, hash)
+	}
+	require.NoError(t, rows.Err())
+
+	// Repeats collapse and only the latest two are kept per kind
+	assert.Equal(t, []string{"b", "c"}, hashes)
+	assert.Equal(t, 1, countRows(t, s, `SELECT COUNT(*) FROM snapshot_history WHERE kind = ?`, snapshotProfile))
+}
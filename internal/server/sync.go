@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+)
+
+// streamWriteTimeout bounds each event written to a stream, in place of the
+// server's WriteTimeout which would cut a slow sync short
+const streamWriteTimeout = 30 * time.Second
+
+// Events of /api/sync/{kind}
+const (
+	eventProgress = "progress"
+	eventDone     = "done"
+	eventError    = "error"
+)
+
+// eventStream writes Server-Sent Events
+type eventStream struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func newEventStream(w http.ResponseWriter) *eventStream {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keep reverse proxies such as nginx from holding back events
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	return &eventStream{w: w, rc: http.NewResponseController(w)}
+}
+
+// send writes data as JSON under event and flushes it to the client
+func (e *eventStream) send(event string, data any) error {
+	body, err := sonic.ConfigFastest.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// Writers that cannot move the deadline keep the server's WriteTimeout
+	_ = e.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+
+	if _, err := fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, body); err != nil {
+		return err
+	}
+	return e.rc.Flush()
+}
+
+// @Title SyncStreamHandler
+// @Description Scrape schedule or result from i-Ma'luum while streaming its progress as Server-Sent Events.
+// @Description progress events carry a dtos.SyncProgress, once the session list is read and as each session is parsed.
+// @Description The stream ends with a done event holding the response in the shape of its /api/v2 endpoint, or an error event holding the error body.
+// @Description Closing the connection stops the scrape.
+// @Tags scraper
+// @Produce text/event-stream
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param kind path string true "What to sync" Enums(schedule, result)
+// @Success 200 {object} dtos.SyncProgress
+// @Router /api/sync/{kind} [get]
+func (s *Server) SyncStreamHandler(w http.ResponseWriter, r *http.Request) {
+	kind := chi.URLParam(r, "kind")
+	if kind != snapshotSchedule && kind != snapshotResult {
+		errors.Render(w, r, errors.ErrInvalidSyncKind)
+		return
+	}
+
+	var (
+		logger      = s.log.GetLogger().Sugar()
+		cookie      = r.Context().Value(ctxToken).(string)
+		user        = r.Context().Value(ctxUser).(*TokenPayload)
+		diagnostics = imaluum.NewDiagnostics()
+		stream      = newEventStream(w)
+	)
+
+	ctx := imaluum.WithProgress(imaluum.WithDiagnostics(r.Context(), diagnostics), func(progress dtos.SyncProgress) {
+		// A client that left cancels the scrape through the request context
+		_ = stream.send(eventProgress, progress)
+	})
+
+	scraped, data, err := s.meSections()[kind](ctx, cookie)
+	if r.Context().Err() != nil {
+		logger.Infof("Client left the %s sync of %s", kind, user.username)
+		return
+	}
+
+	if err != nil {
+		logger.Errorf("Failed to sync %s: %v", kind, err)
+		customErr := errors.From(err)
+		_ = stream.send(eventError, &errors.Body{
+			Details:   customErr.Details,
+			Code:      customErr.Code,
+			Message:   customErr.Message,
+			RequestID: middleware.GetReqID(r.Context()),
+			Status:    customErr.GetStatusCode(),
+		})
+		return
+	}
+
+	s.keepSnapshot(r.Context(), user.username, kind, scraped)
+
+	_ = stream.send(eventDone, &dtos.ResponseDTO{
+		Data:      data,
+		Code:      errors.CodeOK,
+		Message:   "Successfully fetched " + kind,
+		RequestID: middleware.GetReqID(r.Context()),
+		Warnings:  diagnostics.Warnings(),
+	})
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/constants"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	name string
+	data json.RawMessage
+}
+
+// streamSync streams /api/sync/kind to the end and splits it into events
+func streamSync(t *testing.T, api *httptest.Server, token, kind string) []testEvent {
+	t.Helper()
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/sync/"+kind, token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var events []testEvent
+	for _, block := range strings.Split(strings.TrimSpace(string(body)), "\n\n") {
+		var event testEvent
+		for _, line := range strings.Split(block, "\n") {
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				event.name = name
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				event.data = json.RawMessage(data)
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestSyncStream(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	events := streamSync(t, api, token, snapshotSchedule)
+	require.Len(t, events, 4)
+
+	for i, event := range events[:3] {
+		require.Equal(t, eventProgress, event.name)
+		var progress dtos.SyncProgress
+		require.NoError(t, json.Unmarshal(event.data, &progress))
+		assert.Equal(t, 2, progress.Total)
+		assert.Equal(t, i, progress.Done)
+	}
+
+	// The payload comes last
+	done := events[3]
+	require.Equal(t, eventDone, done.name)
+	var response testResponse
+	require.NoError(t, json.Unmarshal(done.data, &response))
+	assert.Equal(t, errors.CodeOK, response.Code)
+	assert.Equal(t, "Successfully fetched schedule", response.Message)
+	assertScheduleV2(t, response.Data)
+
+	resp, _ := do(t, http.MethodGet, api.URL+"/api/sync/profile", token, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSyncStreamError(t *testing.T) {
+	s, fake := newTestBackend(t)
+	s.imaluum = imaluum.New(s.httpClient, brokenPage(t, fake, constants.ImaluumResultPath), s.log)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	events := streamSync(t, api, login(t, api), snapshotResult)
+	require.Len(t, events, 2)
+	assert.Equal(t, eventProgress, events[0].name)
+
+	// A result page without sessions ends the stream with the error
+	require.Equal(t, eventError, events[1].name)
+	var failure testResponse
+	require.NoError(t, json.Unmarshal(events[1].data, &failure))
+	assert.Equal(t, errors.CodeResultEmpty, failure.Code)
+	assert.Equal(t, errors.ErrResultIsEmpty.StatusCode, failure.Status)
+	assert.NotEmpty(t, failure.RequestID)
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/go-chi/chi/v5"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+)
+
+const (
+	// apiVersionHeader tells which version of the API answered
+	apiVersionHeader = "API-Version"
+
+	// mediaTypeV2 in Accept routes /api/... to its /api/v2/... counterpart
+	mediaTypeV2 = "application/vnd.gomaluum.v2+json"
+)
+
+var (
+	// v1Deprecated is when the v1 routes with a v2 counterpart were deprecated
+	v1Deprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+	defaultV1Sunset = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+)
+
+type apiVersionKey struct{}
+
+// apiV1Sunset reads API_V1_SUNSET, the YYYY-MM-DD after which the deprecated
+// v1 routes may be removed
+func apiV1Sunset() time.Time {
+	if raw := os.Getenv("API_V1_SUNSET"); raw != "" {
+		if sunset, err := time.Parse(time.DateOnly, raw); err == nil {
+			return sunset
+		}
+		log.Printf("Invalid API_V1_SUNSET=%q, using default %s", raw, defaultV1Sunset.Format(time.DateOnly))
+	}
+	return defaultV1Sunset
+}
+
+// v2Routes are the routes served under /api/v2
+func (s *Server) v2Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(apiVersion(2))
+	r.Use(s.PasetoAuthenticator())
+
+	r.With(conditional, s.cached("profile")).Get("/profile", s.ProfileV2Handler)
+	r.With(conditional, s.cached("schedule")).Get("/schedule", s.ScheduleV2Handler)
+	r.With(conditional, s.cached("result")).Get("/result", s.ResultV2Handler)
+	r.With(conditional, s.cached("starpoint")).Get("/starpoint", s.StarpointV2Handler)
+
+	return r
+}
+
+// apiVersion marks the requests of a route tree with its version
+func apiVersion(version int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(apiVersionHeader, strconv.Itoa(version))
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version)))
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+// requestAPIVersion returns the version of the route tree serving r, 1 for
+// the unversioned routes
+func requestAPIVersion(r *http.Request) int {
+	if version, ok := r.Context().Value(apiVersionKey{}).(int); ok {
+		return version
+	}
+	return 1
+}
+
+// negotiateVersion serves requests to /api/... whose Accept header asks for
+// mediaTypeV2 from the v2 route of the same path, when there is one
+func negotiateVersion(v2 chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			rest, found := strings.CutPrefix(r.URL.Path, "/api")
+			if found && !strings.HasPrefix(rest, "/v2/") && acceptsV2(r) &&
+				v2.Match(chi.NewRouteContext(), r.Method, rest) {
+				r.URL.Path = "/api/v2" + rest
+				r.URL.RawPath = ""
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+func acceptsV2(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(accept, ";")
+		if strings.TrimSpace(mediaType) == mediaTypeV2 {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecated announces, per RFC 9745 and RFC 8594, that a v1 route is
+// superseded by successor and may be removed after sunset
+func deprecated(successor string, sunset time.Time) func(http.Handler) http.Handler {
+	var (
+		deprecation = "@" + strconv.FormatInt(v1Deprecated.Unix(), 10)
+		sunsetDate  = sunset.UTC().Format(http.TimeFormat)
+		link        = "<" + successor + `>; rel="successor-version"`
+	)
+
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunsetDate)
+			w.Header().Add("Link", link)
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+// scheduleSnapshotV2 converts a stored schedule snapshot to its v2 shape
+func scheduleSnapshotV2(payload string) (any, error) {
+	var schedules []dtos.ScheduleResponse
+	if err := sonic.UnmarshalString(payload, &schedules); err != nil {
+		return nil, err
+	}
+	return imaluum.ScheduleV2(schedules), nil
+}
+
+// resultSnapshotV2 converts a stored result snapshot to its v2 shape
+func (s *Server) resultSnapshotV2(payload string) (any, error) {
+	var results []dtos.ResultResponse
+	if err := sonic.UnmarshalString(payload, &results); err != nil {
+		return nil, err
+	}
+	return s.gradePoints.CheckAll(imaluum.ResultsV2(results)), nil
+}
+
+// starpointSnapshotV2 converts a stored starpoint snapshot to its v2 shape
+func starpointSnapshotV2(payload string) (any, error) {
+	var starpoint dtos.Starpoint
+	if err := sonic.UnmarshalString(payload, &starpoint); err != nil {
+		return nil, err
+	}
+	return imaluum.StarpointV2(&starpoint), nil
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testScheduleV2 struct {
+	Schedule []struct {
+		Slots []struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+			Day   uint8  `json:"day"`
+		} `json:"slots"`
+		CreditHours float64 `json:"credit_hours"`
+	} `json:"schedule"`
+}
+
+func getVersioned(t *testing.T, api *httptest.Server, path, token, accept string) (*http.Response, testResponse) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, api.URL+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, body := doRequest(t, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	return resp, response
+}
+
+func assertScheduleV2(t *testing.T, data json.RawMessage) {
+	t.Helper()
+
+	var schedules []testScheduleV2
+	require.NoError(t, json.Unmarshal(data, &schedules))
+	require.Len(t, schedules, 2)
+	require.NotEmpty(t, schedules[0].Schedule)
+
+	subject := schedules[0].Schedule[0]
+	assert.Positive(t, subject.CreditHours)
+	require.NotEmpty(t, subject.Slots)
+	assert.Regexp(t, `^\d{2}:\d{2}$`, subject.Slots[0].Start)
+	assert.Regexp(t, `^\d{2}:\d{2}$`, subject.Slots[0].End)
+	assert.NotContains(t, string(data), "start_unix")
+}
+
+func TestV2Routes(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	resp, response := getVersioned(t, api, "/api/v2/schedule", token, "")
+	assert.Equal(t, "2", resp.Header.Get(apiVersionHeader))
+	assert.Empty(t, resp.Header.Get("Deprecation"))
+	assertScheduleV2(t, response.Data)
+
+	_, response = getVersioned(t, api, "/api/v2/starpoint", token, "")
+	var starpoint map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(response.Data, &starpoint))
+	assert.Contains(t, starpoint, "cumulative_average")
+	assert.NotContains(t, starpoint, "cummulative_average")
+
+	_, response = getVersioned(t, api, "/api/v2/profile", token, "")
+	assert.Equal(t, "Successfully fetched profile", response.Message)
+
+	// The ETag of a version never matches another's
+	v1, _ := getVersioned(t, api, "/api/result", token, "")
+	v2, _ := getVersioned(t, api, "/api/v2/result", token, "")
+	require.NotEmpty(t, v1.Header.Get("ETag"))
+	assert.Equal(t, strings.TrimSuffix(v1.Header.Get("ETag"), `"`)+`.v2"`, v2.Header.Get("ETag"))
+}
+
+func TestVersionNegotiation(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	resp, response := getVersioned(t, api, "/api/schedule", token, mediaTypeV2)
+	assert.Equal(t, "2", resp.Header.Get(apiVersionHeader))
+	assert.Empty(t, resp.Header.Get("Deprecation"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assertScheduleV2(t, response.Data)
+
+	// Routes without a v2 counterpart are served as they are
+	resp, _ = getVersioned(t, api, "/api/result/summary", token, mediaTypeV2)
+	assert.Empty(t, resp.Header.Get(apiVersionHeader))
+
+	resp, _ = getVersioned(t, api, "/api/schedule", token, "application/json")
+	assert.Empty(t, resp.Header.Get(apiVersionHeader))
+}
+
+func TestV1Deprecation(t *testing.T) {
+	t.Setenv("API_V1_SUNSET", "2027-01-01")
+
+	s, _ := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	token := login(t, api)
+
+	for _, route := range []string{"profile", "schedule", "result", "starpoint"} {
+		t.Run(route, func(t *testing.T) {
+			resp, _ := getVersioned(t, api, "/api/"+route, token, "")
+			assert.Equal(t, "@"+strconv.FormatInt(v1Deprecated.Unix(), 10), resp.Header.Get("Deprecation"))
+			assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", resp.Header.Get("Sunset"))
+			assert.Equal(t, `</api/v2/`+route+`>; rel="successor-version"`, resp.Header.Get("Link"))
+		})
+	}
+
+	resp, _ := getVersioned(t, api, "/api/result/summary", token, "")
+	assert.Empty(t, resp.Header.Get("Deprecation"))
+}
+
+func TestV2SnapshotCache(t *testing.T) {
+	s, fake := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	token := login(t, api)
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/snapshots", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	getVersioned(t, api, "/api/v2/schedule", token, "")
+	getVersioned(t, api, "/api/v2/result", token, "")
+	// Snapshots are stored once whichever version fetched them
+	assert.Equal(t, 2, countRows(t, s, `SELECT COUNT(*) FROM snapshot_history`))
+
+	fake.Close()
+
+	resp, response := getVersioned(t, api, "/api/v2/schedule?source=cache", token, "")
+	assert.True(t, strings.HasSuffix(resp.Header.Get("ETag"), `.v2"`))
+	assertScheduleV2(t, response.Data)
+
+	_, response = getVersioned(t, api, "/api/v2/result?source=cache", token, "")
+	var results []struct {
+		Gpa *float64 `json:"gpa"`
+	}
+	require.NoError(t, json.Unmarshal(response.Data, &results))
+	require.Len(t, results, 2)
+	assert.NotNil(t, results[0].Gpa)
+}
+
+func TestAPIV1Sunset(t *testing.T) {
+	t.Setenv("API_V1_SUNSET", "soon")
+	assert.Equal(t, defaultV1Sunset, apiV1Sunset())
+
+	t.Setenv("API_V1_SUNSET", "2027-06-30")
+	assert.Equal(t, time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC), apiV1Sunset())
+}
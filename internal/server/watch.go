@@ -0,0 +1,647 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/lucsky/cuid"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	pb "github.com/nrmnqdds/gomaluum/internal/proto"
+	"github.com/nrmnqdds/gomaluum/pkg/imaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/scheduler"
+	"github.com/nrmnqdds/gomaluum/pkg/utils"
+)
+
+const (
+	defaultWatchInterval = 30 * time.Minute
+
+	// pollSessionLifetime is how long a background login is reused, long
+	// enough for every job of one poll and well within an i-Ma'luum session
+	pollSessionLifetime = 10 * time.Minute
+
+	// EventResultReleased is sent when grades appear or change
+	EventResultReleased = "result.released"
+	// EventScheduleChanged is sent when a timetable appears or changes
+	EventScheduleChanged = "schedule.changed"
+	// EventStarpointAdded is sent when starpoint programs are added
+	EventStarpointAdded = "starpoint.added"
+)
+
+// Kinds of snapshots stored per user
+const (
+	snapshotProfile   = "profile"
+	snapshotResult    = "result"
+	snapshotSchedule  = "schedule"
+	snapshotStarpoint = "starpoint"
+)
+
+// Kinds of the per-user sync jobs, keyed by matric number
+const (
+	jobSyncResult    = "sync.result"
+	jobSyncSchedule  = "sync.schedule"
+	jobSyncStarpoint = "sync.starpoint"
+)
+
+var errEncryptionUnavailable = stderrors.New("failed to encrypt password, is ENCRYPTION_KEY set?")
+
+// watchTarget is a user polled in the background and where to tell them
+type watchTarget struct {
+	matricNo      string
+	password      string
+	webhookURL    string
+	webhookSecret string
+	push          bool
+}
+
+// watchInterval reads WATCH_POLL_INTERVAL, how often each watched user is
+// synced (default 30m)
+func watchInterval() time.Duration {
+	if raw := os.Getenv("WATCH_POLL_INTERVAL"); raw != "" {
+		if value, err := time.ParseDuration(raw); err == nil && value >= time.Second {
+			return value
+		}
+		log.Printf("Invalid WATCH_POLL_INTERVAL=%q, using default %s", raw, defaultWatchInterval)
+	}
+	return defaultWatchInterval
+}
+
+// registerJobs installs the sync job handlers. Webhooks only carry results,
+// push subscribers also hear about their timetable and starpoints.
+func (s *Server) registerJobs() {
+	s.scheduler.Handle(jobSyncResult, s.syncJob(false, s.checkResults))
+	s.scheduler.Handle(jobSyncSchedule, s.syncJob(true, s.checkSchedule))
+	s.scheduler.Handle(jobSyncStarpoint, s.syncJob(true, s.checkStarpoint))
+
+	// Jobs only draw on what is left of the upstream burst, so interactive
+	// requests never queue behind background syncs
+	s.scheduler.Ready = func() bool {
+		limiter := s.upstream.Limiter
+		return limiter.Tokens() >= float64(limiter.Burst())/2
+	}
+}
+
+// watchUser starts polling for user, storing their password encrypted so the
+// poller can log in as them
+func (s *Server) watchUser(ctx context.Context, user *TokenPayload) error {
+	encrypted := utils.Encrypt(user.password)
+	if encrypted == "" {
+		return errEncryptionUnavailable
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+			INSERT INTO watchers (matric_no, password, created_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT(matric_no)
+			DO UPDATE SET password = excluded.password
+		`, user.username, base64.StdEncoding.EncodeToString([]byte(encrypted)), time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	interval := watchInterval()
+	for _, kind := range []string{jobSyncResult, jobSyncSchedule, jobSyncStarpoint} {
+		if err := s.scheduler.Ensure(ctx, kind, user.username, interval); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchUserError is the error rendered when watchUser fails with err
+func watchUserError(err error) *errors.CustomError {
+	if stderrors.Is(err, errEncryptionUnavailable) {
+		return errors.ErrFailedToEncryptCredentials
+	}
+	return errors.ErrFailedToQueryDB
+}
+
+// unwatchIfUnused stops polling for a user with neither a webhook nor a push
+// subscription left, forgetting their password, snapshots and jobs
+func (s *Server) unwatchIfUnused(ctx context.Context, matricNo string) error {
+	result, err := s.db.ExecContext(ctx, `
+			DELETE FROM watchers
+			WHERE matric_no = ?
+				AND NOT EXISTS (SELECT 1 FROM webhooks WHERE matric_no = ?)
+				AND NOT EXISTS (SELECT 1 FROM push_subscriptions WHERE matric_no = ?)
+		`, matricNo, matricNo, matricNo)
+	if err != nil {
+		return err
+	}
+	if deleted, err := result.RowsAffected(); err != nil || deleted == 0 {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM snapshots WHERE matric_no = ?`, matricNo); err != nil {
+		return err
+	}
+
+	return s.scheduler.Remove(ctx, matricNo)
+}
+
+func (s *Server) watchTarget(ctx context.Context, matricNo string) (watchTarget, error) {
+	target := watchTarget{matricNo: matricNo}
+	err := s.db.QueryRowContext(ctx, `
+			SELECT
+				w.password,
+				COALESCE(h.url, ''),
+				COALESCE(h.secret, ''),
+				EXISTS (SELECT 1 FROM push_subscriptions p WHERE p.matric_no = w.matric_no)
+			FROM watchers w
+			LEFT JOIN webhooks h ON h.matric_no = w.matric_no
+			WHERE w.matric_no = ?
+		`, matricNo).Scan(&target.password, &target.webhookURL, &target.webhookSecret, &target.push)
+	return target, err
+}
+
+// syncJob runs check as the user the job is keyed by. Jobs that only push
+// are skipped without logging in while the user has no push subscription.
+func (s *Server) syncJob(pushOnly bool, check func(ctx context.Context, target watchTarget, cookie string) error) scheduler.Handler {
+	return func(ctx context.Context, job scheduler.Job) error {
+		target, err := s.watchTarget(ctx, job.Key)
+		if stderrors.Is(err, sql.ErrNoRows) {
+			// Unwatched while the job was due
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if pushOnly && !target.push {
+			return nil
+		}
+
+		cookie, err := s.login(ctx, target)
+		if err == nil {
+			err = check(imaluum.WithMatricNo(ctx, target.matricNo), target, cookie)
+		}
+		if err != nil {
+			s.log.GetLogger().Sugar().Warnf("Failed to run %s: %v", job.Name, err)
+		}
+		return err
+	}
+}
+
+// login decrypts the stored password of target and logs in as them
+func (s *Server) login(ctx context.Context, target watchTarget) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(target.password)
+	if err != nil {
+		return "", err
+	}
+	password := utils.Decrypt(string(encrypted))
+	if password == "" {
+		return "", fmt.Errorf("failed to decrypt password")
+	}
+
+	cookie, err := s.backgroundLogin(ctx, target.matricNo, password)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE watchers SET last_polled_at = ? WHERE matric_no = ?`, time.Now().Unix(), target.matricNo); err != nil {
+		return "", err
+	}
+
+	return cookie, nil
+}
+
+// updateSnapshot stores the latest scrape of kind and returns the previous
+// payload when the fingerprint changed. The first poll is the baseline,
+// everything in it is already known, so it reports no change.
+func (s *Server) updateSnapshot(ctx context.Context, matricNo, kind, payload, hash string) (string, bool, error) {
+	var previousPayload, previousHash string
+	err := s.db.QueryRowContext(ctx, `
+			SELECT payload, hash FROM snapshots WHERE matric_no = ? AND kind = ?
+		`, matricNo, kind).Scan(&previousPayload, &previousHash)
+	if err != nil && !stderrors.Is(err, sql.ErrNoRows) {
+		return "", false, err
+	}
+	firstPoll := stderrors.Is(err, sql.ErrNoRows)
+
+	if hash == previousHash {
+		return "", false, nil
+	}
+
+	// Skip users unwatched while the poll ran, their snapshots are gone for good
+	if _, err := s.db.ExecContext(ctx, `
+			INSERT INTO snapshots (matric_no, kind, payload, hash, updated_at)
+			SELECT ?, ?, ?, ?, ?
+			WHERE EXISTS (SELECT 1 FROM watchers WHERE matric_no = ?)
+			ON CONFLICT(matric_no, kind)
+			DO UPDATE SET payload = excluded.payload, hash = excluded.hash, updated_at = excluded.updated_at
+		`, matricNo, kind, payload, hash, time.Now().Unix(), matricNo); err != nil {
+		return "", false, err
+	}
+
+	return previousPayload, !firstPoll, nil
+}
+
+func (s *Server) checkResults(ctx context.Context, target watchTarget, cookie string) error {
+	results, err := s.imaluum.Results(ctx, cookie)
+	if err != nil {
+		return err
+	}
+
+	payload, hash, err := snapshot(results)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.recordSnapshot(ctx, target.matricNo, snapshotResult, payload, hash); err != nil {
+		return err
+	}
+
+	previousPayload, changed, err := s.updateSnapshot(ctx, target.matricNo, snapshotResult, payload, hash)
+	if err != nil || !changed {
+		return err
+	}
+
+	var previous []dtos.ResultResponse
+	if err := sonic.UnmarshalString(previousPayload, &previous); err != nil {
+		return err
+	}
+
+	releases := releasedGrades(previous, results)
+	if len(releases) == 0 {
+		return nil
+	}
+
+	var errs []error
+	if target.webhookURL != "" {
+		errs = append(errs, s.deliverReleases(ctx, target, releases))
+	}
+	if target.push {
+		_, err := s.notify(ctx, target.matricNo, releaseNotification(releases))
+		errs = append(errs, err)
+	}
+
+	return stderrors.Join(errs...)
+}
+
+func (s *Server) checkSchedule(ctx context.Context, target watchTarget, cookie string) error {
+	schedules, err := s.imaluum.Schedule(ctx, cookie)
+	if err != nil {
+		return err
+	}
+
+	payload, hash, err := snapshot(schedules)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.recordSnapshot(ctx, target.matricNo, snapshotSchedule, payload, hash); err != nil {
+		return err
+	}
+
+	previousPayload, changed, err := s.updateSnapshot(ctx, target.matricNo, snapshotSchedule, payload, hash)
+	if err != nil || !changed {
+		return err
+	}
+
+	var previous []dtos.ScheduleResponse
+	if err := sonic.UnmarshalString(previousPayload, &previous); err != nil {
+		return err
+	}
+
+	changes := scheduleChanges(previous, schedules)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	_, err = s.notify(ctx, target.matricNo, scheduleNotification(changes))
+	return err
+}
+
+func (s *Server) checkStarpoint(ctx context.Context, target watchTarget, cookie string) error {
+	starpoint, err := s.imaluum.Starpoint(ctx, cookie)
+	if err != nil {
+		return err
+	}
+
+	payload, hash, err := snapshot(starpoint)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.recordSnapshot(ctx, target.matricNo, snapshotStarpoint, payload, hash); err != nil {
+		return err
+	}
+
+	previousPayload, changed, err := s.updateSnapshot(ctx, target.matricNo, snapshotStarpoint, payload, hash)
+	if err != nil || !changed {
+		return err
+	}
+
+	var previous dtos.Starpoint
+	if err := sonic.UnmarshalString(previousPayload, &previous); err != nil {
+		return err
+	}
+
+	added := addedStarpoints(&previous, starpoint)
+	if len(added) == 0 {
+		return nil
+	}
+
+	_, err = s.notify(ctx, target.matricNo, starpointNotification(added))
+	return err
+}
+
+// deliverReleases calls the webhook, parking the event in the dead letter
+// table when every attempt fails
+func (s *Server) deliverReleases(ctx context.Context, target watchTarget, releases []dtos.GradeRelease) error {
+	event := dtos.WebhookEvent{
+		ID:        cuid.New(),
+		Type:      EventResultReleased,
+		MatricNo:  target.matricNo,
+		Releases:  releases,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	body, err := sonic.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	attempts, err := s.webhooks.Deliver(ctx, target.webhookURL, target.webhookSecret, event.Type, event.ID, body)
+	if err == nil {
+		return nil
+	}
+
+	if _, dbErr := s.db.ExecContext(ctx, `
+			INSERT INTO webhook_dead_letters (matric_no, url, event, error, attempts, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, target.matricNo, target.webhookURL, string(body), err.Error(), attempts, time.Now().Unix()); dbErr != nil {
+		return stderrors.Join(err, dbErr)
+	}
+
+	return err
+}
+
+// backgroundLogin returns an i-Ma'luum cookie for a user outside of a request,
+// sharing in-flight logins with requests of the same user. The cookie is
+// reused for pollSessionLifetime, so the jobs of one poll log in once.
+func (s *Server) backgroundLogin(ctx context.Context, username, password string) (string, error) {
+	return s.pollTokens.GetToken(username, func() (string, time.Time, error) {
+		cookie, err := s.tokenManager.GetToken(username, func() (string, time.Time, error) {
+			resp, err := s.grpc.Login(ctx, &pb.LoginRequest{
+				Username: username,
+				Password: password,
+			})
+			if err != nil {
+				return "", time.Now(), err
+			}
+			return resp.Token, time.Now(), nil
+		})
+		return cookie, time.Now().Add(pollSessionLifetime), err
+	})
+}
+
+// snapshot serialises value for storage and fingerprints it. Scraped IDs
+// are derived from the scraped fields, so they are fingerprinted along.
+// Schedules are fingerprinted undated, so an unchanged timetable keeps its
+// fingerprint from one day to the next.
+func snapshot(value any) (string, string, error) {
+	payload, err := sonic.MarshalString(value)
+	if err != nil {
+		return "", "", err
+	}
+
+	fingerprinted := payload
+	if schedules, ok := value.([]dtos.ScheduleResponse); ok {
+		if fingerprinted, err = sonic.MarshalString(undated(schedules)); err != nil {
+			return "", "", err
+		}
+	}
+	sum := sha256.Sum256([]byte(fingerprinted))
+
+	return payload, hex.EncodeToString(sum[:]), nil
+}
+
+// releasedGrades lists the graded courses in latest that were missing,
+// pending or graded differently in previous
+func releasedGrades(previous, latest []dtos.ResultResponse) []dtos.GradeRelease {
+	key := func(session, course string) string {
+		return session + "\x00" + course
+	}
+
+	known := make(map[string]dtos.Grade)
+	for _, result := range previous {
+		for _, course := range result.Result {
+			known[key(result.SessionName, course.CourseCode)] = imaluum.ParseGrade(course.CourseGrade)
+		}
+	}
+
+	var releases []dtos.GradeRelease
+	for _, result := range latest {
+		for _, course := range result.Result {
+			grade := imaluum.ParseGrade(course.CourseGrade)
+			if grade == dtos.GradePending {
+				continue
+			}
+
+			before, seen := known[key(result.SessionName, course.CourseCode)]
+			if seen && before == grade {
+				continue
+			}
+
+			release := dtos.GradeRelease{
+				SessionName: result.SessionName,
+				CourseCode:  course.CourseCode,
+				CourseName:  course.CourseName,
+				Grade:       grade,
+			}
+			if seen && before != dtos.GradePending {
+				release.Previous = before
+			}
+			releases = append(releases, release)
+		}
+	}
+
+	return releases
+}
+
+// undated copies schedules without start_unix and end_unix. They are dated on
+// the day of the scrape, so they would tell the same timetable apart each day.
+func undated(schedules []dtos.ScheduleResponse) []dtos.ScheduleResponse {
+	copied := slices.Clone(schedules)
+	for i := range copied {
+		copied[i].Schedule = slices.Clone(copied[i].Schedule)
+		for j := range copied[i].Schedule {
+			subject := &copied[i].Schedule[j]
+			subject.Timestamps = slices.Clone(subject.Timestamps)
+			for k := range subject.Timestamps {
+				subject.Timestamps[k].StartUnix = 0
+				subject.Timestamps[k].EndUnix = 0
+			}
+		}
+	}
+	return copied
+}
+
+// scheduleChanges lists, per session, the courses added, removed or moved
+// to another time, venue or lecturer since previous
+func scheduleChanges(previous, latest []dtos.ScheduleResponse) []dtos.ScheduleChange {
+	previous, latest = undated(previous), undated(latest)
+
+	key := func(subject dtos.ScheduleSubject) string {
+		return fmt.Sprintf("%s\x00%d", subject.CourseCode, subject.Section)
+	}
+	fingerprint := func(subject dtos.ScheduleSubject) string {
+		subject.ID = ""
+		value, _ := sonic.MarshalString(subject)
+		return value
+	}
+	// courses fingerprints every row of a course together, a course taught
+	// in more than one place spans several rows. It also returns the first
+	// row of each course, in order.
+	courses := func(subjects []dtos.ScheduleSubject) (map[string]string, []dtos.ScheduleSubject) {
+		fingerprints := make(map[string]string, len(subjects))
+		var first []dtos.ScheduleSubject
+		for _, subject := range subjects {
+			if _, ok := fingerprints[key(subject)]; !ok {
+				first = append(first, subject)
+			}
+			fingerprints[key(subject)] += fingerprint(subject) + "\n"
+		}
+		return fingerprints, first
+	}
+
+	sessions := make(map[string][]dtos.ScheduleSubject, len(previous))
+	for _, schedule := range previous {
+		sessions[schedule.SessionName] = schedule.Schedule
+	}
+
+	var changes []dtos.ScheduleChange
+	for _, schedule := range latest {
+		known, before := courses(sessions[schedule.SessionName])
+		current, subjects := courses(schedule.Schedule)
+		change := dtos.ScheduleChange{SessionName: schedule.SessionName}
+
+		for _, subject := range subjects {
+			fingerprints, ok := known[key(subject)]
+			switch {
+			case !ok:
+				change.Added = append(change.Added, subject.CourseCode)
+			case fingerprints != current[key(subject)]:
+				change.Changed = append(change.Changed, subject.CourseCode)
+			}
+		}
+
+		for _, subject := range before {
+			if _, ok := current[key(subject)]; !ok {
+				change.Removed = append(change.Removed, subject.CourseCode)
+			}
+		}
+
+		if len(change.Added)+len(change.Removed)+len(change.Changed) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// addedStarpoints lists the programs in latest that were not in previous
+func addedStarpoints(previous, latest *dtos.Starpoint) []dtos.StarpointProgram {
+	key := func(program dtos.StarpointProgram) string {
+		program.ID = ""
+		value, _ := sonic.MarshalString(program)
+		return value
+	}
+
+	known := make(map[string]int, len(previous.Programs))
+	for _, program := range previous.Programs {
+		known[key(program)]++
+	}
+
+	var added []dtos.StarpointProgram
+	for _, program := range latest.Programs {
+		if known[key(program)] > 0 {
+			known[key(program)]--
+			continue
+		}
+		added = append(added, program)
+	}
+
+	return added
+}
+
+// summarise joins the first few items and counts the rest
+func summarise(items []string) string {
+	const shown = 3
+	if len(items) <= shown {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:shown], ", "), len(items)-shown)
+}
+
+func releaseNotification(releases []dtos.GradeRelease) dtos.PushNotification {
+	items := make([]string, len(releases))
+	for i, release := range releases {
+		items[i] = fmt.Sprintf("%s: %s", release.CourseCode, release.Grade)
+	}
+
+	return dtos.PushNotification{
+		Type:      EventResultReleased,
+		Title:     "New grades released",
+		Body:      summarise(items),
+		URL:       "/result",
+		Data:      releases,
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+func scheduleNotification(changes []dtos.ScheduleChange) dtos.PushNotification {
+	items := make([]string, 0, len(changes))
+	for _, change := range changes {
+		var parts []string
+		for _, part := range []struct {
+			verb    string
+			courses []string
+		}{
+			{"added", change.Added},
+			{"removed", change.Removed},
+			{"changed", change.Changed},
+		} {
+			if len(part.courses) > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", len(part.courses), part.verb))
+			}
+		}
+		items = append(items, fmt.Sprintf("%s: %s", change.SessionName, strings.Join(parts, ", ")))
+	}
+
+	return dtos.PushNotification{
+		Type:      EventScheduleChanged,
+		Title:     "Timetable changed",
+		Body:      summarise(items),
+		URL:       "/schedule",
+		Data:      changes,
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+func starpointNotification(programs []dtos.StarpointProgram) dtos.PushNotification {
+	items := make([]string, len(programs))
+	for i, program := range programs {
+		items[i] = fmt.Sprintf("%s (+%g)", program.EventName, program.Points)
+	}
+
+	return dtos.PushNotification{
+		Type:      EventStarpointAdded,
+		Title:     "New starpoints",
+		Body:      summarise(items),
+		URL:       "/starpoint",
+		Data:      programs,
+		CreatedAt: time.Now().Unix(),
+	}
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/webhook"
+)
+
+// @Title RegisterWebhookHandler
+// @Description Registers a webhook called with a signed result.released event when new grades appear. Results are polled in the background, the first poll only records a baseline. The secret is only returned here.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param body body dtos.WebhookRequest true "Webhook URL"
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.Webhook}
+// @Router /api/webhooks [post]
+func (s *Server) RegisterWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+		req    dtos.WebhookRequest
+	)
+
+	if err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Sugar().Errorf("Failed to decode request body: %v", err)
+		errors.Render(w, r, errors.ErrInvalidRequest)
+		return
+	}
+
+	if err := s.webhooks.Validate(r.Context(), req.URL); err != nil {
+		errors.Render(w, r, errors.ErrInvalidWebhookURL)
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		logger.Sugar().Errorf("Failed to generate webhook secret: %v", err)
+		errors.Render(w, r, errors.ErrInternal)
+		return
+	}
+
+	if err := s.watchUser(r.Context(), user); err != nil {
+		logger.Sugar().Errorf("Failed to watch user: %v", err)
+		errors.Render(w, r, watchUserError(err))
+		return
+	}
+
+	registered := dtos.Webhook{
+		URL:       req.URL,
+		Secret:    secret,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	if _, err := s.db.ExecContext(r.Context(), `
+			INSERT INTO webhooks (matric_no, url, secret, created_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(matric_no)
+			DO UPDATE SET url = excluded.url, secret = excluded.secret, created_at = excluded.created_at
+		`, user.username, registered.URL, secret, registered.CreatedAt); err != nil {
+		logger.Sugar().Errorf("Failed to save webhook: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Webhook registered! Keep the secret to verify deliveries.",
+		Data:    registered,
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title GetWebhookHandler
+// @Description Get the webhook registered for the user
+// @Tags webhook
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} dtos.ResponseDTO{data=dtos.Webhook}
+// @Router /api/webhooks [get]
+func (s *Server) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger     = s.log.GetLogger()
+		user       = r.Context().Value(ctxUser).(*TokenPayload)
+		registered dtos.Webhook
+		lastPolled sql.NullInt64
+	)
+
+	err := s.db.QueryRowContext(r.Context(), `
+			SELECT h.url, h.created_at, w.last_polled_at
+			FROM webhooks h
+			LEFT JOIN watchers w ON w.matric_no = h.matric_no
+			WHERE h.matric_no = ?
+		`, user.username).Scan(&registered.URL, &registered.CreatedAt, &lastPolled)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		errors.Render(w, r, errors.ErrWebhookNotFound)
+		return
+	}
+	if err != nil {
+		logger.Sugar().Errorf("Failed to get webhook: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+	if lastPolled.Valid {
+		registered.LastPolledAt = &lastPolled.Int64
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Successfully fetched webhook",
+		Data:    registered,
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title DeleteWebhookHandler
+// @Description Removes the webhook of the user. Background polling stops and the stored password is forgotten once the user has no push subscription left either.
+// @Tags webhook
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} dtos.ResponseDTO
+// @Router /api/webhooks [delete]
+func (s *Server) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+	)
+
+	if _, err := s.db.ExecContext(r.Context(), `DELETE FROM webhooks WHERE matric_no = ?`, user.username); err != nil {
+		logger.Sugar().Errorf("Failed to delete webhook: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	if err := s.unwatchIfUnused(r.Context(), user.username); err != nil {
+		logger.Sugar().Errorf("Failed to unwatch user: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Webhook removed",
+	}
+
+	s.respond(w, r, response)
+}
+
+// @Title WebhookDeadLettersHandler
+// @Description List the latest webhook deliveries of the user that failed every attempt
+// @Tags webhook
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} dtos.ResponseDTO{data=[]dtos.DeadLetter}
+// @Router /api/webhooks/dead-letters [get]
+func (s *Server) WebhookDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		logger = s.log.GetLogger()
+		user   = r.Context().Value(ctxUser).(*TokenPayload)
+	)
+
+	rows, err := s.db.QueryContext(r.Context(), `
+			SELECT id, url, event, error, attempts, created_at
+			FROM webhook_dead_letters
+			WHERE matric_no = ?
+			ORDER BY id DESC
+			LIMIT 50
+		`, user.username)
+	if err != nil {
+		logger.Sugar().Errorf("Failed to get dead letters: %v", err)
+		errors.Render(w, r, errors.ErrFailedToQueryDB)
+		return
+	}
+	defer rows.Close()
+
+	deadLetters := []dtos.DeadLetter{}
+	for rows.Next() {
+		var deadLetter dtos.DeadLetter
+		if err := rows.Scan(&deadLetter.ID, &deadLetter.URL, &deadLetter.Event, &deadLetter.Error, &deadLetter.Attempts, &deadLetter.CreatedAt); err != nil {
+			errors.Render(w, r, errors.ErrFailedToMapDBRows)
+			return
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	response := &dtos.ResponseDTO{
+		Message: "Successfully fetched dead letters",
+		Data:    deadLetters,
+	}
+
+	s.respond(w, r, response)
+}
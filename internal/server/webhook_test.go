@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerWebhook registers target for the logged in user and returns the secret
+func registerWebhook(t *testing.T, api *httptest.Server, token, target string) string {
+	t.Helper()
+
+	resp, body := do(t, http.MethodPost, api.URL+"/api/webhooks", token, `{"url":"`+target+`"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+
+	var registered dtos.Webhook
+	require.NoError(t, json.Unmarshal(response.Data, &registered))
+	assert.Equal(t, target, registered.URL)
+	require.NotEmpty(t, registered.Secret)
+
+	return registered.Secret
+}
+
+// forgetGrade rewrites the stored snapshot as if the first course of the
+// latest session was still pending
+func forgetGrade(t *testing.T, s *Server, matricNo string) dtos.Result {
+	t.Helper()
+
+	var results []dtos.ResultResponse
+	loadSnapshot(t, s, matricNo, snapshotResult, &results)
+	require.NotEmpty(t, results)
+	require.NotEmpty(t, results[0].Result)
+
+	course := results[0].Result[0]
+	results[0].Result[0].CourseGrade = ""
+	storeStaleSnapshot(t, s, matricNo, snapshotResult, results)
+
+	return course
+}
+
+func loadSnapshot(t *testing.T, s *Server, matricNo, kind string, value any) {
+	t.Helper()
+
+	var payload string
+	require.NoError(t, s.db.QueryRow(`SELECT payload FROM snapshots WHERE matric_no = ? AND kind = ?`, matricNo, kind).Scan(&payload))
+	require.NoError(t, sonic.UnmarshalString(payload, value))
+}
+
+// storeStaleSnapshot replaces a stored snapshot, so the next poll diffs against value
+func storeStaleSnapshot(t *testing.T, s *Server, matricNo, kind string, value any) {
+	t.Helper()
+
+	payload, err := sonic.MarshalString(value)
+	require.NoError(t, err)
+	_, err = s.db.Exec(`UPDATE snapshots SET payload = ?, hash = 'stale' WHERE matric_no = ? AND kind = ?`, payload, matricNo, kind)
+	require.NoError(t, err)
+}
+
+func TestWebhookRoutes(t *testing.T) {
+	api, _ := newTestServer(t)
+	token := login(t, api)
+
+	resp, _ := do(t, http.MethodGet, api.URL+"/api/webhooks", token, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	for _, target := range []string{"not a url", "/relative", "ftp://example.com/hook"} {
+		resp, _ := do(t, http.MethodPost, api.URL+"/api/webhooks", token, `{"url":"`+target+`"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, target)
+	}
+
+	registerWebhook(t, api, token, "https://example.com/hook")
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/webhooks", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), "https://example.com/hook")
+	assert.NotContains(t, string(body), "whsec_")
+
+	resp, body = do(t, http.MethodDelete, api.URL+"/api/webhooks", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	resp, _ = do(t, http.MethodGet, api.URL+"/api/webhooks", token, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestWebhookWatchFailure(t *testing.T) {
+	s, _ := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+
+	token := login(t, api)
+	_, err := s.db.Exec(`DROP TABLE watchers`)
+	require.NoError(t, err)
+
+	// The credentials were encrypted, it is the database that failed
+	resp, body := do(t, http.MethodPost, api.URL+"/api/webhooks", token, `{"url":"https://example.com/hook"}`)
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode, string(body))
+
+	var failure testResponse
+	require.NoError(t, json.Unmarshal(body, &failure))
+	assert.Equal(t, errors.ErrFailedToQueryDB.Message, failure.Message)
+}
+
+func TestResultWebhook(t *testing.T) {
+	s, _ := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+	token := login(t, api)
+
+	events := make(chan dtos.WebhookEvent, 4)
+	var secret string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+		if !webhook.Verify(secret, timestamp, body, r.Header.Get(webhook.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var event dtos.WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events <- event
+	}))
+	t.Cleanup(receiver.Close)
+
+	secret = registerWebhook(t, api, token, receiver.URL)
+
+	// The first poll only records a baseline
+	runJobs(t, s)
+	assert.Empty(t, events)
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/webhooks", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), "last_polled_at")
+
+	// Nothing changed upstream
+	runJobs(t, s)
+	assert.Empty(t, events)
+
+	course := forgetGrade(t, s, fakeimaluum.Username)
+	runJobs(t, s)
+
+	require.Len(t, events, 1)
+	event := <-events
+	assert.Equal(t, EventResultReleased, event.Type)
+	require.Len(t, event.Releases, 1)
+	assert.Equal(t, course.CourseCode, event.Releases[0].CourseCode)
+	assert.NotEqual(t, dtos.GradePending, event.Releases[0].Grade)
+	assert.Empty(t, event.Releases[0].Previous)
+}
+
+func TestResultWebhookDeadLetter(t *testing.T) {
+	s, _ := newTestBackend(t)
+	api := httptest.NewServer(s.RegisterRoutes())
+	t.Cleanup(api.Close)
+	token := login(t, api)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(receiver.Close)
+
+	registerWebhook(t, api, token, receiver.URL)
+	runJobs(t, s)
+	forgetGrade(t, s, fakeimaluum.Username)
+	runJobs(t, s)
+
+	resp, body := do(t, http.MethodGet, api.URL+"/api/webhooks/dead-letters", token, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var response testResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+
+	var deadLetters []dtos.DeadLetter
+	require.NoError(t, json.Unmarshal(response.Data, &deadLetters))
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, receiver.URL, deadLetters[0].URL)
+	assert.Equal(t, 2, deadLetters[0].Attempts)
+	assert.Contains(t, deadLetters[0].Error, "500")
+	assert.Contains(t, deadLetters[0].Event, EventResultReleased)
+}
+
+func TestReleasedGrades(t *testing.T) {
+	session := func(grades ...string) []dtos.ResultResponse {
+		result := dtos.ResultResponse{SessionName: "Sem 1, 2024/2025"}
+		for i, grade := range grades {
+			result.Result = append(result.Result, dtos.Result{
+				CourseCode:  "CSCI 100" + strconv.Itoa(i),
+				CourseGrade: grade,
+			})
+		}
+		return []dtos.ResultResponse{result}
+	}
+
+	releases := releasedGrades(session("A", "", "B"), session("A", "B+", "B-", "C"))
+	require.Len(t, releases, 3)
+	assert.Equal(t, dtos.GradeRelease{SessionName: "Sem 1, 2024/2025", CourseCode: "CSCI 1001", Grade: dtos.GradeBPlus}, releases[0])
+	assert.Equal(t, dtos.GradeRelease{SessionName: "Sem 1, 2024/2025", CourseCode: "CSCI 1002", Grade: dtos.GradeBMinus, Previous: dtos.GradeB}, releases[1])
+	assert.Equal(t, dtos.GradeC, releases[2].Grade)
+
+	assert.Empty(t, releasedGrades(session("A", "-"), session("A", "")))
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
 	auth_proto "github.com/nrmnqdds/gomaluum/internal/proto"
 	"github.com/nrmnqdds/gomaluum/internal/server"
 	"github.com/nrmnqdds/gomaluum/pkg/utils"
@@ -92,8 +93,8 @@ func main() {
 		log.Println("Running in production mode")
 	}
 
-	// Initialize gRPC server
-	grpcServer := grpc.NewServer()
+	// Initialize gRPC server, sending failures with the same codes as HTTP
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(errors.UnaryServerInterceptor))
 	grpcService := server.NewGRPCServer()
 	auth_proto.RegisterAuthServer(grpcServer, grpcService)
 
@@ -0,0 +1,288 @@
+// Package cache keeps successful GET responses in memory, served fresh for a
+// TTL and then stale while a single request refreshes them in the background.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Values of the X-Cache header
+const (
+	Hit     = "HIT"
+	Stale   = "STALE"
+	Miss    = "MISS"
+	Refresh = "REFRESH"
+)
+
+const defaultMaxEntries = 2000
+
+// Policy is how long a response is served as is, then for how much longer it
+// is served stale while being refreshed
+type Policy struct {
+	TTL   time.Duration
+	Stale time.Duration
+}
+
+// PolicyFromEnv returns fallback overridden by CACHE_<NAME>_TTL and CACHE_<NAME>_STALE
+func PolicyFromEnv(name string, fallback Policy) Policy {
+	prefix := "CACHE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+
+	return Policy{
+		TTL:   envDuration(prefix+"_TTL", fallback.TTL),
+		Stale: envDuration(prefix+"_STALE", fallback.Stale),
+	}
+}
+
+type entry struct {
+	header   http.Header
+	body     []byte
+	storedAt time.Time
+	expires  time.Time
+}
+
+// Cache holds responses keyed by scope, path and query
+type Cache struct {
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	group   singleflight.Group
+	now     func() time.Time
+}
+
+// New reads CACHE_MAX_ENTRIES, the number of responses kept before the
+// oldest are evicted
+func New() *Cache {
+	maxEntries := defaultMaxEntries
+	if raw := os.Getenv("CACHE_MAX_ENTRIES"); raw != "" {
+		if value, err := strconv.Atoi(raw); err == nil && value > 0 {
+			maxEntries = value
+		} else {
+			log.Printf("Invalid CACHE_MAX_ENTRIES=%q, using default %d", raw, maxEntries)
+		}
+	}
+
+	return &Cache{MaxEntries: maxEntries}
+}
+
+// Middleware caches the GET responses of next under policy. scope returns who
+// the response belongs to, e.g. the matric number, and an empty scope skips
+// the cache. Responses are kept apart per Accept header, which may pick
+// another encoding. ?fresh=true skips the lookup and replaces the stored response.
+// Concurrent requests for a response that is not stored share one call to next.
+func (c *Cache) Middleware(policy Policy, scope func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			owner := scope(r)
+			if r.Method != http.MethodGet || owner == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			query := r.URL.Query()
+			fresh, _ := strconv.ParseBool(query.Get("fresh"))
+			query.Del("fresh")
+			key := owner + " " + r.URL.Path + "?" + query.Encode() + " " + r.Header.Get("Accept")
+
+			if !fresh {
+				if cached, age, ok := c.lookup(key); ok {
+					status := Hit
+					if age >= policy.TTL {
+						status = Stale
+						c.revalidate(key, policy, next, r)
+					}
+					write(w, cached.header, http.StatusOK, cached.body, status, age, policy)
+					return
+				}
+			}
+
+			status := Miss
+			if fresh {
+				status = Refresh
+			}
+
+			rec := c.fill(key, policy, next, r)
+			write(w, rec.header, rec.status, rec.body.Bytes(), status, 0, policy)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+// Forget drops every response stored for scope
+func (c *Cache) Forget(scope string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, scope+" ") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *Cache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// lookup returns the response stored under key while it may still be served
+func (c *Cache) lookup(key string) (*entry, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+
+	now := c.clock()
+	if !now.Before(cached.expires) {
+		delete(c.entries, key)
+		return nil, 0, false
+	}
+
+	return cached, now.Sub(cached.storedAt), true
+}
+
+type fillingKey struct{}
+
+// Filling reports whether ctx is that of a call filling the cache. Its
+// response is replayed to other requests, so it should hold nothing that
+// belongs to the request alone.
+func Filling(ctx context.Context) bool {
+	filling, _ := ctx.Value(fillingKey{}).(bool)
+	return filling
+}
+
+// fill calls next once for everyone waiting on key and stores a 200 response.
+// The call is shared, so it runs on a context that is not canceled when the
+// request that started it goes away.
+func (c *Cache) fill(key string, policy Policy, next http.Handler, r *http.Request) *recorder {
+	v, _, _ := c.group.Do(key, func() (any, error) {
+		ctx := context.WithValue(context.WithoutCancel(r.Context()), fillingKey{}, true)
+		rec := newRecorder()
+		next.ServeHTTP(rec, r.Clone(ctx))
+		// A handler that wrote nothing answered 200
+		rec.WriteHeader(http.StatusOK)
+		if rec.status == http.StatusOK {
+			c.store(key, policy, rec)
+		}
+		return rec, nil
+	})
+	return v.(*recorder)
+}
+
+// revalidate refreshes a stale response without holding up the request
+func (c *Cache) revalidate(key string, policy Policy, next http.Handler, r *http.Request) {
+	go c.fill(key, policy, next, r)
+}
+
+func (c *Cache) store(key string, policy Policy, rec *recorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]*entry)
+	}
+
+	now := c.clock()
+	if _, ok := c.entries[key]; !ok && c.MaxEntries > 0 && len(c.entries) >= c.MaxEntries {
+		c.evict(now)
+	}
+
+	c.entries[key] = &entry{
+		header:   rec.header.Clone(),
+		body:     bytes.Clone(rec.body.Bytes()),
+		storedAt: now,
+		expires:  now.Add(policy.TTL + policy.Stale),
+	}
+}
+
+// evict drops expired responses, or the oldest one when none expired
+func (c *Cache) evict(now time.Time) {
+	var (
+		oldestKey string
+		oldest    time.Time
+	)
+	for key, cached := range c.entries {
+		if !now.Before(cached.expires) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || cached.storedAt.Before(oldest) {
+			oldestKey, oldest = key, cached.storedAt
+		}
+	}
+
+	if len(c.entries) >= c.MaxEntries {
+		delete(c.entries, oldestKey)
+	}
+}
+
+func write(w http.ResponseWriter, header http.Header, status int, body []byte, cacheStatus string, age time.Duration, policy Policy) {
+	for name, values := range header {
+		w.Header()[name] = slices.Clone(values)
+	}
+	if status == http.StatusOK {
+		w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d, stale-while-revalidate=%d", int(policy.TTL.Seconds()), int(policy.Stale.Seconds())))
+		w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+		w.Header().Set("X-Cache", cacheStatus)
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// recorder buffers a response so it can be stored and shared
+type recorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: make(http.Header)}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(p)
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil || value < 0 {
+		log.Printf("Invalid %s=%q, using default %s", key, raw, fallback)
+		return fallback
+	}
+
+	return value
+}
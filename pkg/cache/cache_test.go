@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = Policy{TTL: time.Minute, Stale: time.Hour}
+
+// clock is a manually advanced time source
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// counter answers with how many times it was called
+type counter struct {
+	calls  atomic.Int32
+	status int
+}
+
+func (h *counter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	calls := h.calls.Add(1)
+	w.Header().Set("Content-Type", "text/plain")
+	if h.status != 0 {
+		w.WriteHeader(h.status)
+	}
+	_, _ = w.Write([]byte(strconv.Itoa(int(calls))))
+}
+
+func newTestCache() (*Cache, *clock) {
+	now := &clock{now: time.Unix(1_700_000_000, 0)}
+	return &Cache{MaxEntries: 10, now: now.Now}, now
+}
+
+func byUser(r *http.Request) string {
+	return r.Header.Get("X-User")
+}
+
+func get(t *testing.T, handler http.Handler, target, user string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("X-User", user)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	c, now := newTestCache()
+	next := &counter{}
+	handler := c.Middleware(testPolicy, byUser)(next)
+
+	rec := get(t, handler, "/schedule", "a")
+	assert.Equal(t, "1", rec.Body.String())
+	assert.Equal(t, Miss, rec.Header().Get("X-Cache"))
+	assert.Equal(t, "private, max-age=60, stale-while-revalidate=3600", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+
+	now.Advance(30 * time.Second)
+	rec = get(t, handler, "/schedule", "a")
+	assert.Equal(t, "1", rec.Body.String())
+	assert.Equal(t, Hit, rec.Header().Get("X-Cache"))
+	assert.Equal(t, "30", rec.Header().Get("Age"))
+
+	// Users, paths and queries are cached apart, fresh is not part of the key
+	assert.Equal(t, "2", get(t, handler, "/schedule", "b").Body.String())
+	assert.Equal(t, "3", get(t, handler, "/schedule?session=1", "a").Body.String())
+	assert.Equal(t, "4", get(t, handler, "/result", "a").Body.String())
+
+	rec = get(t, handler, "/schedule?fresh=true", "a")
+	assert.Equal(t, "5", rec.Body.String())
+	assert.Equal(t, Refresh, rec.Header().Get("X-Cache"))
+	assert.Equal(t, "5", get(t, handler, "/schedule", "a").Body.String())
+
+	// Each Accept header has its own response
+	req := httptest.NewRequest(http.MethodGet, "/schedule", nil)
+	req.Header.Set("X-User", "a")
+	req.Header.Set("Accept", "application/cbor")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "6", rec.Body.String())
+	assert.Equal(t, Miss, rec.Header().Get("X-Cache"))
+
+	// No scope, no cache
+	assert.Equal(t, "7", get(t, handler, "/schedule", "").Body.String())
+	assert.Equal(t, "8", get(t, handler, "/schedule", "").Body.String())
+
+	c.Forget("a")
+	assert.Equal(t, "9", get(t, handler, "/schedule", "a").Body.String())
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	c, now := newTestCache()
+	next := &counter{}
+	handler := c.Middleware(testPolicy, byUser)(next)
+
+	get(t, handler, "/schedule", "a")
+	now.Advance(2 * time.Minute)
+
+	rec := get(t, handler, "/schedule", "a")
+	assert.Equal(t, "1", rec.Body.String())
+	assert.Equal(t, Stale, rec.Header().Get("X-Cache"))
+	assert.Equal(t, "120", rec.Header().Get("Age"))
+
+	// The refresh runs in the background and replaces the stale response
+	require.Eventually(t, func() bool {
+		return get(t, handler, "/schedule", "a").Body.String() == "2"
+	}, time.Second, time.Millisecond)
+
+	// Past the stale window the response is gone
+	now.Advance(2 * time.Hour)
+	rec = get(t, handler, "/schedule", "a")
+	assert.Equal(t, Miss, rec.Header().Get("X-Cache"))
+	assert.Equal(t, "3", rec.Body.String())
+}
+
+func TestErrorsAreNotCached(t *testing.T) {
+	c, _ := newTestCache()
+	next := &counter{status: http.StatusBadGateway}
+	handler := c.Middleware(testPolicy, byUser)(next)
+
+	rec := get(t, handler, "/schedule", "a")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-Cache"))
+
+	get(t, handler, "/schedule", "a")
+	assert.Equal(t, int32(2), next.calls.Load())
+}
+
+func TestConcurrentMissesShareOneCall(t *testing.T) {
+	c, _ := newTestCache()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	handler := c.Middleware(testPolicy, byUser)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = w.Write([]byte("done"))
+	}))
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bodies[i] = get(t, handler, "/schedule", "a").Body.String()
+		}()
+	}
+
+	// Let every request reach the cache before the first call completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, body := range bodies {
+		assert.Equal(t, "done", body)
+	}
+}
+
+func TestFirstCallerLeaving(t *testing.T) {
+	c, _ := newTestCache()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := c.Middleware(testPolicy, byUser)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, Filling(r.Context()))
+		close(started)
+		<-release
+		if r.Context().Err() != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("done"))
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := httptest.NewRequestWithContext(ctx, http.MethodGet, "/schedule", nil)
+	first.Header.Set("X-User", "a")
+	go handler.ServeHTTP(httptest.NewRecorder(), first)
+	<-started
+
+	var second *httptest.ResponseRecorder
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		second = get(t, handler, "/schedule", "a")
+	}()
+
+	// Let the second request join the call before the first one leaves
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	close(release)
+	<-done
+
+	// The call the first request started still answers the second one
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "done", second.Body.String())
+	assert.Equal(t, "done", get(t, handler, "/schedule", "a").Body.String())
+}
+
+func TestEviction(t *testing.T) {
+	c, now := newTestCache()
+	c.MaxEntries = 2
+	next := &counter{}
+	handler := c.Middleware(testPolicy, byUser)(next)
+
+	get(t, handler, "/a", "u")
+	now.Advance(time.Second)
+	get(t, handler, "/b", "u")
+	now.Advance(time.Second)
+	get(t, handler, "/c", "u")
+
+	// The oldest made room
+	assert.Equal(t, Miss, get(t, handler, "/a", "u").Header().Get("X-Cache"))
+	assert.Len(t, c.entries, 2)
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv("CACHE_STUDY_PLAN_TTL", "5m")
+	t.Setenv("CACHE_STUDY_PLAN_STALE", "soon")
+
+	assert.Equal(t, Policy{TTL: 5 * time.Minute, Stale: time.Hour}, PolicyFromEnv("study-plan", testPolicy))
+}
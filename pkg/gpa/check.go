@@ -0,0 +1,80 @@
+package gpa
+
+import (
+	"math"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// checkTolerance absorbs i-Ma'luum rounding GPAs to two decimals
+const checkTolerance = 0.01
+
+// Check recomputes the GPA of a session from its course rows and compares it
+// with the GPA i-Ma'luum printed, listing the courses left out of the GPA
+func (t Table) Check(result dtos.ResultResponseV2) dtos.GpaCheck {
+	check := dtos.GpaCheck{
+		ScrapedGpa: result.Gpa,
+		Excluded:   []dtos.ExcludedCourse{},
+	}
+
+	var standing Standing
+	for _, course := range result.Result {
+		if reason, excluded := t.exclusion(course); excluded {
+			check.Excluded = append(check.Excluded, dtos.ExcludedCourse{
+				CourseCode: course.CourseCode,
+				Grade:      course.Grade,
+				Reason:     reason,
+			})
+			continue
+		}
+		standing = t.Add(standing, course.Grade, *course.Credit)
+	}
+
+	check.ComputedGpa = standing.Average()
+	check.ComputedCredits = standing.Credits
+
+	if check.ScrapedGpa == nil || check.ComputedGpa == nil {
+		check.Status = dtos.GpaCheckUnverified
+		return check
+	}
+
+	check.Difference = Round(*check.ComputedGpa - *check.ScrapedGpa)
+	if math.Abs(*check.Difference) <= checkTolerance {
+		check.Status = dtos.GpaCheckMatch
+	} else {
+		check.Status = dtos.GpaCheckMismatch
+	}
+
+	return check
+}
+
+// CheckAll attaches a GpaCheck to every session
+func (t Table) CheckAll(results []dtos.ResultResponseV2) []dtos.ResultResponseV2 {
+	for i := range results {
+		check := t.Check(results[i])
+		results[i].GpaCheck = &check
+	}
+	return results
+}
+
+func (t Table) exclusion(course dtos.ResultV2) (dtos.ExclusionReason, bool) {
+	if _, ok := t.Points(course.Grade); ok {
+		if course.Credit == nil || *course.Credit <= 0 {
+			return dtos.ExcludedNoCredit, true
+		}
+		return "", false
+	}
+
+	switch course.Grade {
+	case dtos.GradePending:
+		return dtos.ExcludedPending, true
+	case dtos.GradeIncomplete:
+		return dtos.ExcludedIncomplete, true
+	case dtos.GradeWithdrawn:
+		return dtos.ExcludedWithdrawn, true
+	case dtos.GradePass:
+		return dtos.ExcludedPassFail, true
+	default:
+		return dtos.ExcludedUnknown, true
+	}
+}
@@ -0,0 +1,63 @@
+package gpa
+
+import (
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	named := func(code string, grade dtos.Grade, credit float64) dtos.ResultV2 {
+		c := course(grade, credit)
+		c.CourseCode = code
+		return c
+	}
+	courses := []dtos.ResultV2{
+		named("CSCI 3300", dtos.GradeA, 3),
+		named("INFO 3302", dtos.GradeBPlus, 3),
+		named("UNGS 2050", dtos.GradeAMinus, 2),
+		named("LM 1021", dtos.GradePass, 1),
+		named("CSCI 4401", dtos.GradePending, 3),
+		named("CSCI 4402", dtos.GradeIncomplete, 3),
+		{CourseCode: "CSCI 4403", Grade: dtos.GradeB},
+	}
+
+	t.Run("match", func(t *testing.T) {
+		check := DefaultTable.Check(dtos.ResultResponseV2{Gpa: ptr(3.67), Result: courses})
+
+		assert.Equal(t, dtos.GpaCheckMatch, check.Status)
+		assert.InDelta(t, 3.67, *check.ComputedGpa, 0.001)
+		assert.InDelta(t, 8, check.ComputedCredits, 0.001)
+		assert.InDelta(t, 0, *check.Difference, 0.001)
+		assert.Equal(t, []dtos.ExcludedCourse{
+			{CourseCode: "LM 1021", Grade: dtos.GradePass, Reason: dtos.ExcludedPassFail},
+			{CourseCode: "CSCI 4401", Grade: dtos.GradePending, Reason: dtos.ExcludedPending},
+			{CourseCode: "CSCI 4402", Grade: dtos.GradeIncomplete, Reason: dtos.ExcludedIncomplete},
+			{CourseCode: "CSCI 4403", Grade: dtos.GradeB, Reason: dtos.ExcludedNoCredit},
+		}, check.Excluded)
+	})
+
+	t.Run("off by rounding", func(t *testing.T) {
+		check := DefaultTable.Check(dtos.ResultResponseV2{Gpa: ptr(3.66), Result: courses})
+		assert.Equal(t, dtos.GpaCheckMatch, check.Status)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		check := DefaultTable.Check(dtos.ResultResponseV2{Gpa: ptr(3.50), Result: courses})
+
+		assert.Equal(t, dtos.GpaCheckMismatch, check.Status)
+		require.NotNil(t, check.Difference)
+		assert.InDelta(t, 0.17, *check.Difference, 0.001)
+	})
+
+	t.Run("unverified", func(t *testing.T) {
+		check := DefaultTable.Check(dtos.ResultResponseV2{Result: courses})
+		assert.Equal(t, dtos.GpaCheckUnverified, check.Status)
+
+		check = DefaultTable.Check(dtos.ResultResponseV2{Gpa: ptr(3.67), Result: courses[3:5]})
+		assert.Equal(t, dtos.GpaCheckUnverified, check.Status)
+		assert.Nil(t, check.ComputedGpa)
+	})
+}
@@ -0,0 +1,157 @@
+// Package gpa computes IIUM grade point averages from scraped results.
+package gpa
+
+import (
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// Table maps a grade to its grade points. Grades missing from the table,
+// such as pass/fail, incomplete or pending grades, do not count towards the GPA.
+type Table map[dtos.Grade]float64
+
+// DefaultTable is the IIUM undergraduate grading scale
+var DefaultTable = Table{
+	dtos.GradeA:      4.00,
+	dtos.GradeAMinus: 3.67,
+	dtos.GradeBPlus:  3.33,
+	dtos.GradeB:      3.00,
+	dtos.GradeBMinus: 2.67,
+	dtos.GradeCPlus:  2.33,
+	dtos.GradeC:      2.00,
+	dtos.GradeD:      1.67,
+	dtos.GradeDMinus: 1.33,
+	dtos.GradeE:      1.00,
+	dtos.GradeF:      0.00,
+}
+
+// TableFromEnv returns DefaultTable with the entries of GPA_GRADE_POINTS
+// applied on top, e.g. GPA_GRADE_POINTS="D=1.00,D-=0.67"
+func TableFromEnv() Table {
+	table := make(Table, len(DefaultTable))
+	for grade, points := range DefaultTable {
+		table[grade] = points
+	}
+
+	raw := os.Getenv("GPA_GRADE_POINTS")
+	if raw == "" {
+		return table
+	}
+
+	for entry := range strings.SplitSeq(raw, ",") {
+		grade, value, found := strings.Cut(entry, "=")
+		points, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !found || err != nil || points < 0 {
+			log.Printf("Invalid GPA_GRADE_POINTS entry %q, skipping", entry)
+			continue
+		}
+		table[dtos.Grade(strings.ToUpper(strings.TrimSpace(grade)))] = points
+	}
+
+	return table
+}
+
+// Points returns the grade points of grade, and false if the grade does not count towards the GPA
+func (t Table) Points(grade dtos.Grade) (float64, bool) {
+	points, ok := t[grade]
+	return points, ok
+}
+
+// Max returns the highest grade points in the table
+func (t Table) Max() float64 {
+	var highest float64
+	for _, points := range t {
+		highest = max(highest, points)
+	}
+	return highest
+}
+
+// Standing is a cumulative position: the credit hours counted towards the
+// CGPA and the grade points earned on them
+type Standing struct {
+	Credits float64
+	Points  float64
+}
+
+// Average returns Points per credit hour, or nil without credits
+func (s Standing) Average() *float64 {
+	if s.Credits <= 0 {
+		return nil
+	}
+	return Round(s.Points / s.Credits)
+}
+
+// Add returns s with a course added, if its grade counts towards the GPA
+func (t Table) Add(s Standing, grade dtos.Grade, credits float64) Standing {
+	points, ok := t.Points(grade)
+	if !ok || credits <= 0 {
+		return s
+	}
+	return Standing{
+		Credits: s.Credits + credits,
+		Points:  s.Points + points*credits,
+	}
+}
+
+// Current derives the standing from scraped results, most recent session first.
+// It trusts the CGPA i-Ma'luum printed on the latest session that has one, and
+// only recomputes from the course rows when no session has a CGPA. Either way
+// a course taken in several sessions counts once, by its latest attempt.
+func (t Table) Current(results []dtos.ResultResponseV2) Standing {
+	for i, result := range results {
+		if result.Cgpa == nil {
+			continue
+		}
+
+		credits := t.latestAttempts(results[i:]).Credits
+		for _, older := range results[i:] {
+			// Without course rows repeats cannot be told apart, trust the
+			// credit hours i-Ma'luum printed
+			if len(older.Result) == 0 && older.CreditHours != nil {
+				credits += *older.CreditHours
+			}
+		}
+		return Standing{
+			Credits: credits,
+			Points:  *result.Cgpa * credits,
+		}
+	}
+
+	return t.latestAttempts(results)
+}
+
+// latestAttempts adds up the latest attempt of every course in results, most
+// recent session first, as Summary does
+func (t Table) latestAttempts(results []dtos.ResultResponseV2) Standing {
+	var (
+		standing Standing
+		seen     = make(map[string]bool)
+	)
+	for _, result := range results {
+		// A course listed twice in one session counts by its last row
+		for _, course := range slices.Backward(result.Result) {
+			if code := courseCode(course.CourseCode); code != "" {
+				if seen[code] {
+					continue
+				}
+				seen[code] = true
+			}
+			if course.Credit != nil {
+				standing = t.Add(standing, course.Grade, *course.Credit)
+			}
+		}
+	}
+	return standing
+}
+
+// Round rounds to two decimals, the precision i-Ma'luum prints
+func Round(value float64) *float64 {
+	rounded := math.Round(value*100) / 100
+	return &rounded
+}
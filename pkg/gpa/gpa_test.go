@@ -0,0 +1,155 @@
+package gpa
+
+import (
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr(value float64) *float64 {
+	return &value
+}
+
+func course(grade dtos.Grade, credit float64) dtos.ResultV2 {
+	return dtos.ResultV2{Grade: grade, Credit: ptr(credit)}
+}
+
+func coded(code string, grade dtos.Grade, credit float64) dtos.ResultV2 {
+	result := course(grade, credit)
+	result.CourseCode = code
+	return result
+}
+
+func TestTableFromEnv(t *testing.T) {
+	t.Setenv("GPA_GRADE_POINTS", "d=1.00, D-=0.67,E=oops")
+
+	table := TableFromEnv()
+	assert.InDelta(t, 1.00, table[dtos.GradeD], 0.001)
+	assert.InDelta(t, 0.67, table[dtos.GradeDMinus], 0.001)
+	assert.InDelta(t, 1.00, table[dtos.GradeE], 0.001)
+	assert.InDelta(t, 4.00, table.Max(), 0.001)
+
+	// The default table is left alone
+	assert.InDelta(t, 1.67, DefaultTable[dtos.GradeD], 0.001)
+}
+
+func TestCurrent(t *testing.T) {
+	t.Run("trusts the latest scraped CGPA", func(t *testing.T) {
+		standing := DefaultTable.Current([]dtos.ResultResponseV2{
+			// Grades not out yet
+			{Result: []dtos.ResultV2{course(dtos.GradePending, 3)}},
+			{Cgpa: ptr(3.52), CreditHours: ptr(8)},
+			{Cgpa: ptr(3.40), CreditHours: ptr(12)},
+		})
+
+		assert.InDelta(t, 20, standing.Credits, 0.001)
+		assert.InDelta(t, 3.52, *standing.Average(), 0.001)
+	})
+
+	t.Run("recomputes without a scraped CGPA", func(t *testing.T) {
+		standing := DefaultTable.Current([]dtos.ResultResponseV2{
+			{Result: []dtos.ResultV2{course(dtos.GradeA, 3), course(dtos.GradeB, 3), course(dtos.GradePass, 2)}},
+		})
+
+		assert.InDelta(t, 6, standing.Credits, 0.001)
+		assert.InDelta(t, 3.5, *standing.Average(), 0.001)
+	})
+
+	t.Run("counts a repeated course once", func(t *testing.T) {
+		results := []dtos.ResultResponseV2{
+			{Cgpa: ptr(3.00), Result: []dtos.ResultV2{
+				coded("CSCI 1301", dtos.GradeB, 3),
+				coded("MATH 1310", dtos.GradeA, 3),
+			}},
+			{Cgpa: ptr(2.00), Result: []dtos.ResultV2{
+				coded("csci  1301", dtos.GradeF, 3),
+				coded("LM 1021", dtos.GradePass, 1),
+				coded("ENGL 1301", dtos.GradeB, 3),
+			}},
+		}
+
+		standing := DefaultTable.Current(results)
+		assert.InDelta(t, 9, standing.Credits, 0.001)
+		assert.InDelta(t, 3.00, *standing.Average(), 0.001)
+
+		// Without a printed CGPA the failed attempt is replaced as well
+		results[0].Cgpa, results[1].Cgpa = nil, nil
+		standing = DefaultTable.Current(results)
+		assert.InDelta(t, 9, standing.Credits, 0.001)
+		assert.InDelta(t, 3.33, *standing.Average(), 0.001)
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		assert.Nil(t, DefaultTable.Current(nil).Average())
+	})
+}
+
+func TestSimulate(t *testing.T) {
+	history := []dtos.ResultResponseV2{{Cgpa: ptr(3.00), CreditHours: ptr(10)}}
+
+	t.Run("projects the simulated courses", func(t *testing.T) {
+		simulation, err := DefaultTable.Simulate(history, dtos.SimulateRequest{
+			Courses: []dtos.SimulatedCourse{
+				{Grade: dtos.GradeA, Credit: 3},
+				{Grade: dtos.GradeB, Credit: 3},
+				{Grade: dtos.GradePass, Credit: 1},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.InDelta(t, 3.00, *simulation.Current.Cgpa, 0.001)
+		assert.InDelta(t, 10, simulation.Current.CreditHours, 0.001)
+		assert.Nil(t, simulation.Current.Gpa)
+
+		assert.InDelta(t, 3.50, *simulation.Projected.Gpa, 0.001)
+		assert.InDelta(t, 3.19, *simulation.Projected.Cgpa, 0.001)
+		assert.InDelta(t, 16, simulation.Projected.CreditHours, 0.001)
+		assert.Nil(t, simulation.Target)
+	})
+
+	t.Run("target over the simulated courses", func(t *testing.T) {
+		simulation, err := DefaultTable.Simulate(history, dtos.SimulateRequest{
+			TargetCgpa: ptr(3.5),
+			Courses:    []dtos.SimulatedCourse{{Grade: dtos.GradeB, Credit: 10}},
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, simulation.Target)
+		assert.InDelta(t, 4.00, simulation.Target.RequiredAverage, 0.001)
+		assert.InDelta(t, 10, simulation.Target.RemainingCredits, 0.001)
+		assert.True(t, simulation.Target.Achievable)
+	})
+
+	t.Run("target over the remaining credits", func(t *testing.T) {
+		simulation, err := DefaultTable.Simulate(history, dtos.SimulateRequest{
+			TargetCgpa:       ptr(3.8),
+			RemainingCredits: ptr(10),
+			Courses:          []dtos.SimulatedCourse{{Grade: dtos.GradeB, Credit: 10}},
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, simulation.Target)
+		assert.InDelta(t, 5.40, simulation.Target.RequiredAverage, 0.001)
+		assert.False(t, simulation.Target.Achievable)
+	})
+
+	t.Run("target already reached", func(t *testing.T) {
+		simulation, err := DefaultTable.Simulate(history, dtos.SimulateRequest{
+			TargetCgpa: ptr(2.0),
+			Courses:    []dtos.SimulatedCourse{{Grade: dtos.GradeA, Credit: 3}},
+		})
+		require.NoError(t, err)
+
+		assert.Zero(t, simulation.Target.RequiredAverage)
+		assert.True(t, simulation.Target.Achievable)
+	})
+
+	t.Run("unknown grade", func(t *testing.T) {
+		_, err := DefaultTable.Simulate(history, dtos.SimulateRequest{
+			Courses: []dtos.SimulatedCourse{{Grade: "Z", Credit: 3}},
+		})
+		assert.EqualError(t, err, `grade "Z" is not in the grade point table`)
+	})
+}
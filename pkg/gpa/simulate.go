@@ -0,0 +1,91 @@
+package gpa
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// ErrUnknownGrade is returned when a simulated course has a grade the table does not know
+type ErrUnknownGrade struct {
+	Grade dtos.Grade
+}
+
+func (e *ErrUnknownGrade) Error() string {
+	return fmt.Sprintf("grade %q is not in the grade point table", e.Grade)
+}
+
+// Simulate projects the GPA and CGPA after the given courses on top of the
+// scraped results, and the average needed to reach a target CGPA
+func (t Table) Simulate(results []dtos.ResultResponseV2, req dtos.SimulateRequest) (dtos.SimulateResponse, error) {
+	current := t.Current(results)
+
+	var semester Standing
+	for _, course := range req.Courses {
+		if _, ok := t.Points(course.Grade); !ok && !Uncounted(course.Grade) {
+			return dtos.SimulateResponse{}, &ErrUnknownGrade{Grade: course.Grade}
+		}
+		semester = t.Add(semester, course.Grade, course.Credit)
+	}
+
+	projected := Standing{
+		Credits: current.Credits + semester.Credits,
+		Points:  current.Points + semester.Points,
+	}
+
+	response := dtos.SimulateResponse{
+		GradePoints: t,
+		Current: dtos.Projection{
+			Cgpa:        current.Average(),
+			CreditHours: current.Credits,
+		},
+		Projected: dtos.Projection{
+			Gpa:         semester.Average(),
+			Cgpa:        projected.Average(),
+			CreditHours: projected.Credits,
+		},
+	}
+
+	if req.TargetCgpa != nil {
+		// Work out the target over the simulated courses from the current
+		// standing, or over the remaining credits after them
+		from, remaining := current, semester.Credits
+		if req.RemainingCredits != nil {
+			from, remaining = projected, *req.RemainingCredits
+		}
+		response.Target = t.target(from, *req.TargetCgpa, remaining)
+	}
+
+	return response, nil
+}
+
+func (t Table) target(from Standing, cgpa, remaining float64) *dtos.SimulatedTarget {
+	target := &dtos.SimulatedTarget{
+		Cgpa:             cgpa,
+		RemainingCredits: remaining,
+	}
+
+	if remaining <= 0 {
+		average := from.Average()
+		target.Achievable = average != nil && *average >= cgpa
+		return target
+	}
+
+	// Round up, a rounded down average would fall just short of the target.
+	// The epsilon keeps float noise on exact averages from rounding them up.
+	required := math.Ceil((cgpa*(from.Credits+remaining)-from.Points)/remaining*100-1e-9) / 100
+	target.RequiredAverage = max(required, 0)
+	target.Achievable = target.RequiredAverage <= t.Max()
+
+	return target
+}
+
+// Uncounted reports whether grade is a known grade that never carries grade points
+func Uncounted(grade dtos.Grade) bool {
+	switch grade {
+	case dtos.GradePass, dtos.GradeIncomplete, dtos.GradeWithdrawn, dtos.GradePending:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package gpa
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// Outcome is what a single attempt at a course amounts to
+type Outcome int
+
+const (
+	Pending Outcome = iota
+	Passed
+	Failed
+	Withdrawn
+	// Unknown is a grade the scraper could not read
+	Unknown
+)
+
+// Outcome classifies a grade. Counted grades worth no grade points fail,
+// pass/fail courses pass on P, withdrawn courses are neither, and only
+// grades still to be given, pending or incomplete, are pending.
+func (t Table) Outcome(grade dtos.Grade) Outcome {
+	if points, ok := t.Points(grade); ok {
+		if points > 0 {
+			return Passed
+		}
+		return Failed
+	}
+	switch grade {
+	case dtos.GradePass:
+		return Passed
+	case dtos.GradeWithdrawn:
+		return Withdrawn
+	case dtos.GradePending, dtos.GradeIncomplete:
+		return Pending
+	}
+	return Unknown
+}
+
+type attempt struct {
+	course  dtos.ResultV2
+	session string
+}
+
+// Summary aggregates results, most recent session first, into a transcript.
+// A course code taken in several sessions counts once, by its latest attempt.
+func (t Table) Summary(results []dtos.ResultResponseV2) dtos.TranscriptSummary {
+	summary := dtos.TranscriptSummary{
+		GradeDistribution: make(map[dtos.Grade]int),
+		Repeated:          []dtos.RepeatedCourse{},
+		Trend:             make([]dtos.SemesterTrend, 0, len(results)),
+	}
+
+	var (
+		codes    []string
+		attempts = make(map[string][]attempt)
+	)
+
+	// Walk oldest session first so the last attempt is the latest
+	for _, result := range slices.Backward(results) {
+		summary.Trend = append(summary.Trend, dtos.SemesterTrend{
+			SessionName: result.SessionName,
+			Gpa:         result.Gpa,
+			Cgpa:        result.Cgpa,
+			CreditHours: result.CreditHours,
+		})
+		if result.Cgpa != nil {
+			summary.Cgpa = result.Cgpa
+		}
+
+		for _, course := range result.Result {
+			code := courseCode(course.CourseCode)
+			previous, ok := attempts[code]
+			if !ok {
+				codes = append(codes, code)
+			}
+			// A course listed twice in one session is one attempt
+			if ok && previous[len(previous)-1].session == result.SessionName {
+				previous[len(previous)-1].course = course
+				continue
+			}
+			attempts[code] = append(previous, attempt{course: course, session: result.SessionName})
+		}
+	}
+
+	for _, code := range codes {
+		courseAttempts := attempts[code]
+		latest := courseAttempts[len(courseAttempts)-1].course
+
+		for _, a := range courseAttempts {
+			summary.GradeDistribution[a.course.Grade]++
+		}
+
+		summary.Courses.Total++
+		switch t.Outcome(latest.Grade) {
+		case Passed:
+			summary.Courses.Passed++
+			if latest.Credit != nil {
+				summary.CreditHoursEarned += *latest.Credit
+			}
+		case Failed:
+			summary.Courses.Failed++
+		case Withdrawn:
+			summary.Courses.Withdrawn++
+		case Pending:
+			summary.Courses.Pending++
+		default:
+			summary.Courses.Unknown++
+		}
+
+		if len(courseAttempts) < 2 {
+			continue
+		}
+
+		summary.Courses.Repeated++
+		repeated := dtos.RepeatedCourse{
+			CourseCode: latest.CourseCode,
+			CourseName: latest.CourseName,
+			Attempts:   make([]dtos.CourseAttempt, 0, len(courseAttempts)),
+		}
+		for _, a := range courseAttempts {
+			repeated.Attempts = append(repeated.Attempts, dtos.CourseAttempt{
+				SessionName: a.session,
+				Grade:       a.course.Grade,
+			})
+		}
+		summary.Repeated = append(summary.Repeated, repeated)
+	}
+
+	return summary
+}
+
+// courseCode normalises the spacing and case of a course code, "csci  1301" is "CSCI 1301"
+func courseCode(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), " "))
+}
@@ -0,0 +1,83 @@
+package gpa
+
+import (
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	calculus := func(grade dtos.Grade) dtos.ResultV2 {
+		c := course(grade, 3)
+		c.CourseCode = "MATH 1310"
+		c.CourseName = "CALCULUS I"
+		return c
+	}
+	named := func(code string, grade dtos.Grade, credit float64) dtos.ResultV2 {
+		c := course(grade, credit)
+		c.CourseCode = code
+		return c
+	}
+
+	summary := DefaultTable.Summary([]dtos.ResultResponseV2{
+		{
+			SessionName: "Sem 1, 2024/2025",
+			Result:      []dtos.ResultV2{named("CSCI 3300", dtos.GradePending, 3), named("SCSH 2101", dtos.GradeWithdrawn, 2)},
+		},
+		{
+			SessionName: "Sem 2, 2023/2024",
+			Gpa:         ptr(3.10),
+			Cgpa:        ptr(2.80),
+			Result:      []dtos.ResultV2{calculus(dtos.GradeB), named("LM 1021", dtos.GradePass, 1)},
+		},
+		{
+			SessionName: "Sem 1, 2023/2024",
+			Gpa:         ptr(2.50),
+			Cgpa:        ptr(2.50),
+			Result:      []dtos.ResultV2{calculus(dtos.GradeF), named("csci  1301", dtos.GradeA, 4), named("CSCI 1301", dtos.GradeA, 4)},
+		},
+	})
+
+	require.Len(t, summary.Trend, 3)
+	assert.Equal(t, "Sem 1, 2023/2024", summary.Trend[0].SessionName)
+	assert.Equal(t, "Sem 1, 2024/2025", summary.Trend[2].SessionName)
+	assert.Nil(t, summary.Trend[2].Cgpa)
+	assert.InDelta(t, 2.80, *summary.Cgpa, 0.001)
+
+	// CSCI 1301 was listed twice in the same session, that is not a repeat
+	// SCSH 2101 was withdrawn, it is not awaiting a result
+	assert.Equal(t, dtos.CourseCounts{Total: 5, Passed: 3, Withdrawn: 1, Pending: 1, Repeated: 1}, summary.Courses)
+	// MATH 1310 and CSCI 1301 count once each
+	assert.InDelta(t, 8, summary.CreditHoursEarned, 0.001)
+
+	assert.Equal(t, map[dtos.Grade]int{
+		dtos.GradeA:         1,
+		dtos.GradeB:         1,
+		dtos.GradeF:         1,
+		dtos.GradePass:      1,
+		dtos.GradePending:   1,
+		dtos.GradeWithdrawn: 1,
+	}, summary.GradeDistribution)
+
+	require.Len(t, summary.Repeated, 1)
+	assert.Equal(t, dtos.RepeatedCourse{
+		CourseCode: "MATH 1310",
+		CourseName: "CALCULUS I",
+		Attempts: []dtos.CourseAttempt{
+			{SessionName: "Sem 1, 2023/2024", Grade: dtos.GradeF},
+			{SessionName: "Sem 2, 2023/2024", Grade: dtos.GradeB},
+		},
+	}, summary.Repeated[0])
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, Passed, DefaultTable.Outcome(dtos.GradeDMinus))
+	assert.Equal(t, Failed, DefaultTable.Outcome(dtos.GradeF))
+	assert.Equal(t, Passed, DefaultTable.Outcome(dtos.GradePass))
+	assert.Equal(t, Withdrawn, DefaultTable.Outcome(dtos.GradeWithdrawn))
+	assert.Equal(t, Pending, DefaultTable.Outcome(dtos.GradePending))
+	assert.Equal(t, Pending, DefaultTable.Outcome(dtos.GradeIncomplete))
+	assert.Equal(t, Unknown, DefaultTable.Outcome(dtos.GradeUnknown))
+}
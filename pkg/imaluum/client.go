@@ -0,0 +1,110 @@
+// Package imaluum scrapes i-Ma'luum on behalf of a student.
+//
+// Every method takes the student's MOD_AUTH_CAS cookie and a context, so the
+// same client can back the HTTP handlers, the gRPC server or a CLI.
+package imaluum
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/lucsky/cuid"
+	"github.com/nrmnqdds/gomaluum/internal/constants"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/logger"
+	"github.com/nrmnqdds/gomaluum/pkg/upstream"
+)
+
+var UnwantedSessionQueries = [...]string{
+	"?ses=1111/1111&sem=1",
+	"?ses=0000/0000&sem=0",
+}
+
+// Session is an academic session listed in the i-Ma'luum session dropdown
+type Session struct {
+	Name  string `json:"session_name"`
+	Query string `json:"session_query"`
+}
+
+type Client struct {
+	httpClient    *http.Client
+	log           *logger.AppLogger
+	retryPolicies map[string]upstream.RetryPolicy
+	parseHealth   *ParseHealth
+	urls          constants.Upstream
+}
+
+func New(httpClient *http.Client, urls constants.Upstream, log *logger.AppLogger) *Client {
+	return &Client{
+		httpClient:    httpClient,
+		log:           log,
+		urls:          urls,
+		retryPolicies: loadRetryPolicies(),
+		parseHealth:   newParseHealth(),
+	}
+}
+
+// upstreamContext returns ctx carrying the retry policy of the given endpoint
+func (c *Client) upstreamContext(ctx context.Context, endpoint string) context.Context {
+	policy, ok := c.retryPolicies[endpoint]
+	if !ok {
+		policy = upstream.NoRetry
+	}
+	return upstream.WithRetryPolicy(ctx, policy)
+}
+
+// newCollector returns a collector authenticated as the cookie owner,
+// bound to ctx and to the retry policy of the given endpoint
+func (c *Client) newCollector(ctx context.Context, endpoint, cookie string) *colly.Collector {
+	// Pre-build cookie string once
+	cookieStr := "MOD_AUTH_CAS=" + cookie
+
+	collector := colly.NewCollector()
+	collector.WithTransport(upstream.BindContext(c.upstreamContext(ctx, endpoint), c.httpClient.Transport))
+
+	collector.OnRequest(func(r *colly.Request) {
+		r.Headers.Set("Cookie", cookieStr)
+		r.Headers.Set("User-Agent", cuid.New())
+	})
+
+	return collector
+}
+
+// sessions lists the sessions in the dropdown of the given page,
+// without the placeholder entries i-Ma'luum adds
+func (c *Client) sessions(ctx context.Context, endpoint, page, cookie string) ([]Session, error) {
+	var (
+		sessionQueries []string
+		sessionNames   []string
+	)
+
+	collector := c.newCollector(ctx, endpoint, cookie)
+
+	collector.OnHTML(".box.box-primary .box-header.with-border .dropdown ul.dropdown-menu", func(e *colly.HTMLElement) {
+		sessionQueries = e.ChildAttrs("li[style*='font-size:16px'] a", "href")
+		sessionNames = e.ChildTexts("li[style*='font-size:16px'] a")
+	})
+
+	if err := collector.Visit(page); err != nil {
+		return nil, errors.WrapUpstream(err, errors.Wrap(errors.ErrFailedToGoToURL, err))
+	}
+
+	// Filter out unwanted sessions with pre-allocated slices
+	sessions := make([]Session, 0, len(sessionQueries))
+
+	for i := range sessionQueries {
+		if i >= len(sessionNames) {
+			break
+		}
+		if !slices.Contains(UnwantedSessionQueries[:], sessionQueries[i]) {
+			sessions = append(sessions, Session{
+				Name:  sessionNames[i],
+				Query: sessionQueries[i],
+			})
+		}
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,113 @@
+package imaluum
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client scraping a fresh fake i-Ma'luum, and a logged in cookie
+func newTestClient(t *testing.T) (*Client, *fakeimaluum.Server, string) {
+	t.Helper()
+
+	fake := fakeimaluum.New()
+	t.Cleanup(fake.Close)
+
+	client := New(&http.Client{Transport: http.DefaultTransport}, fake.Upstream(), logger.New())
+
+	return client, fake, fake.Login(fakeimaluum.Username)
+}
+
+func TestSessions(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+
+	sessions, err := client.Sessions(context.Background(), cookie)
+	require.NoError(t, err)
+
+	// The placeholder session is filtered out
+	assert.Equal(t, []Session{
+		{Name: "Sem 1, 2024/2025", Query: "?ses=2024/2025&sem=1"},
+		{Name: "Sem 2, 2023/2024", Query: "?ses=2023/2024&sem=2"},
+	}, sessions)
+}
+
+func TestScheduleWithoutSession(t *testing.T) {
+	client, _, _ := newTestClient(t)
+
+	// i-Ma'luum redirects to the CAS login page, which has no sessions
+	_, err := client.Schedule(context.Background(), "expired-cookie")
+	assert.ErrorIs(t, err, errors.ErrScheduleIsEmpty)
+}
+
+func TestDownloads(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+
+	tests := []struct {
+		download func(context.Context, string) (io.ReadCloser, error)
+		name     string
+		fixture  string
+	}{
+		{name: "exam slip", download: client.ExamSlip, fixture: "exam-slip.pdf"},
+		{name: "study plan", download: client.StudyPlan, fixture: "study-plan.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.download(context.Background(), cookie)
+			require.NoError(t, err)
+			defer body.Close()
+
+			content, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, fakeimaluum.Fixture(tt.fixture), content)
+		})
+	}
+}
+
+func TestScheduleProgress(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+
+	var events []dtos.SyncProgress
+	ctx := WithProgress(context.Background(), func(event dtos.SyncProgress) {
+		events = append(events, event)
+	})
+
+	_, err := client.Schedule(ctx, cookie)
+	require.NoError(t, err)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, dtos.SyncProgress{Stage: StageSessions, Message: "Fetched session list", Total: 2}, events[0])
+	for i, event := range events[1:] {
+		assert.Equal(t, StageSession, event.Stage)
+		assert.Equal(t, i+1, event.Done)
+		assert.Equal(t, 2, event.Total)
+		assert.NotEmpty(t, event.Session)
+	}
+	assert.Equal(t, "Session 2/2 parsed", events[2].Message)
+}
+
+func TestResultsStopWhenCancelled(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stages []string
+	ctx = WithProgress(ctx, func(event dtos.SyncProgress) {
+		stages = append(stages, event.Stage)
+		// The caller goes away once the session list is in
+		cancel()
+	})
+
+	_, err := client.Results(ctx, cookie)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{StageSessions}, stages)
+}
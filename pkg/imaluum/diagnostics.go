@@ -0,0 +1,218 @@
+package imaluum
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/nrmnqdds/gomaluum/internal/constants"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// driftThreshold is how many empty pages in a row, after an endpoint has
+// parsed rows before, mark it as drifting. A page whose table is there but
+// holds no rows, such as a semester without a timetable yet, is not counted.
+const driftThreshold = 3
+
+// Diagnostics collects the rows skipped while serving a single request.
+// Attach it with WithDiagnostics, a nil *Diagnostics discards everything.
+type Diagnostics struct {
+	warnings []dtos.ParseWarning
+	mu       sync.Mutex
+}
+
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{}
+}
+
+type diagnosticsKey struct{}
+
+// WithDiagnostics makes the scrapes run with ctx report skipped rows to d
+func WithDiagnostics(ctx context.Context, d *Diagnostics) context.Context {
+	return context.WithValue(ctx, diagnosticsKey{}, d)
+}
+
+func diagnosticsFrom(ctx context.Context) *Diagnostics {
+	d, _ := ctx.Value(diagnosticsKey{}).(*Diagnostics)
+	return d
+}
+
+func (d *Diagnostics) add(warnings ...dtos.ParseWarning) {
+	if d == nil || len(warnings) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	d.warnings = append(d.warnings, warnings...)
+	d.mu.Unlock()
+}
+
+// Warnings returns the rows skipped so far
+func (d *Diagnostics) Warnings() []dtos.ParseWarning {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]dtos.ParseWarning(nil), d.warnings...)
+}
+
+// EndpointHealth counts how well the pages of one endpoint have parsed
+type EndpointHealth struct {
+	LastRowsAt time.Time `json:"last_rows_at,omitzero"`
+	Pages      uint64    `json:"pages"`
+	Rows       uint64    `json:"rows"`
+	SkipRows   uint64    `json:"skipped_rows"`
+	EmptyPages uint64    `json:"empty_pages"`
+	// EmptyAfterRows counts pages that yielded zero rows although the endpoint parsed rows before
+	EmptyAfterRows   uint64 `json:"empty_after_rows"`
+	ConsecutiveEmpty uint64 `json:"consecutive_empty"`
+	// Drifting is set once driftThreshold pages in a row came back without
+	// their table or with only skipped rows after the endpoint parsed rows
+	// before, and cleared by the next page with rows
+	Drifting bool `json:"drifting"`
+}
+
+// ParseHealth keeps per-endpoint parse counters for the lifetime of a Client
+type ParseHealth struct {
+	endpoints map[string]*EndpointHealth
+	mu        sync.Mutex
+}
+
+func newParseHealth() *ParseHealth {
+	return &ParseHealth{
+		endpoints: make(map[string]*EndpointHealth),
+	}
+}
+
+// record counts a page. hasTable reports whether the page held its table at all.
+func (h *ParseHealth) record(endpoint string, rows, skipped int, hasTable bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health, ok := h.endpoints[endpoint]
+	if !ok {
+		health = &EndpointHealth{}
+		h.endpoints[endpoint] = health
+	}
+
+	health.Pages++
+	health.Rows += uint64(rows)
+	health.SkipRows += uint64(skipped)
+
+	if rows > 0 {
+		health.LastRowsAt = time.Now()
+		health.ConsecutiveEmpty = 0
+		health.Drifting = false
+		return
+	}
+
+	health.EmptyPages++
+	// An empty table with nothing skipped is a session without data, which
+	// says nothing about the markup
+	if health.LastRowsAt.IsZero() || (hasTable && skipped == 0) {
+		return
+	}
+
+	health.EmptyAfterRows++
+	health.ConsecutiveEmpty++
+	if health.ConsecutiveEmpty >= driftThreshold {
+		health.Drifting = true
+	}
+}
+
+// Snapshot returns a copy of the counters of every endpoint scraped so far
+func (h *ParseHealth) Snapshot() map[string]EndpointHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot := make(map[string]EndpointHealth, len(h.endpoints))
+	for endpoint, health := range h.endpoints {
+		snapshot[endpoint] = *health
+	}
+	return snapshot
+}
+
+// Drifting lists the endpoints whose pages stopped yielding rows
+func (h *ParseHealth) Drifting() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var drifting []string
+	for endpoint, health := range h.endpoints {
+		if health.Drifting {
+			drifting = append(drifting, endpoint)
+		}
+	}
+	return drifting
+}
+
+// ParseHealth returns the parse counters of the client
+func (c *Client) ParseHealth() *ParseHealth {
+	return c.parseHealth
+}
+
+// tablePage tracks the rows of a single scraped table page
+type tablePage struct {
+	endpoint string
+	session  string
+	skipped  []dtos.ParseWarning
+	// hasTable is set once the page's table was found
+	hasTable bool
+	// loggedOut is set when i-Ma'luum bounced the request to the CAS login page,
+	// in which case the page says nothing about its markup
+	loggedOut bool
+}
+
+func (c *Client) newTablePage(collector *colly.Collector, endpoint, session, table string) *tablePage {
+	page := &tablePage{
+		endpoint: endpoint,
+		session:  session,
+	}
+
+	collector.OnResponse(func(r *colly.Response) {
+		u := r.Request.URL
+		page.loggedOut = u.Path == constants.CasLoginPath && strings.HasPrefix(u.String(), c.urls.CASBaseURL)
+	})
+	collector.OnHTML(table, func(*colly.HTMLElement) {
+		page.hasTable = true
+	})
+
+	return page
+}
+
+// row records the outcome of parsing a single row
+func (p *tablePage) row(cells int, skipped error) {
+	if skipped == nil {
+		return
+	}
+
+	p.skipped = append(p.skipped, dtos.ParseWarning{
+		Endpoint: p.endpoint,
+		Session:  p.session,
+		Reason:   skipped.Error(),
+		Cells:    cells,
+	})
+}
+
+// finishTablePage reports the page to the request diagnostics and the client parse health
+func (c *Client) finishTablePage(ctx context.Context, page *tablePage, rows int) {
+	if page.loggedOut {
+		return
+	}
+
+	for _, warning := range page.skipped {
+		c.log.Sugar().Warnf("Skipped %s row (%s): %s", warning.Endpoint, warning.Session, warning.Reason)
+	}
+
+	diagnosticsFrom(ctx).add(page.skipped...)
+	c.parseHealth.record(page.endpoint, rows, len(page.skipped), page.hasTable)
+
+	if rows == 0 && (!page.hasTable || len(page.skipped) > 0) {
+		c.log.Sugar().Warnf("No rows parsed from %s page (%s)", page.endpoint, page.session)
+	}
+}
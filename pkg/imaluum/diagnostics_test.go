@@ -0,0 +1,108 @@
+package imaluum
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/constants"
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHealthDrift(t *testing.T) {
+	health := newParseHealth()
+
+	// Empty pages before any rows are not drift, the student may have no data yet
+	for range driftThreshold {
+		health.record(endpointResult, 0, 0, false)
+	}
+	assert.Empty(t, health.Drifting())
+
+	health.record(endpointResult, 3, 0, true)
+	// Sessions without data, such as a semester with no timetable yet
+	for range driftThreshold {
+		health.record(endpointResult, 0, 0, true)
+	}
+	assert.Empty(t, health.Drifting())
+
+	for range driftThreshold - 1 {
+		health.record(endpointResult, 0, 2, true)
+	}
+	assert.Empty(t, health.Drifting())
+
+	health.record(endpointResult, 0, 2, true)
+	assert.Equal(t, []string{endpointResult}, health.Drifting())
+
+	snapshot := health.Snapshot()[endpointResult]
+	assert.Equal(t, uint64(3*driftThreshold+1), snapshot.Pages)
+	assert.Equal(t, uint64(driftThreshold), snapshot.EmptyAfterRows)
+	assert.Equal(t, uint64(2*driftThreshold), snapshot.SkipRows)
+
+	health.record(endpointResult, 1, 0, true)
+	assert.Empty(t, health.Drifting())
+}
+
+func TestDiagnostics(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+
+	diagnostics := NewDiagnostics()
+	_, err := client.Schedule(WithDiagnostics(context.Background(), diagnostics), cookie)
+	require.NoError(t, err)
+
+	// The fixtures parse cleanly
+	assert.Empty(t, diagnostics.Warnings())
+
+	snapshot := client.ParseHealth().Snapshot()[endpointSchedule]
+	assert.Equal(t, uint64(2), snapshot.Pages)
+	assert.Equal(t, uint64(8), snapshot.Rows)
+}
+
+func TestEmptyTableIsNotDrift(t *testing.T) {
+	fake := fakeimaluum.New()
+	t.Cleanup(fake.Close)
+
+	target, err := url.Parse(fake.URL)
+	require.NoError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	// The starpoint page served in place of the fixture, if any
+	var override atomic.Value
+	override.Store("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if page := override.Load().(string); page != "" && r.URL.Path == constants.ImaluumStarpointPath {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(page))
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	urls := fake.Upstream()
+	urls.ImaluumBaseURL = server.URL
+	client := New(&http.Client{Transport: http.DefaultTransport}, urls, logger.New())
+	cookie := fake.Login(fakeimaluum.Username)
+
+	_, err = client.Starpoint(context.Background(), cookie)
+	require.NoError(t, err)
+
+	override.Store(`<html><body><table class="table table-hover"><tbody></tbody></table></body></html>`)
+	for range driftThreshold {
+		_, _ = client.Starpoint(context.Background(), cookie)
+	}
+	assert.Empty(t, client.ParseHealth().Drifting())
+
+	// Without its table the page did change
+	override.Store("<html><body></body></html>")
+	for range driftThreshold {
+		_, _ = client.Starpoint(context.Background(), cookie)
+	}
+	assert.Equal(t, []string{endpointStarpoint}, client.ParseHealth().Drifting())
+}
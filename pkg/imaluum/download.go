@@ -0,0 +1,43 @@
+package imaluum
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+)
+
+// ExamSlip streams the exam slip PDF. The caller must close the returned body.
+func (c *Client) ExamSlip(ctx context.Context, cookie string) (io.ReadCloser, error) {
+	return c.download(ctx, endpointExamSlip, c.urls.ImaluumExamSlipPage(), cookie)
+}
+
+// StudyPlan streams the study plan PDF. The caller must close the returned body.
+func (c *Client) StudyPlan(ctx context.Context, cookie string) (io.ReadCloser, error) {
+	return c.download(ctx, endpointStudyPlan, c.urls.ImaluumStudyPlanPage(), cookie)
+}
+
+func (c *Client) download(ctx context.Context, endpoint, page, cookie string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(c.upstreamContext(ctx, endpoint), "GET", page, nil)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrURLParseFailed, err)
+	}
+
+	setHeadersWithCookie(req, cookie)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.WrapUpstream(err, errors.Wrap(errors.ErrURLParseFailed, err))
+	}
+
+	return resp.Body, nil
+}
+
+// Function to set headers for a request.
+func setHeadersWithCookie(req *http.Request, cookie string) {
+	req.Header.Set("Connection", "Keep-Alive")
+	req.Header.Set("Accept-Language", "en-US")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("Cookie", "MOD_AUTH_CAS="+cookie)
+}
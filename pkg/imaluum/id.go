@@ -0,0 +1,113 @@
+package imaluum
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// IDs stay the same across scrapes so clients can key lists and follow an
+// item from one refresh to the next. Every ID reads
+//
+//	gomaluum:<kind>:<hash>
+//
+// where hash is the first 20 hex digits of the SHA-256 of the kind, the
+// owner's matric number and the fields that identify the item, each
+// terminated by a NUL byte:
+//
+//	schedule   session query
+//	subject    session query, course code, section and the day, start and
+//	           end of every slot of a schedule row
+//	result     session query
+//	subject    session query and course code of a result row
+//	starpoint  nothing else, there is one per student
+//	program    session, semester, event name, type and level
+//
+// Ads belong to no student and hash their link alone.
+//
+// Fields that may be corrected in place, such as venues, lecturers and
+// grades, are left out so the ID survives the correction. Items identical in
+// every field get #2, #3 and so on appended in page order.
+const (
+	idKindSchedule  = "schedule"
+	idKindSubject   = "subject"
+	idKindResult    = "result"
+	idKindStarpoint = "starpoint"
+	idKindProgram   = "program"
+)
+
+type matricNoKey struct{}
+
+// WithMatricNo names the student scraped with ctx, whose matric number scopes
+// the IDs of everything scraped so two students never share one
+func WithMatricNo(ctx context.Context, matricNo string) context.Context {
+	return context.WithValue(ctx, matricNoKey{}, matricNo)
+}
+
+// StableID returns the ID of an item of kind identified by parts, outside of
+// any student
+func StableID(kind string, parts ...string) string {
+	sum := sha256.New()
+	for _, part := range append([]string{kind}, parts...) {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	return "gomaluum:" + kind + ":" + hex.EncodeToString(sum.Sum(nil))[:20]
+}
+
+// idMinter hands out the IDs of one scrape, numbering repeats
+type idMinter struct {
+	owner string
+	seen  map[string]int
+}
+
+func newIDMinter(ctx context.Context) *idMinter {
+	owner, _ := ctx.Value(matricNoKey{}).(string)
+	return &idMinter{owner: owner, seen: make(map[string]int)}
+}
+
+func (m *idMinter) id(kind string, parts ...string) string {
+	id := StableID(kind, append([]string{m.owner}, parts...)...)
+
+	m.seen[id]++
+	if n := m.seen[id]; n > 1 {
+		id += "#" + strconv.Itoa(n)
+	}
+	return id
+}
+
+func (m *idMinter) schedule(schedule *dtos.ScheduleResponse) {
+	schedule.ID = m.id(idKindSchedule, schedule.SessionQuery)
+
+	for i := range schedule.Schedule {
+		subject := &schedule.Schedule[i]
+
+		parts := []string{schedule.SessionQuery, subject.CourseCode, strconv.FormatUint(uint64(subject.Section), 10)}
+		for _, slot := range subject.Timestamps {
+			parts = append(parts, strings.Join([]string{strconv.Itoa(int(slot.Day)), slot.Start, slot.End}, " "))
+		}
+		subject.ID = m.id(idKindSubject, parts...)
+	}
+}
+
+func (m *idMinter) result(result *dtos.ResultResponse) {
+	result.ID = m.id(idKindResult, result.SessionQuery)
+
+	for i := range result.Result {
+		course := &result.Result[i]
+		course.ID = m.id(idKindSubject, result.SessionQuery, course.CourseCode)
+	}
+}
+
+func (m *idMinter) starpoint(starpoint *dtos.Starpoint) {
+	starpoint.ID = m.id(idKindStarpoint)
+
+	for i := range starpoint.Programs {
+		program := &starpoint.Programs[i]
+		program.ID = m.id(idKindProgram, program.Session, strconv.Itoa(int(program.Semester)), program.EventName, program.Type, program.Level)
+	}
+}
@@ -0,0 +1,82 @@
+package imaluum
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStableID(t *testing.T) {
+	id := StableID("subject", "2110000", "CSCI 4311")
+	assert.Equal(t, id, StableID("subject", "2110000", "CSCI 4311"))
+	assert.True(t, strings.HasPrefix(id, "gomaluum:subject:"))
+	assert.Len(t, strings.TrimPrefix(id, "gomaluum:subject:"), 20)
+
+	// Every part counts, and parts do not run into each other
+	assert.NotEqual(t, id, StableID("subject", "2110001", "CSCI 4311"))
+	assert.NotEqual(t, id, StableID("result", "2110000", "CSCI 4311"))
+	assert.NotEqual(t, StableID("subject", "ab", "c"), StableID("subject", "a", "bc"))
+}
+
+func TestIDMinter(t *testing.T) {
+	mint := func(owner string) dtos.ResultResponse {
+		result := dtos.ResultResponse{
+			SessionQuery: "?ses=2024/2025&sem=1",
+			Result: []dtos.Result{
+				{CourseCode: "CSCI 4311", CourseGrade: "A"},
+				{CourseCode: "INFO 3305"},
+				{CourseCode: "INFO 3305"},
+			},
+		}
+		newIDMinter(WithMatricNo(context.Background(), owner)).result(&result)
+		return result
+	}
+
+	result := mint("2110000")
+	assert.True(t, strings.HasPrefix(result.ID, "gomaluum:result:"))
+	assert.Equal(t, result.Result[1].ID+"#2", result.Result[2].ID)
+
+	// Grades are corrected in place and leave the ID alone
+	regraded := mint("2110000")
+	assert.Equal(t, result, regraded)
+
+	other := mint("2110001")
+	assert.NotEqual(t, result.ID, other.ID)
+	assert.NotEqual(t, result.Result[0].ID, other.Result[0].ID)
+}
+
+func TestScrapedIDsAreStable(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+	ctx := WithMatricNo(context.Background(), "2110000")
+
+	scrape := func() ([]dtos.ScheduleResponse, []dtos.ResultResponse, *dtos.Starpoint) {
+		schedules, err := client.Schedule(ctx, cookie)
+		require.NoError(t, err)
+		results, err := client.Results(ctx, cookie)
+		require.NoError(t, err)
+		starpoint, err := client.Starpoint(ctx, cookie)
+		require.NoError(t, err)
+		return schedules, results, starpoint
+	}
+
+	schedules, results, starpoint := scrape()
+	againSchedules, againResults, againStarpoint := scrape()
+
+	assert.Equal(t, schedules, againSchedules)
+	assert.Equal(t, results, againResults)
+	assert.Equal(t, starpoint, againStarpoint)
+
+	// The merged row of a subject is told apart by its slots
+	require.Len(t, schedules[0].Schedule, 4)
+	assert.NotEqual(t, schedules[0].Schedule[1].ID, schedules[0].Schedule[2].ID)
+	assert.NotEqual(t, schedules[0].ID, schedules[1].ID)
+	for _, subject := range schedules[0].Schedule {
+		assert.True(t, strings.HasPrefix(subject.ID, "gomaluum:subject:"), subject.ID)
+	}
+	assert.True(t, strings.HasPrefix(starpoint.ID, "gomaluum:starpoint:"))
+	assert.True(t, strings.HasPrefix(starpoint.Programs[0].ID, "gomaluum:program:"))
+}
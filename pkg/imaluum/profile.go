@@ -1,12 +1,11 @@
-package server
+package imaluum
 
 import (
+	"context"
 	"strings"
 	"sync"
 
 	"github.com/gocolly/colly/v2"
-	"github.com/lucsky/cuid"
-	"github.com/nrmnqdds/gomaluum/internal/constants"
 	"github.com/nrmnqdds/gomaluum/internal/dtos"
 	"github.com/nrmnqdds/gomaluum/internal/errors"
 )
@@ -97,8 +96,7 @@ func formatAddress(address string) string {
 }
 
 // Build image URL efficiently
-func buildImageURL(matricNo string) string {
-	const baseURL = "https://smartcard.iium.edu.my/packages/card/printing/camera/uploads/original/"
+func buildImageURL(baseURL, matricNo string) string {
 	const extension = ".jpeg"
 
 	// Pre-calculate capacity to avoid reallocation
@@ -138,23 +136,13 @@ func extractProfileData(e *colly.HTMLElement) *profileData {
 	return data
 }
 
-func (s *Server) Profile(cookie string) (*dtos.Profile, error) {
-	logger := s.log.GetLogger()
-
-	// Pre-build cookie string
-	cookieStr := "MOD_AUTH_CAS=" + cookie
-
-	c := colly.NewCollector()
-	c.WithTransport(s.httpClient.Transport)
+// Profile scrapes the student's profile page
+func (c *Client) Profile(ctx context.Context, cookie string) (*dtos.Profile, error) {
+	collector := c.newCollector(ctx, endpointProfile, cookie)
 
 	var profileResult *dtos.Profile
 
-	c.OnRequest(func(r *colly.Request) {
-		r.Headers.Set("Cookie", cookieStr)
-		r.Headers.Set("User-Agent", cuid.New())
-	})
-
-	c.OnHTML("body", func(e *colly.HTMLElement) {
+	collector.OnHTML("body", func(e *colly.HTMLElement) {
 		// Extract all profile data efficiently
 		data := extractProfileData(e)
 		defer profileDataPool.Put(data)
@@ -171,17 +159,16 @@ func (s *Server) Profile(cookie string) (*dtos.Profile, error) {
 			Religion:      data.religion,
 			MaritalStatus: data.maritalStatus,
 			Address:       data.address,
-			ImageURL:      buildImageURL(data.matricNo),
+			ImageURL:      buildImageURL(c.urls.SmartcardImageBaseURL, data.matricNo),
 		}
 	})
 
-	if err := c.Visit(constants.ImaluumProfilePage); err != nil {
-		logger.Sugar().Errorf("Failed to go to URL: %v", err)
-		return nil, errors.ErrFailedToGoToURL
+	if err := collector.Visit(c.urls.ImaluumProfilePage()); err != nil {
+		return nil, errors.WrapUpstream(err, errors.Wrap(errors.ErrFailedToGoToURL, err))
 	}
 
 	if profileResult == nil {
-		logger.Sugar().Error("Failed to extract profile data")
+		c.log.Sugar().Error("Failed to extract profile data")
 		return nil, errors.ErrFailedToGoToURL
 	}
 
@@ -0,0 +1,36 @@
+package imaluum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractProfileData(t *testing.T) {
+	client, fake, cookie := newTestClient(t)
+
+	profile, err := client.Profile(context.Background(), cookie)
+	require.NoError(t, err)
+
+	assert.Equal(t, &dtos.Profile{
+		ImageURL:      fake.URL + "/smartcard/2110000.jpeg",
+		Name:          "AHMAD FULAN BIN ABDULLAH",
+		MatricNo:      "2110000",
+		Level:         "UNDERGRADUATE",
+		Kuliyyah:      "KULLIYYAH OF INFORMATION AND COMMUNICATION TECHNOLOGY",
+		IC:            "000101-01-0101",
+		Gender:        "Male",
+		Birthday:      "01-01-2000",
+		Religion:      "Islam",
+		MaritalStatus: "Single",
+		Address:       "NO 1, JALAN CONTOH 1/1, TAMAN CONTOH, 53100, GOMBAK, SELANGOR",
+	}, profile)
+}
+
+func TestFormatAddress(t *testing.T) {
+	assert.Equal(t, "", formatAddress(""))
+	assert.Equal(t, "LINE 1, LINE 2", formatAddress("  LINE   1 \n\t\n LINE\t2  "))
+}
@@ -0,0 +1,52 @@
+package imaluum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// Stages of the progress reported by Schedule and Results
+const (
+	// StageSessions is reported once the session dropdown has been read
+	StageSessions = "sessions"
+	// StageSession is reported as each session is parsed
+	StageSession = "session"
+)
+
+// Progress is told how far the scrapes run with a context have come. It is
+// called from the goroutine running the scrape.
+type Progress func(dtos.SyncProgress)
+
+type progressKey struct{}
+
+// WithProgress makes the schedule and result scrapes run with ctx report
+// their steps to progress
+func WithProgress(ctx context.Context, progress Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+func reportProgress(ctx context.Context, event dtos.SyncProgress) {
+	if progress, _ := ctx.Value(progressKey{}).(Progress); progress != nil {
+		progress(event)
+	}
+}
+
+func reportSessions(ctx context.Context, sessions []Session) {
+	reportProgress(ctx, dtos.SyncProgress{
+		Stage:   StageSessions,
+		Message: "Fetched session list",
+		Total:   len(sessions),
+	})
+}
+
+func reportSession(ctx context.Context, session string, done, total int) {
+	reportProgress(ctx, dtos.SyncProgress{
+		Stage:   StageSession,
+		Message: fmt.Sprintf("Session %d/%d parsed", done, total),
+		Session: session,
+		Done:    done,
+		Total:   total,
+	})
+}
@@ -0,0 +1,74 @@
+package imaluum
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/fakeimaluum"
+	"github.com/nrmnqdds/gomaluum/pkg/logger"
+	"github.com/nrmnqdds/gomaluum/pkg/upstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	fake := fakeimaluum.New()
+	urls := fake.Upstream()
+	cookie := fake.Login(fakeimaluum.Username)
+
+	recorder := &http.Client{Transport: &upstream.Recorder{Base: http.DefaultTransport, Dir: dir}}
+	client := New(recorder, urls, logger.New())
+
+	resp, err := recorder.PostForm(urls.ImaluumLoginPage(), url.Values{
+		"username": {fakeimaluum.Username},
+		"password": {fakeimaluum.Password},
+	})
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	recorded, err := client.Schedule(context.Background(), cookie)
+	require.NoError(t, err)
+	_, err = client.Profile(context.Background(), cookie)
+	require.NoError(t, err)
+
+	// Nothing identifying reaches the disk
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		for _, secret := range []string{cookie, fakeimaluum.Password, fakeimaluum.Username, "000101-01-0101", "AHMAD FULAN", "JALAN CONTOH"} {
+			assert.NotContains(t, string(data), secret, path)
+		}
+	}
+
+	// Replay works with the network gone
+	fake.Close()
+
+	replayer, err := upstream.NewReplayer(dir)
+	require.NoError(t, err)
+	client = New(&http.Client{Transport: replayer}, urls, logger.New())
+
+	replayed, err := client.Schedule(context.Background(), "any-cookie")
+	require.NoError(t, err)
+	require.Len(t, replayed, len(recorded))
+	for i := range recorded {
+		assert.Equal(t, recorded[i].SessionName, replayed[i].SessionName)
+		assert.Len(t, replayed[i].Schedule, len(recorded[i].Schedule))
+	}
+
+	profile, err := client.Profile(context.Background(), "any-cookie")
+	require.NoError(t, err)
+	assert.Equal(t, "0000000", profile.MatricNo)
+	assert.Equal(t, "REDACTED", profile.Name)
+
+	_, err = client.Starpoint(context.Background(), "any-cookie")
+	assert.ErrorContains(t, err, upstream.ErrNoRecording.Error())
+}
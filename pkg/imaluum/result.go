@@ -0,0 +1,260 @@
+package imaluum
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/utils"
+)
+
+// Object pools for result processing
+var resultPool = sync.Pool{
+	New: func() any {
+		return &dtos.Result{}
+	},
+}
+
+var resultStringSlicePool = sync.Pool{
+	New: func() any {
+		return make([]string, 0, 10)
+	},
+}
+
+// Worker pool structures for results
+type resultWorkerResult struct {
+	result dtos.ResultResponse
+	err    error
+}
+
+// Parse result table row with object pooling.
+// Course rows are added to subjects and the Total row fills gpaInfo.
+// Blank spacer rows are ignored and return nil, any other row that cannot
+// be used returns why it was skipped.
+func parseResultRow(tds []string, subjects *[]dtos.Result, gpaInfo *map[string]string, mu *sync.Mutex) error {
+	if len(tds) < 4 {
+		return fmt.Errorf("unexpected %d cells, want at least 4", len(tds))
+	}
+
+	courseCode := strings.TrimSpace(tds[0])
+	courseName := strings.TrimSpace(tds[1])
+	courseGrade := strings.TrimSpace(tds[2])
+	courseCredit := strings.TrimSpace(tds[3])
+
+	words := strings.Fields(courseCode)
+	if len(words) == 0 {
+		if courseName == "" && courseGrade == "" && courseCredit == "" {
+			// Blank spacer row
+			return nil
+		}
+		return fmt.Errorf("row without a course code")
+	}
+
+	// Handle GPA information row
+	if words[0] == "Total" {
+		mu.Lock()
+		gpaWords := strings.Fields(courseName)
+
+		if len(gpaWords) > 1 {
+			(*gpaInfo)["chr"] = strings.TrimSpace(gpaWords[1])
+		}
+		if len(gpaWords) > 2 {
+			(*gpaInfo)["gpa"] = strings.TrimSpace(gpaWords[2])
+		}
+		if len(gpaWords) > 3 {
+			(*gpaInfo)["status"] = strings.TrimSpace(gpaWords[3])
+		}
+
+		cgpaWords := strings.Fields(courseCredit)
+		if len(cgpaWords) > 2 {
+			(*gpaInfo)["cgpa"] = strings.TrimSpace(cgpaWords[2])
+		}
+		mu.Unlock()
+		return nil
+	}
+
+	// Create result object
+	result := resultPool.Get().(*dtos.Result)
+	*result = dtos.Result{} // Reset
+
+	result.CourseCode = courseCode
+	result.CourseName = courseName
+	result.CourseGrade = courseGrade
+	result.CourseCredit = courseCredit
+
+	mu.Lock()
+	*subjects = append(*subjects, *result)
+	mu.Unlock()
+
+	resultPool.Put(result)
+
+	return nil
+}
+
+// Worker function for processing result sessions
+func (c *Client) resultWorker(ctx context.Context, jobs <-chan Session, results chan<- resultWorkerResult, cookie string) {
+	for job := range jobs {
+		// Skip what is left once the caller has gone
+		if err := ctx.Err(); err != nil {
+			results <- resultWorkerResult{err: errors.Wrap(errors.ErrFailedToGoToURL, err)}
+			continue
+		}
+
+		func() {
+			defer utils.CatchPanic("result worker")
+
+			collector := c.newCollector(ctx, endpointResult, cookie)
+			page := c.newTablePage(collector, endpointResult, job.Name, "table.table-hover")
+
+			var (
+				mu       sync.Mutex
+				subjects []dtos.Result
+				gpaInfo  = map[string]string{
+					"gpa":    resultPlaceholder,
+					"cgpa":   resultPlaceholder,
+					"chr":    resultPlaceholder,
+					"status": resultPlaceholder,
+				}
+			)
+
+			collector.OnHTML("table.table-hover tbody tr", func(e *colly.HTMLElement) {
+				cells := e.DOM.Find("td")
+				if cells.Length() == 0 {
+					return
+				}
+
+				tds := resultStringSlicePool.Get().([]string)
+				tds = tds[:0] // Reset slice
+
+				cells.Each(func(_ int, s *goquery.Selection) {
+					tds = append(tds, s.Text())
+				})
+
+				page.row(len(tds), parseResultRow(tds, &subjects, &gpaInfo, &mu))
+				resultStringSlicePool.Put(tds)
+			})
+
+			url := c.urls.ImaluumResultPage() + job.Query
+			if err := collector.Visit(url); err != nil {
+				results <- resultWorkerResult{
+					err: errors.WrapUpstream(err, errors.Wrap(errors.ErrFailedToGoToURL, err)),
+				}
+				return
+			}
+
+			c.finishTablePage(ctx, page, len(subjects))
+
+			response := dtos.ResultResponse{
+				SessionName:  job.Name,
+				SessionQuery: job.Query,
+				GpaValue:     gpaInfo["gpa"],
+				CgpaValue:    gpaInfo["cgpa"],
+				CreditHours:  gpaInfo["chr"],
+				Status:       gpaInfo["status"],
+				Result:       subjects,
+			}
+			newIDMinter(ctx).result(&response)
+
+			results <- resultWorkerResult{
+				result: response,
+				err:    nil,
+			}
+		}()
+	}
+}
+
+// Process results using worker pool pattern
+func (c *Client) processResultsWithWorkerPool(ctx context.Context, sessions []Session, cookie string) ([]dtos.ResultResponse, error) {
+	const maxWorkers = 5
+
+	jobs := make(chan Session, len(sessions))
+	results := make(chan resultWorkerResult, len(sessions))
+
+	// Start workers
+	for range maxWorkers {
+		go c.resultWorker(ctx, jobs, results, cookie)
+	}
+
+	// Send jobs
+	go func() {
+		defer close(jobs)
+		for _, session := range sessions {
+			jobs <- session
+		}
+	}()
+
+	// Collect results
+	var resultResponses []dtos.ResultResponse
+	var errorList []error
+
+	for done := range len(sessions) {
+		var result resultWorkerResult
+		select {
+		case result = <-results:
+		case <-ctx.Done():
+			return nil, errors.Wrap(errors.ErrFailedToGoToURL, ctx.Err())
+		}
+
+		if result.err != nil {
+			errorList = append(errorList, result.err)
+		} else {
+			resultResponses = append(resultResponses, result.result)
+			reportSession(ctx, result.result.SessionName, done+1, len(sessions))
+		}
+	}
+
+	if len(errorList) > 0 {
+		return nil, errorList[0] // Return first error
+	}
+
+	return resultResponses, nil
+}
+
+// ResultSessions lists the academic sessions available on the result page
+func (c *Client) ResultSessions(ctx context.Context, cookie string) ([]Session, error) {
+	return c.sessions(ctx, endpointResult, c.urls.ImaluumResultPage(), cookie)
+}
+
+// Results scrapes the examination results of every session, most recent session first
+func (c *Client) Results(ctx context.Context, cookie string) ([]dtos.ResultResponse, error) {
+	sessions, err := c.ResultSessions(ctx, cookie)
+	if err != nil {
+		return nil, err
+	}
+	reportSessions(ctx, sessions)
+
+	if len(sessions) == 0 {
+		c.log.Sugar().Error("No valid sessions found")
+		return nil, errors.ErrResultIsEmpty
+	}
+
+	return c.ResultsFor(ctx, cookie, sessions)
+}
+
+// ResultsFor scrapes the examination results of the given sessions only,
+// most recent session first
+func (c *Client) ResultsFor(ctx context.Context, cookie string, sessions []Session) ([]dtos.ResultResponse, error) {
+	// Use worker pool for concurrent processing
+	results, err := c.processResultsWithWorkerPool(ctx, sessions, cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		c.log.Sugar().Error("Result is empty")
+		return nil, errors.ErrResultIsEmpty
+	}
+
+	// Sort results
+	sort.Slice(results, func(i, j int) bool {
+		return utils.SortSessionNames(results[i].SessionName, results[j].SessionName)
+	})
+
+	return results, nil
+}
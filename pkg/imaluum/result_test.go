@@ -0,0 +1,93 @@
+package imaluum
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResultRow(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		subjects []dtos.Result
+		gpaInfo  = map[string]string{"gpa": "0", "cgpa": "0", "chr": "0", "status": "0"}
+	)
+
+	require.NoError(t, parseResultRow([]string{" CSCI 3300 ", "DATA STRUCTURES AND ALGORITHMS", "A", "3"}, &subjects, &gpaInfo, &mu))
+	require.NoError(t, parseResultRow([]string{"Total Credit Hours", "Chr: 8 3.65 KS", "", "CGPA Value: 3.52"}, &subjects, &gpaInfo, &mu))
+	// Blank spacer rows are not skipped rows
+	require.NoError(t, parseResultRow([]string{"  ", "", "", ""}, &subjects, &gpaInfo, &mu))
+
+	err := parseResultRow([]string{"CSCI 3300", "DATA STRUCTURES AND ALGORITHMS"}, &subjects, &gpaInfo, &mu)
+	assert.EqualError(t, err, "unexpected 2 cells, want at least 4")
+	err = parseResultRow([]string{"", "DATA STRUCTURES AND ALGORITHMS", "A", "3"}, &subjects, &gpaInfo, &mu)
+	assert.EqualError(t, err, "row without a course code")
+
+	require.Len(t, subjects, 1)
+	assert.Equal(t, "CSCI 3300", subjects[0].CourseCode)
+	assert.Equal(t, "DATA STRUCTURES AND ALGORITHMS", subjects[0].CourseName)
+	assert.Equal(t, "A", subjects[0].CourseGrade)
+	assert.Equal(t, "3", subjects[0].CourseCredit)
+
+	assert.Equal(t, map[string]string{"chr": "8", "gpa": "3.65", "status": "KS", "cgpa": "3.52"}, gpaInfo)
+}
+
+func TestResults(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+
+	results, err := client.Results(context.Background(), cookie)
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "Sem 2, 2023/2024", results[0].SessionName)
+	assert.Equal(t, "Sem 1, 2023/2024", results[1].SessionName)
+
+	result := results[0]
+	assert.Equal(t, "?ses=2023/2024&sem=2", result.SessionQuery)
+	assert.Equal(t, "3.67", result.GpaValue)
+	assert.Equal(t, "3.52", result.CgpaValue)
+	assert.Equal(t, "8", result.CreditHours)
+	assert.Equal(t, "KS", result.Status)
+	require.Len(t, result.Result, 3)
+	assert.Equal(t, "B+", result.Result[1].CourseGrade)
+}
+
+func TestResultV2(t *testing.T) {
+	result := ResultV2(dtos.ResultResponse{
+		ID:          "gomaluum:result:1",
+		SessionName: "Sem 2, 2023/2024",
+		GpaValue:    "3.65",
+		CgpaValue:   resultPlaceholder,
+		CreditHours: "8",
+		Status:      "KS",
+		Result: []dtos.Result{
+			{CourseCode: "CSCI 3300", CourseGrade: "A-", CourseCredit: "3"},
+			{CourseCode: "UNGS 2290", CourseGrade: "", CourseCredit: "2"},
+			{CourseCode: "LM 1021", CourseGrade: "P", CourseCredit: "-"},
+			{CourseCode: "CSCI 1301", CourseGrade: "?", CourseCredit: "3"},
+		},
+	})
+
+	require.NotNil(t, result.Gpa)
+	assert.InDelta(t, 3.65, *result.Gpa, 0.001)
+	assert.Nil(t, result.Cgpa)
+	require.NotNil(t, result.CreditHours)
+	assert.InDelta(t, 8.0, *result.CreditHours, 0.001)
+	require.NotNil(t, result.Status)
+	assert.Equal(t, "KS", *result.Status)
+	assert.Equal(t, dtos.ResultSummaryRaw{Gpa: "3.65", CreditHours: "8", Status: "KS"}, result.Raw)
+
+	require.Len(t, result.Result, 4)
+	assert.Equal(t, dtos.GradeAMinus, result.Result[0].Grade)
+	require.NotNil(t, result.Result[0].Credit)
+	assert.InDelta(t, 3.0, *result.Result[0].Credit, 0.001)
+	assert.Equal(t, dtos.GradePending, result.Result[1].Grade)
+	assert.Equal(t, dtos.GradePass, result.Result[2].Grade)
+	assert.Nil(t, result.Result[2].Credit)
+	assert.Equal(t, dtos.ResultRaw{Grade: "P", Credit: "-"}, result.Result[2].Raw)
+	assert.Equal(t, dtos.GradeUnknown, result.Result[3].Grade)
+}
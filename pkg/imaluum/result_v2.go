@@ -0,0 +1,110 @@
+package imaluum
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// resultPlaceholder fills the GPA fields of ResultResponse when the page has
+// no Total row. i-Ma'luum prints GPAs with two decimals, so it is never real data.
+const resultPlaceholder = "0"
+
+var grades = map[string]dtos.Grade{
+	"A":  dtos.GradeA,
+	"A-": dtos.GradeAMinus,
+	"B+": dtos.GradeBPlus,
+	"B":  dtos.GradeB,
+	"B-": dtos.GradeBMinus,
+	"C+": dtos.GradeCPlus,
+	"C":  dtos.GradeC,
+	"D":  dtos.GradeD,
+	"D-": dtos.GradeDMinus,
+	"E":  dtos.GradeE,
+	"F":  dtos.GradeF,
+	"P":  dtos.GradePass,
+	"I":  dtos.GradeIncomplete,
+	"W":  dtos.GradeWithdrawn,
+	"":   dtos.GradePending,
+	"-":  dtos.GradePending,
+}
+
+// ParseGrade maps the grade text of a result row to a Grade
+func ParseGrade(raw string) dtos.Grade {
+	if grade, ok := grades[strings.ToUpper(strings.TrimSpace(raw))]; ok {
+		return grade
+	}
+	return dtos.GradeUnknown
+}
+
+// parseNumber returns nil for text that is not a number
+func parseNumber(raw string) *float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+func parseText(raw string) *string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	return &raw
+}
+
+// unplaceholder returns the text i-Ma'luum printed, or "" if it printed nothing
+func unplaceholder(raw string) string {
+	if raw == resultPlaceholder {
+		return ""
+	}
+	return raw
+}
+
+// ResultV2 converts a scraped session result to the typed v2 shape
+func ResultV2(result dtos.ResultResponse) dtos.ResultResponseV2 {
+	raw := dtos.ResultSummaryRaw{
+		Gpa:         unplaceholder(result.GpaValue),
+		Cgpa:        unplaceholder(result.CgpaValue),
+		CreditHours: unplaceholder(result.CreditHours),
+		Status:      unplaceholder(result.Status),
+	}
+
+	courses := make([]dtos.ResultV2, 0, len(result.Result))
+	for _, course := range result.Result {
+		courses = append(courses, dtos.ResultV2{
+			ID:         course.ID,
+			CourseCode: course.CourseCode,
+			CourseName: course.CourseName,
+			Grade:      ParseGrade(course.CourseGrade),
+			Credit:     parseNumber(course.CourseCredit),
+			Raw: dtos.ResultRaw{
+				Grade:  course.CourseGrade,
+				Credit: course.CourseCredit,
+			},
+		})
+	}
+
+	return dtos.ResultResponseV2{
+		ID:           result.ID,
+		SessionName:  result.SessionName,
+		SessionQuery: result.SessionQuery,
+		Gpa:          parseNumber(raw.Gpa),
+		Cgpa:         parseNumber(raw.Cgpa),
+		CreditHours:  parseNumber(raw.CreditHours),
+		Status:       parseText(raw.Status),
+		Raw:          raw,
+		Result:       courses,
+	}
+}
+
+// ResultsV2 converts every session of Results to the typed v2 shape
+func ResultsV2(results []dtos.ResultResponse) []dtos.ResultResponseV2 {
+	converted := make([]dtos.ResultResponseV2, 0, len(results))
+	for _, result := range results {
+		converted = append(converted, ResultV2(result))
+	}
+	return converted
+}
@@ -0,0 +1,36 @@
+package imaluum
+
+import (
+	"time"
+
+	"github.com/nrmnqdds/gomaluum/pkg/upstream"
+)
+
+// i-Ma'luum endpoints with their own retry policy
+const (
+	endpointProfile   = "profile"
+	endpointSchedule  = "schedule"
+	endpointResult    = "result"
+	endpointStarpoint = "starpoint"
+	endpointExamSlip  = "exam-slip"
+	endpointStudyPlan = "study-plan"
+)
+
+// Scraped pages are cheap to refetch, PDF downloads are retried less eagerly
+var defaultRetryPolicies = map[string]upstream.RetryPolicy{
+	endpointProfile:   {MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second},
+	endpointSchedule:  {MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second},
+	endpointResult:    {MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second},
+	endpointStarpoint: {MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second},
+	endpointExamSlip:  {MaxAttempts: 2, BaseDelay: 500 * time.Millisecond, MaxDelay: 2 * time.Second},
+	endpointStudyPlan: {MaxAttempts: 2, BaseDelay: 500 * time.Millisecond, MaxDelay: 2 * time.Second},
+}
+
+// loadRetryPolicies applies the UPSTREAM_RETRY_<ENDPOINT>_* overrides to the defaults
+func loadRetryPolicies() map[string]upstream.RetryPolicy {
+	policies := make(map[string]upstream.RetryPolicy, len(defaultRetryPolicies))
+	for endpoint, policy := range defaultRetryPolicies {
+		policies[endpoint] = upstream.RetryPolicyFromEnv(endpoint, policy)
+	}
+	return policies
+}
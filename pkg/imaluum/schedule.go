@@ -0,0 +1,390 @@
+package imaluum
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+	"github.com/nrmnqdds/gomaluum/internal/constants"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/nrmnqdds/gomaluum/pkg/utils"
+	"github.com/rung/go-safecast"
+)
+
+// Pre-map day conversions for better performance
+var dayMap = map[string][]string{
+	"MTW":    {"M", "T", "W"},
+	"TWTH":   {"T", "W", "TH"},
+	"MTWTH":  {"M", "T", "W", "TH"},
+	"MTWTHF": {"M", "T", "W", "TH", "F"},
+}
+
+// Pre-compiled regex for time parsing
+var timePattern = regexp.MustCompile(`^\d{3,4}-\d{3,4}$`)
+
+// Object pools for memory reuse
+var subjectPool = sync.Pool{
+	New: func() any {
+		return &dtos.ScheduleSubject{}
+	},
+}
+
+var weekTimeSlicePool = sync.Pool{
+	New: func() any {
+		return make([]dtos.WeekTime, 0, 5)
+	},
+}
+
+var stringSlicePool = sync.Pool{
+	New: func() any {
+		return make([]string, 0, 10)
+	},
+}
+
+// Worker pool structures
+type scheduleResult struct {
+	err      error
+	schedule dtos.ScheduleResponse
+}
+
+type dayKey struct{}
+
+// WithDay makes the schedule scrapes run with ctx set start_unix and end_unix
+// on day instead of today
+func WithDay(ctx context.Context, day time.Time) context.Context {
+	return context.WithValue(ctx, dayKey{}, day)
+}
+
+func dayFrom(ctx context.Context) time.Time {
+	if day, ok := ctx.Value(dayKey{}).(time.Time); ok {
+		return day
+	}
+	return time.Now()
+}
+
+// Fast day parsing using pre-built map
+func parseDays(dayStr string) []string {
+	cleaned := strings.ReplaceAll(dayStr, " ", "")
+	if mapped, exists := dayMap[cleaned]; exists {
+		return mapped
+	}
+	return strings.Split(cleaned, "-")
+}
+
+// Normalize time format efficiently
+func normalizeTime(timeStr string, day time.Time) (string, *int64) {
+	trimmed := strings.TrimSpace(timeStr)
+
+	if len(trimmed) == 3 {
+		trimmed = fmt.Sprintf("0%s", trimmed) // Pad single-digit times
+	}
+
+	KLTimezone, err := time.LoadLocation("Asia/Kuala_Lumpur")
+	if err != nil {
+		fmt.Println("Error parsing time:", err)
+		return trimmed, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02 1504", fmt.Sprintf("%04d-%02d-%02d %s", day.Year(), day.Month(), day.Day(), trimmed), KLTimezone)
+	if err != nil {
+		fmt.Println("Error parsing time:", err)
+		return trimmed, nil
+	}
+
+	unixTimestamp := t.Unix()
+
+	return trimmed, &unixTimestamp
+}
+
+// Parse table row with object pooling.
+// A 9 cell row adds a subject and a 4 cell row adds another class of the
+// subject before it. Rows without cells are ignored and return nil, any
+// other row that cannot be used returns why it was skipped.
+func parseTableRow(tds []string, day time.Time, subjects *[]dtos.ScheduleSubject, mu *sync.Mutex) error {
+	if len(tds) == 0 {
+		return nil
+	}
+
+	weekTimeSlice := weekTimeSlicePool.Get().([]dtos.WeekTime)
+	weekTimeSlice = weekTimeSlice[:0] // Reset slice
+
+	var subject *dtos.ScheduleSubject
+
+	// Handle perfect cell (9 columns)
+	if len(tds) == 9 {
+		subject = subjectPool.Get().(*dtos.ScheduleSubject)
+		*subject = dtos.ScheduleSubject{} // Reset
+
+		subject.CourseCode = strings.TrimSpace(tds[0])
+		subject.CourseName = strings.TrimSpace(tds[1])
+
+		section, err := safecast.Atoi32(strings.TrimSpace(tds[2]))
+		if err != nil {
+			subjectPool.Put(subject)
+			weekTimeSlicePool.Put(weekTimeSlice)
+			return fmt.Errorf("section %q is not a number", strings.TrimSpace(tds[2]))
+		}
+		subject.Section = uint32(section)
+
+		chr, err := strconv.ParseFloat(strings.TrimSpace(tds[3]), 32)
+		if err != nil {
+			subjectPool.Put(subject)
+			weekTimeSlicePool.Put(weekTimeSlice)
+			return fmt.Errorf("credit hour %q is not a number", strings.TrimSpace(tds[3]))
+		}
+		subject.Chr = chr
+
+		// Parse days and times
+		days := parseDays(strings.TrimSpace(tds[5]))
+		timeFullForm := strings.ReplaceAll(strings.TrimSpace(tds[6]), " ", "")
+
+		if timeFullForm != constants.TimeSeparator && timePattern.MatchString(timeFullForm) {
+			timeParts := strings.Split(timeFullForm, constants.TimeSeparator)
+			if len(timeParts) == 2 {
+				start, startUnix := normalizeTime(timeParts[0], day)
+				end, endUnix := normalizeTime(timeParts[1], day)
+
+				for _, day := range days {
+					dayNum := utils.GetScheduleDays(day)
+					weekTimeSlice = append(weekTimeSlice, dtos.WeekTime{
+						Start:     start,
+						StartUnix: *startUnix,
+						End:       end,
+						EndUnix:   *endUnix,
+						Day:       dayNum,
+					})
+				}
+			}
+		}
+
+		subject.Venue = strings.TrimSpace(tds[7])
+		subject.Lecturer = strings.TrimSpace(tds[8])
+	}
+
+	// Handle merged cell (4 columns)
+	if len(tds) == 4 {
+		mu.Lock()
+		if len(*subjects) == 0 {
+			mu.Unlock()
+			weekTimeSlicePool.Put(weekTimeSlice)
+			return fmt.Errorf("merged row without a subject before it")
+		}
+		lastSubject := (*subjects)[len(*subjects)-1]
+		mu.Unlock()
+
+		subject = subjectPool.Get().(*dtos.ScheduleSubject)
+		*subject = dtos.ScheduleSubject{} // Reset
+
+		subject.CourseCode = lastSubject.CourseCode
+		subject.CourseName = lastSubject.CourseName
+		subject.Section = lastSubject.Section
+		subject.Chr = lastSubject.Chr
+
+		// Parse days and times
+		days := parseDays(strings.TrimSpace(tds[0]))
+		timeFullForm := strings.ReplaceAll(strings.TrimSpace(tds[1]), " ", "")
+
+		if timePattern.MatchString(timeFullForm) {
+			timeParts := strings.Split(timeFullForm, "-")
+			if len(timeParts) == 2 {
+				start, startUnix := normalizeTime(timeParts[0], day)
+				end, endUnix := normalizeTime(timeParts[1], day)
+
+				for _, day := range days {
+					dayNum := utils.GetScheduleDays(day)
+					weekTimeSlice = append(weekTimeSlice, dtos.WeekTime{
+						Start:     start,
+						StartUnix: *startUnix,
+						End:       end,
+						EndUnix:   *endUnix,
+						Day:       dayNum,
+					})
+				}
+			}
+		}
+
+		subject.Venue = strings.TrimSpace(tds[2])
+		subject.Lecturer = strings.TrimSpace(tds[3])
+	}
+
+	if subject != nil {
+		// Copy weekTime slice to avoid pool contamination
+		subject.Timestamps = make([]dtos.WeekTime, len(weekTimeSlice))
+		copy(subject.Timestamps, weekTimeSlice)
+
+		mu.Lock()
+		*subjects = append(*subjects, *subject)
+		mu.Unlock()
+
+		subjectPool.Put(subject)
+	}
+
+	weekTimeSlicePool.Put(weekTimeSlice)
+
+	if subject == nil {
+		return fmt.Errorf("unexpected %d cells, want 9 or 4", len(tds))
+	}
+	return nil
+}
+
+// Worker function for processing schedule sessions
+func (c *Client) scheduleWorker(ctx context.Context, jobs <-chan Session, results chan<- scheduleResult, cookie string) {
+	for job := range jobs {
+		// Skip what is left once the caller has gone
+		if err := ctx.Err(); err != nil {
+			results <- scheduleResult{err: errors.Wrap(errors.ErrFailedToGoToURL, err)}
+			continue
+		}
+
+		func() {
+			defer utils.CatchPanic("schedule worker")
+
+			collector := c.newCollector(ctx, endpointSchedule, cookie)
+			page := c.newTablePage(collector, endpointSchedule, job.Name, "table.table-hover")
+
+			var (
+				mu       sync.Mutex
+				subjects []dtos.ScheduleSubject
+				day      = dayFrom(ctx)
+			)
+
+			collector.OnHTML("table.table-hover tbody tr", func(e *colly.HTMLElement) {
+				// Get all text at once with efficient DOM traversal
+				cells := e.DOM.Find("td")
+				if cells.Length() == 0 {
+					return
+				}
+
+				tds := stringSlicePool.Get().([]string)
+				tds = tds[:0] // Reset slice
+
+				cells.Each(func(_ int, s *goquery.Selection) {
+					tds = append(tds, s.Text())
+				})
+
+				page.row(len(tds), parseTableRow(tds, day, &subjects, &mu))
+				stringSlicePool.Put(tds)
+			})
+
+			url := c.urls.ImaluumSchedulePage() + job.Query
+			if err := collector.Visit(url); err != nil {
+				results <- scheduleResult{
+					err: errors.WrapUpstream(err, errors.Wrap(errors.ErrFailedToGoToURL, err)),
+				}
+				return
+			}
+
+			c.finishTablePage(ctx, page, len(subjects))
+
+			response := dtos.ScheduleResponse{
+				SessionName:  job.Name,
+				SessionQuery: job.Query,
+				Schedule:     subjects,
+			}
+			newIDMinter(ctx).schedule(&response)
+
+			results <- scheduleResult{
+				schedule: response,
+				err:      nil,
+			}
+		}()
+	}
+}
+
+// Process schedules using worker pool pattern
+func (c *Client) processSchedulesWithWorkerPool(ctx context.Context, sessions []Session, cookie string) ([]dtos.ScheduleResponse, error) {
+	const maxWorkers = 5
+
+	jobs := make(chan Session, len(sessions))
+	results := make(chan scheduleResult, len(sessions))
+
+	// Start workers
+	for range maxWorkers {
+		go c.scheduleWorker(ctx, jobs, results, cookie)
+	}
+
+	// Send jobs
+	go func() {
+		defer close(jobs)
+		for _, session := range sessions {
+			jobs <- session
+		}
+	}()
+
+	// Collect results
+	var schedules []dtos.ScheduleResponse
+	var errorList []error
+
+	for done := range len(sessions) {
+		var result scheduleResult
+		select {
+		case result = <-results:
+		case <-ctx.Done():
+			return nil, errors.Wrap(errors.ErrFailedToGoToURL, ctx.Err())
+		}
+
+		if result.err != nil {
+			errorList = append(errorList, result.err)
+		} else {
+			schedules = append(schedules, result.schedule)
+			reportSession(ctx, result.schedule.SessionName, done+1, len(sessions))
+		}
+	}
+
+	if len(errorList) > 0 {
+		return nil, errorList[0] // Return first error
+	}
+
+	return schedules, nil
+}
+
+// Sessions lists the academic sessions available on the schedule page
+func (c *Client) Sessions(ctx context.Context, cookie string) ([]Session, error) {
+	return c.sessions(ctx, endpointSchedule, c.urls.ImaluumSchedulePage(), cookie)
+}
+
+// Schedule scrapes the timetable of every session, most recent session first
+func (c *Client) Schedule(ctx context.Context, cookie string) ([]dtos.ScheduleResponse, error) {
+	sessions, err := c.Sessions(ctx, cookie)
+	if err != nil {
+		return nil, err
+	}
+	reportSessions(ctx, sessions)
+
+	if len(sessions) == 0 {
+		c.log.Sugar().Error("No valid sessions found")
+		return nil, errors.ErrScheduleIsEmpty
+	}
+
+	return c.ScheduleFor(ctx, cookie, sessions)
+}
+
+// ScheduleFor scrapes the timetable of the given sessions only, most recent
+// session first
+func (c *Client) ScheduleFor(ctx context.Context, cookie string, sessions []Session) ([]dtos.ScheduleResponse, error) {
+	// Use worker pool for concurrent processing
+	schedules, err := c.processSchedulesWithWorkerPool(ctx, sessions, cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(schedules) == 0 {
+		c.log.Sugar().Error("Schedule is empty")
+		return nil, errors.ErrScheduleIsEmpty
+	}
+
+	// Sort schedules
+	sort.Slice(schedules, func(i, j int) bool {
+		return utils.SortSessionNames(schedules[i].SessionName, schedules[j].SessionName)
+	})
+
+	return schedules, nil
+}
@@ -0,0 +1,108 @@
+package imaluum
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTableRow(t *testing.T) {
+	t.Run("full row", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			subjects []dtos.ScheduleSubject
+		)
+
+		require.NoError(t, parseTableRow([]string{" CSCI 4311 ", "COMPUTER ARCHITECTURE", "1", "3", "Registered", "M - W", "830 - 950", "ICT LR 1", "DR. LECTURER ONE"}, time.Now(), &subjects, &mu))
+
+		require.Len(t, subjects, 1)
+		subject := subjects[0]
+		assert.Equal(t, "CSCI 4311", subject.CourseCode)
+		assert.Equal(t, "COMPUTER ARCHITECTURE", subject.CourseName)
+		assert.Equal(t, uint32(1), subject.Section)
+		assert.InDelta(t, 3.0, subject.Chr, 0.001)
+		assert.Equal(t, "ICT LR 1", subject.Venue)
+		assert.Equal(t, "DR. LECTURER ONE", subject.Lecturer)
+
+		require.Len(t, subject.Timestamps, 2)
+		assert.Equal(t, "0830", subject.Timestamps[0].Start)
+		assert.Equal(t, "0950", subject.Timestamps[0].End)
+		assert.Equal(t, uint8(1), subject.Timestamps[0].Day)
+		assert.Equal(t, uint8(3), subject.Timestamps[1].Day)
+	})
+
+	t.Run("merged row continues the previous subject", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			subjects []dtos.ScheduleSubject
+		)
+
+		require.NoError(t, parseTableRow([]string{"INFO 3305", "WEB APPLICATION DEVELOPMENT", "2", "3", "Registered", "T - TH", "1000 - 1120", "ICT LAB 3", "DR. LECTURER TWO"}, time.Now(), &subjects, &mu))
+		require.NoError(t, parseTableRow([]string{"F", "1430 - 1620", "ICT LAB 4", "DR. LECTURER TWO"}, time.Now(), &subjects, &mu))
+
+		require.Len(t, subjects, 2)
+		merged := subjects[1]
+		assert.Equal(t, "INFO 3305", merged.CourseCode)
+		assert.Equal(t, uint32(2), merged.Section)
+		assert.Equal(t, "ICT LAB 4", merged.Venue)
+		require.Len(t, merged.Timestamps, 1)
+		assert.Equal(t, "1430", merged.Timestamps[0].Start)
+		assert.Equal(t, uint8(5), merged.Timestamps[0].Day)
+	})
+
+	t.Run("row without time", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			subjects []dtos.ScheduleSubject
+		)
+
+		require.NoError(t, parseTableRow([]string{"UNGS 2290", "KNOWLEDGE AND CIVILIZATION IN ISLAM", "15", "2", "Registered", "-", "-", "ONLINE", "USTAZ LECTURER THREE"}, time.Now(), &subjects, &mu))
+
+		require.Len(t, subjects, 1)
+		assert.Empty(t, subjects[0].Timestamps)
+	})
+
+	t.Run("skipped rows", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			subjects []dtos.ScheduleSubject
+		)
+
+		err := parseTableRow([]string{"F", "1430 - 1620", "ICT LAB 4", "DR. LECTURER TWO"}, time.Now(), &subjects, &mu)
+		assert.EqualError(t, err, "merged row without a subject before it")
+
+		err = parseTableRow([]string{"CSCI 4311", "COMPUTER ARCHITECTURE", "A", "3", "Registered", "M", "830 - 950", "ICT LR 1", "DR. LECTURER ONE"}, time.Now(), &subjects, &mu)
+		assert.EqualError(t, err, `section "A" is not a number`)
+
+		err = parseTableRow([]string{"CSCI 4311", "COMPUTER ARCHITECTURE", "1"}, time.Now(), &subjects, &mu)
+		assert.EqualError(t, err, "unexpected 3 cells, want 9 or 4")
+
+		// Rows without cells are not data rows
+		assert.NoError(t, parseTableRow(nil, time.Now(), &subjects, &mu))
+
+		assert.Empty(t, subjects)
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+
+	schedules, err := client.Schedule(context.Background(), cookie)
+	require.NoError(t, err)
+
+	require.Len(t, schedules, 2)
+	assert.Equal(t, "Sem 1, 2024/2025", schedules[0].SessionName)
+	assert.Equal(t, "Sem 2, 2023/2024", schedules[1].SessionName)
+
+	for _, schedule := range schedules {
+		require.Len(t, schedule.Schedule, 4)
+		assert.Equal(t, "CSCI 4311", schedule.Schedule[0].CourseCode)
+		assert.Equal(t, "INFO 3305", schedule.Schedule[2].CourseCode)
+		assert.Equal(t, "UNGS 2290", schedule.Schedule[3].CourseCode)
+	}
+}
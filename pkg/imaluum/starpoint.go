@@ -0,0 +1,156 @@
+package imaluum
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/nrmnqdds/gomaluum/internal/errors"
+	"github.com/rung/go-safecast"
+)
+
+// Object pools for memory reuse
+var programPool = sync.Pool{
+	New: func() any {
+		return &dtos.StarpointProgram{}
+	},
+}
+
+var programTdStringSlicePool = sync.Pool{
+	New: func() any {
+		return make([]string, 0, 10)
+	},
+}
+
+// Parse table row with object pooling.
+// A 6 cell row adds a program. Summary rows arrive without cells, as their
+// cells are read by the caller, and return nil. Any other row returns why it
+// was skipped.
+func parseProgramRows(tds []string, programs *[]dtos.StarpointProgram, mu *sync.Mutex) error {
+	if len(tds) == 0 {
+		return nil
+	}
+
+	var program *dtos.StarpointProgram
+
+	// Handle perfect cell (6 columns)
+	if len(tds) == 6 {
+		program = programPool.Get().(*dtos.StarpointProgram)
+		*program = dtos.StarpointProgram{} // Reset
+
+		section, err := safecast.Atoi8(strings.TrimSpace(tds[0]))
+		if err != nil {
+			programPool.Put(program)
+			return fmt.Errorf("semester %q is not a number", strings.TrimSpace(tds[0]))
+		}
+
+		program.Semester = uint8(section)
+		program.Session = strings.TrimSpace(tds[1])
+		program.EventName = strings.TrimSpace(tds[2])
+		program.Type = strings.TrimSpace(tds[3])
+		program.Level = strings.TrimSpace(tds[4])
+
+		points, err := strconv.ParseFloat(strings.TrimSpace(tds[5]), 32)
+		if err != nil {
+			programPool.Put(program)
+			return fmt.Errorf("points %q is not a number", strings.TrimSpace(tds[5]))
+		}
+		program.Points = float32(points)
+	}
+
+	if program != nil {
+		mu.Lock()
+		*programs = append(*programs, *program)
+		mu.Unlock()
+
+		programPool.Put(program)
+		return nil
+	}
+
+	return fmt.Errorf("unexpected %d cells, want 6", len(tds))
+}
+
+func getFloatFromString(s string) float64 {
+	ca := strings.TrimSpace(strings.Split(s, ":")[1])
+
+	points, err := strconv.ParseFloat(ca, 64)
+	if err != nil {
+		return 0
+	}
+
+	return points
+}
+
+// Starpoint scrapes the student's co-curricular programs and points
+func (c *Client) Starpoint(ctx context.Context, cookie string) (*dtos.Starpoint, error) {
+	var (
+		logger    = c.log.GetLogger()
+		mu        sync.Mutex
+		programs  []dtos.StarpointProgram
+		starpoint = &dtos.Starpoint{}
+	)
+
+	collector := c.newCollector(ctx, endpointStarpoint, cookie)
+	page := c.newTablePage(collector, endpointStarpoint, "", "table.table.table-hover")
+
+	collector.OnHTML("table.table.table-hover tbody tr", func(e *colly.HTMLElement) {
+		// Get all text at once with efficient DOM traversal
+		cells := e.DOM.Find("td")
+		if cells.Length() == 0 {
+			return
+		}
+
+		tds := programTdStringSlicePool.Get().([]string)
+		tds = tds[:0] // Reset slice
+
+		cells.Each(func(_ int, s *goquery.Selection) {
+			if strings.TrimSpace(strings.Split(s.Text(), ":")[0]) == "Cummulative Average" {
+				// Special case for Cummulative Average row
+				if starpoint.CummulativeAverage != 0 {
+					logger.Sugar().Warn("Cummulative Average already set, skipping duplicate")
+					logger.Sugar().Debugf("Current value: %f, new value: %s", starpoint.CummulativeAverage, s.Text())
+					return
+				}
+				starpoint.CummulativeAverage = getFloatFromString(s.Text())
+				return
+			}
+
+			if strings.TrimSpace(strings.Split(s.Text(), ":")[0]) == "Total Point" {
+				// Special case for Total Point row
+				if starpoint.TotalPoints != 0 {
+					logger.Sugar().Warn("Total Point already set, skipping duplicate")
+					logger.Sugar().Debugf("Current value: %f, new value: %s", starpoint.CummulativeAverage, s.Text())
+					return
+				}
+				starpoint.TotalPoints = getFloatFromString(s.Text())
+				return
+			}
+			tds = append(tds, s.Text())
+		})
+		page.row(len(tds), parseProgramRows(tds, &programs, &mu))
+
+		programTdStringSlicePool.Put(tds)
+	})
+
+	if err := collector.Visit(c.urls.ImaluumStarpointPage()); err != nil {
+		return nil, errors.WrapUpstream(err, errors.Wrap(errors.ErrFailedToGoToURL, err))
+	}
+
+	c.finishTablePage(ctx, page, len(programs))
+
+	if len(programs) == 0 {
+		logger.Sugar().Error("Program is empty")
+		return nil, errors.ErrNoStarpoint
+	}
+
+	// Set starpoint data
+	starpoint.Programs = programs
+	newIDMinter(ctx).starpoint(starpoint)
+
+	return starpoint, nil
+}
@@ -0,0 +1,48 @@
+package imaluum
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProgramRows(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		programs []dtos.StarpointProgram
+	)
+
+	require.NoError(t, parseProgramRows([]string{"1", "2023/2024", "KICT HACKATHON", "PARTICIPANT", "KULLIYYAH", "2.5"}, &programs, &mu))
+
+	// Semester and points must be numbers, and only 6 cell rows are programs
+	err := parseProgramRows([]string{"I", "2023/2024", "KICT HACKATHON", "PARTICIPANT", "KULLIYYAH", "2.5"}, &programs, &mu)
+	assert.EqualError(t, err, `semester "I" is not a number`)
+	err = parseProgramRows([]string{"1", "2023/2024", "KICT HACKATHON", "PARTICIPANT", "KULLIYYAH", "-"}, &programs, &mu)
+	assert.EqualError(t, err, `points "-" is not a number`)
+	err = parseProgramRows([]string{"1", "2023/2024", "KICT HACKATHON"}, &programs, &mu)
+	assert.EqualError(t, err, "unexpected 3 cells, want 6")
+
+	require.Len(t, programs, 1)
+	assert.Equal(t, uint8(1), programs[0].Semester)
+	assert.Equal(t, "2023/2024", programs[0].Session)
+	assert.Equal(t, "KICT HACKATHON", programs[0].EventName)
+	assert.Equal(t, "PARTICIPANT", programs[0].Type)
+	assert.Equal(t, "KULLIYYAH", programs[0].Level)
+	assert.InDelta(t, 2.5, programs[0].Points, 0.001)
+}
+
+func TestStarpoint(t *testing.T) {
+	client, _, cookie := newTestClient(t)
+
+	starpoint, err := client.Starpoint(context.Background(), cookie)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 3.25, starpoint.CummulativeAverage, 0.001)
+	assert.InDelta(t, 6.5, starpoint.TotalPoints, 0.001)
+	require.Len(t, starpoint.Programs, 2)
+	assert.Equal(t, "MAHALLAH SPORTS WEEK", starpoint.Programs[1].EventName)
+}
@@ -0,0 +1,62 @@
+package imaluum
+
+import (
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+)
+
+// ScheduleV2 converts scraped schedules to their v2 shape
+func ScheduleV2(schedules []dtos.ScheduleResponse) []dtos.ScheduleResponseV2 {
+	converted := make([]dtos.ScheduleResponseV2, len(schedules))
+	for i, schedule := range schedules {
+		subjects := make([]dtos.ScheduleSubjectV2, len(schedule.Schedule))
+		for j, subject := range schedule.Schedule {
+			slots := make([]dtos.ScheduleSlot, len(subject.Timestamps))
+			for k, timestamp := range subject.Timestamps {
+				slots[k] = dtos.ScheduleSlot{
+					Start: clockTime(timestamp.Start),
+					End:   clockTime(timestamp.End),
+					Day:   timestamp.Day,
+				}
+			}
+
+			subjects[j] = dtos.ScheduleSubjectV2{
+				ID:          subject.ID,
+				CourseCode:  subject.CourseCode,
+				CourseName:  subject.CourseName,
+				Venue:       subject.Venue,
+				Lecturer:    subject.Lecturer,
+				Slots:       slots,
+				CreditHours: subject.Chr,
+				Section:     subject.Section,
+			}
+		}
+
+		converted[i] = dtos.ScheduleResponseV2{
+			ID:           schedule.ID,
+			SessionName:  schedule.SessionName,
+			SessionQuery: schedule.SessionQuery,
+			Schedule:     subjects,
+		}
+	}
+
+	return converted
+}
+
+// StarpointV2 converts a scraped starpoint to its v2 shape
+func StarpointV2(starpoint *dtos.Starpoint) *dtos.StarpointV2 {
+	return &dtos.StarpointV2{
+		ID:                starpoint.ID,
+		CumulativeAverage: starpoint.CummulativeAverage,
+		TotalPoints:       starpoint.TotalPoints,
+		Programs:          starpoint.Programs,
+	}
+}
+
+// clockTime turns the 0830 of a schedule slot into 08:30, leaving anything
+// else as printed
+func clockTime(hhmm string) string {
+	if len(hhmm) != 4 {
+		return hhmm
+	}
+	return hhmm[:2] + ":" + hhmm[2:]
+}
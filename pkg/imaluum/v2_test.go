@@ -0,0 +1,58 @@
+package imaluum
+
+import (
+	"testing"
+
+	"github.com/nrmnqdds/gomaluum/internal/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleV2(t *testing.T) {
+	schedules := ScheduleV2([]dtos.ScheduleResponse{{
+		ID:           "gomaluum:schedule:1",
+		SessionName:  "Sem 1, 2024/2025",
+		SessionQuery: "?ses=2024/2025&sem=1",
+		Schedule: []dtos.ScheduleSubject{{
+			ID:         "gomaluum:subject:1",
+			CourseCode: "CSCI 3300",
+			Timestamps: []dtos.WeekTime{
+				{Start: "0830", StartUnix: 1, End: "0950", EndUnix: 2, Day: 1},
+				{Start: "1400", End: "1520", Day: 3},
+				{Start: "TBA", End: "TBA", Day: 7},
+			},
+			Chr:     3,
+			Section: 2,
+		}},
+	}})
+
+	require.Len(t, schedules, 1)
+	assert.Equal(t, "gomaluum:schedule:1", schedules[0].ID)
+	assert.Equal(t, "?ses=2024/2025&sem=1", schedules[0].SessionQuery)
+
+	require.Len(t, schedules[0].Schedule, 1)
+	subject := schedules[0].Schedule[0]
+	assert.Equal(t, "gomaluum:subject:1", subject.ID)
+	assert.InDelta(t, 3.0, subject.CreditHours, 0.001)
+	assert.Equal(t, uint32(2), subject.Section)
+	assert.Equal(t, []dtos.ScheduleSlot{
+		{Start: "08:30", End: "09:50", Day: 1},
+		{Start: "14:00", End: "15:20", Day: 3},
+		{Start: "TBA", End: "TBA", Day: 7},
+	}, subject.Slots)
+}
+
+func TestStarpointV2(t *testing.T) {
+	programs := []dtos.StarpointProgram{{ID: "gomaluum:program:1"}}
+	starpoint := StarpointV2(&dtos.Starpoint{
+		ID:                 "gomaluum:starpoint:1",
+		CummulativeAverage: 2.5,
+		TotalPoints:        10,
+		Programs:           programs,
+	})
+
+	assert.Equal(t, "gomaluum:starpoint:1", starpoint.ID)
+	assert.InDelta(t, 2.5, starpoint.CumulativeAverage, 0.001)
+	assert.InDelta(t, 10.0, starpoint.TotalPoints, 0.001)
+	assert.Equal(t, programs, starpoint.Programs)
+}
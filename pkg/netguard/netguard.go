@@ -0,0 +1,104 @@
+// Package netguard keeps requests to user-supplied URLs, such as webhooks
+// and push endpoints, away from the server's own network.
+//
+// Hosts are checked twice: when the URL is registered, with CheckHost, and
+// on every dial, with Control, so a name that later resolves to an internal
+// address (DNS rebinding) is still refused.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+const dialTimeout = 5 * time.Second
+
+// ErrForbiddenAddress is returned for hosts on loopback, private, link-local,
+// carrier-grade NAT or unspecified addresses
+var ErrForbiddenAddress = errors.New("address is not publicly routable")
+
+// internalPrefixes are internal ranges netip has no predicate for: "this
+// network" and the carrier-grade NAT shared address space
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Allowed reports whether ip may be connected to on behalf of a user
+func Allowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() ||
+		ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost resolves host and fails when any of its addresses is not Allowed
+func CheckHost(ctx context.Context, host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return check(ip)
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if err := check(ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control that refuses addresses not Allowed
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	return check(addrPort.Addr())
+}
+
+func check(ip netip.Addr) error {
+	if !Allowed(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// NewClient returns a client that only dials Allowed addresses and never
+// follows redirects, which could otherwise point anywhere. Environment
+// proxies are ignored, as the proxy itself would be dialed.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: dialTimeout, Control: Control}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
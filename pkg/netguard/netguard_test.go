@@ -0,0 +1,77 @@
+package netguard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowed(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.215.14":      true,
+		"2606:4700::6810:1":  true,
+		"127.0.0.1":          false,
+		"::1":                false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"fe80::1":            false,
+		"fd00::1":            false,
+		"0.0.0.0":            false,
+		"0.1.2.3":            false,
+		"100.64.0.1":         false,
+		"100.127.255.254":    false,
+		"100.128.0.1":        true,
+		"::ffff:100.64.0.1":  false,
+		"::":                 false,
+		"::ffff:127.0.0.1":   false,
+		"::ffff:169.254.0.1": false,
+	} {
+		assert.Equal(t, want, Allowed(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	assert.NoError(t, CheckHost(context.Background(), "93.184.215.14"))
+	assert.ErrorIs(t, CheckHost(context.Background(), "169.254.169.254"), ErrForbiddenAddress)
+	assert.ErrorIs(t, CheckHost(context.Background(), "100.100.100.200"), ErrForbiddenAddress)
+	assert.ErrorIs(t, CheckHost(context.Background(), "localhost"), ErrForbiddenAddress)
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a loopback address was dialed")
+	}))
+	defer server.Close()
+
+	// The URL passed no registration check, the dial is refused all the same
+	_, err := NewClient(0).Get(server.URL)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	client := NewClient(0)
+	// Dial the test server regardless of its address
+	client.Transport = http.DefaultTransport
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/internal", http.StatusFound)
+			return
+		}
+		t.Error("the redirect was followed")
+	}))
+	defer server.Close()
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
@@ -0,0 +1,199 @@
+// Package push sends Web Push notifications (RFC 8030) to browser
+// subscriptions, encrypted for the subscriber (RFC 8291) and signed with the
+// server's VAPID key (RFC 8292).
+//
+// Generate a key pair once with GenerateKeys and configure it with
+// VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY. The public key is the
+// applicationServerKey the PWA passes to pushManager.subscribe().
+package push
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/nrmnqdds/gomaluum/pkg/netguard"
+)
+
+const (
+	defaultSubject = "mailto:admin@gomaluum.app"
+	defaultTTL     = 24 * time.Hour
+	defaultTimeout = 10 * time.Second
+)
+
+var (
+	// ErrGone is returned when the push service no longer knows the
+	// subscription, it should be deleted
+	ErrGone = errors.New("push subscription expired or unsubscribed")
+
+	// ErrInvalidSubscription is returned for subscriptions that cannot be delivered to
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+)
+
+// Services are the hosts of the browser push services. Endpoints must be on
+// one of them or a subdomain.
+var Services = []string{
+	// Chrome, Edge on Android, Opera
+	"fcm.googleapis.com",
+	// Firefox
+	"updates.push.services.mozilla.com",
+	// Safari, web.push.apple.com
+	"push.apple.com",
+	// Edge on Windows, wns2-*.notify.windows.com
+	"notify.windows.com",
+}
+
+// Subscription is a PushSubscription of a browser, with its keys base64url encoded
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Sender encrypts and posts notifications to push services
+type Sender struct {
+	Client     *http.Client
+	PublicKey  string
+	PrivateKey string
+	// Subject is a mailto: or https: contact for the push service operators
+	Subject string
+	TTL     time.Duration
+	// AllowHTTP accepts plain http endpoints on any host, for local push
+	// service stubs
+	AllowHTTP bool
+}
+
+// NewSender configures a sender from the environment, or returns nil when
+// no VAPID key pair is configured:
+//
+//	VAPID_PUBLIC_KEY   base64url P-256 public key
+//	VAPID_PRIVATE_KEY  base64url P-256 private key
+//	VAPID_SUBJECT      contact for push services (default mailto:admin@gomaluum.app)
+//	PUSH_TTL           how long push services keep undelivered messages (default 24h)
+//	PUSH_ALLOW_HTTP    accept plain http endpoints on any host (default false)
+func NewSender() *Sender {
+	publicKey, privateKey := os.Getenv("VAPID_PUBLIC_KEY"), os.Getenv("VAPID_PRIVATE_KEY")
+	if publicKey == "" || privateKey == "" {
+		log.Println("VAPID_PUBLIC_KEY or VAPID_PRIVATE_KEY not set, Web Push is disabled")
+		return nil
+	}
+
+	sender := &Sender{
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		Subject:    defaultSubject,
+		TTL:        defaultTTL,
+	}
+
+	if subject := os.Getenv("VAPID_SUBJECT"); subject != "" {
+		sender.Subject = subject
+	}
+
+	if raw := os.Getenv("PUSH_TTL"); raw != "" {
+		if ttl, err := time.ParseDuration(raw); err == nil && ttl >= 0 {
+			sender.TTL = ttl
+		} else {
+			log.Printf("Invalid PUSH_TTL=%q, using default %s", raw, defaultTTL)
+		}
+	}
+
+	sender.AllowHTTP, _ = strconv.ParseBool(os.Getenv("PUSH_ALLOW_HTTP"))
+
+	// Push services are third parties, never through the i-Ma'luum
+	// transport, and only reached on public addresses unless using a stub
+	sender.Client = netguard.NewClient(defaultTimeout)
+	if sender.AllowHTTP {
+		sender.Client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return sender
+}
+
+// GenerateKeys returns a new base64url VAPID key pair
+func GenerateKeys() (publicKey, privateKey string, err error) {
+	privateKey, publicKey, err = webpush.GenerateVAPIDKeys()
+	return publicKey, privateKey, err
+}
+
+// Validate checks that sub can be delivered to
+func (s *Sender) Validate(sub Subscription) error {
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an absolute URL", ErrInvalidSubscription)
+	}
+	if u.Scheme != "https" && (u.Scheme != "http" || !s.AllowHTTP) {
+		return fmt.Errorf("%w: endpoint must be https", ErrInvalidSubscription)
+	}
+	if !s.AllowHTTP && !knownService(u.Hostname()) {
+		return fmt.Errorf("%w: endpoint must be on a browser push service", ErrInvalidSubscription)
+	}
+
+	// An uncompressed P-256 point and a 16 byte secret
+	if key, err := decodeKey(sub.P256dh); err != nil || len(key) != 65 || key[0] != 4 {
+		return fmt.Errorf("%w: p256dh must be an uncompressed P-256 public key", ErrInvalidSubscription)
+	}
+	if auth, err := decodeKey(sub.Auth); err != nil || len(auth) != 16 {
+		return fmt.Errorf("%w: auth must be 16 bytes", ErrInvalidSubscription)
+	}
+
+	return nil
+}
+
+func knownService(host string) bool {
+	host = strings.ToLower(host)
+	for _, service := range Services {
+		if host == service || strings.HasSuffix(host, "."+service) {
+			return true
+		}
+	}
+	return false
+}
+
+// Send encrypts payload for sub and posts it to its push service. topic
+// collapses undelivered messages of the same kind, it may be empty.
+func (s *Sender) Send(ctx context.Context, sub Subscription, payload []byte, topic string) error {
+	resp, err := webpush.SendNotificationWithContext(ctx, payload, &webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		Keys: webpush.Keys{
+			P256dh: sub.P256dh,
+			Auth:   sub.Auth,
+		},
+	}, &webpush.Options{
+		HTTPClient:      s.Client,
+		Subscriber:      s.Subject,
+		Topic:           topic,
+		TTL:             int(s.TTL.Seconds()),
+		Urgency:         webpush.UrgencyNormal,
+		VAPIDPublicKey:  s.PublicKey,
+		VAPIDPrivateKey: s.PrivateKey,
+	})
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	default:
+		return fmt.Errorf("push service answered %s", resp.Status)
+	}
+}
+
+// decodeKey accepts the padded and unpadded base64url keys browsers hand out
+func decodeKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
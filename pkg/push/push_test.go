@@ -0,0 +1,128 @@
+package push_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nrmnqdds/gomaluum/pkg/netguard"
+	"github.com/nrmnqdds/gomaluum/pkg/push"
+	"github.com/nrmnqdds/gomaluum/pkg/push/pushtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSender(t *testing.T) *push.Sender {
+	t.Helper()
+
+	publicKey, privateKey, err := push.GenerateKeys()
+	require.NoError(t, err)
+
+	return &push.Sender{
+		Client:     http.DefaultClient,
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		Subject:    "mailto:test@example.com",
+		AllowHTTP:  true,
+	}
+}
+
+func TestSend(t *testing.T) {
+	service := pushtest.NewServer()
+	defer service.Close()
+
+	sender := testSender(t)
+	sub := service.Subscribe()
+	require.NoError(t, sender.Validate(sub))
+
+	require.NoError(t, sender.Send(context.Background(), sub, []byte(`{"title":"New grades"}`), "result"))
+
+	messages := service.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, sub.Endpoint, messages[0].Endpoint)
+	assert.JSONEq(t, `{"title":"New grades"}`, string(messages[0].Payload))
+	assert.Equal(t, "result", messages[0].Header.Get("Topic"))
+	assert.Contains(t, messages[0].Header.Get("Authorization"), "k="+sender.PublicKey)
+}
+
+func TestSendGone(t *testing.T) {
+	service := pushtest.NewServer()
+	defer service.Close()
+
+	sender := testSender(t)
+	sub := service.Subscribe()
+	service.Expire(sub)
+
+	assert.ErrorIs(t, sender.Send(context.Background(), sub, []byte(`{}`), ""), push.ErrGone)
+
+	unknown := sub
+	unknown.Endpoint = service.URL + "/push/unknown"
+	assert.ErrorIs(t, sender.Send(context.Background(), unknown, []byte(`{}`), ""), push.ErrGone)
+
+	assert.Empty(t, service.Messages())
+}
+
+func TestValidate(t *testing.T) {
+	service := pushtest.NewServer()
+	defer service.Close()
+
+	sender := testSender(t)
+	sub := service.Subscribe()
+
+	for name, mutate := range map[string]func(*push.Subscription){
+		"relative endpoint": func(s *push.Subscription) { s.Endpoint = "/push/1" },
+		"short key":         func(s *push.Subscription) { s.P256dh = "BAAA" },
+		"invalid key":       func(s *push.Subscription) { s.P256dh = "not base64!" },
+		"short auth":        func(s *push.Subscription) { s.Auth = "AAAA" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			invalid := sub
+			mutate(&invalid)
+			assert.ErrorIs(t, sender.Validate(invalid), push.ErrInvalidSubscription)
+		})
+	}
+
+	sender.AllowHTTP = false
+	assert.ErrorIs(t, sender.Validate(sub), push.ErrInvalidSubscription)
+}
+
+func TestValidateOnlyKnownServices(t *testing.T) {
+	service := pushtest.NewServer()
+	defer service.Close()
+
+	sender := testSender(t)
+	sender.AllowHTTP = false
+	sub := service.Subscribe()
+
+	for endpoint, valid := range map[string]bool{
+		"https://fcm.googleapis.com/fcm/send/abc":                true,
+		"https://updates.push.services.mozilla.com/wpush/v2/abc": true,
+		"https://web.push.apple.com/abc":                         true,
+		"https://wns2-par02p.notify.windows.com/w/?token=abc":    true,
+		"https://127.0.0.1/push/abc":                             false,
+		"https://169.254.169.254/latest/meta-data":               false,
+		"https://internal.example.com/push/abc":                  false,
+		"https://fcm.googleapis.com.example.com/fcm/send/abc":    false,
+		"https://notfcm.googleapis.com/fcm/send/abc":             false,
+	} {
+		sub.Endpoint = endpoint
+		if valid {
+			assert.NoError(t, sender.Validate(sub), endpoint)
+		} else {
+			assert.ErrorIs(t, sender.Validate(sub), push.ErrInvalidSubscription, endpoint)
+		}
+	}
+}
+
+func TestSendRefusesPrivateAddresses(t *testing.T) {
+	service := pushtest.NewServer()
+	defer service.Close()
+
+	sender := testSender(t)
+	sender.Client = netguard.NewClient(time.Second)
+
+	err := sender.Send(context.Background(), service.Subscribe(), []byte(`{}`), "")
+	assert.ErrorIs(t, err, netguard.ErrForbiddenAddress)
+	assert.Empty(t, service.Messages())
+}
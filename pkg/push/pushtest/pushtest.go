@@ -0,0 +1,204 @@
+// Package pushtest is a local push service for testing Web Push delivery
+// without a browser. It hands out subscriptions, decrypts what is posted to
+// them and records the messages.
+package pushtest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/lucsky/cuid"
+	"github.com/nrmnqdds/gomaluum/pkg/push"
+)
+
+// Message is a notification received by the stub
+type Message struct {
+	Header   http.Header
+	Endpoint string
+	Payload  []byte
+}
+
+type subscriber struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+	gone bool
+}
+
+// Server is a push service stub
+type Server struct {
+	*httptest.Server
+
+	subscribers map[string]*subscriber
+	messages    []Message
+	mu          sync.Mutex
+}
+
+// NewServer starts a push service stub, close it when done
+func NewServer() *Server {
+	s := &Server{subscribers: make(map[string]*subscriber)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Subscribe returns a new subscription on the stub, as a browser would
+func (s *Server) Subscribe() push.Subscription {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		panic(err)
+	}
+
+	endpoint := s.URL + "/push/" + cuid.New()
+
+	s.mu.Lock()
+	s.subscribers[endpoint] = &subscriber{key: key, auth: auth}
+	s.mu.Unlock()
+
+	return push.Subscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}
+}
+
+// Expire makes the endpoint of sub answer 410 Gone, as after the user
+// revoked the notification permission
+func (s *Server) Expire(sub push.Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if subscriber, ok := s.subscribers[sub.Endpoint]; ok {
+		subscriber.gone = true
+	}
+}
+
+// Messages returns the decrypted notifications received so far
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.messages...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	endpoint := s.URL + r.URL.Path
+
+	s.mu.Lock()
+	subscriber, ok := s.subscribers[endpoint]
+	s.mu.Unlock()
+
+	switch {
+	case !ok || r.Method != http.MethodPost:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case subscriber.gone:
+		w.WriteHeader(http.StatusGone)
+		return
+	case !strings.HasPrefix(r.Header.Get("Authorization"), "vapid t="):
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	case r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") == "":
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	payload, err := decrypt(subscriber, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.messages = append(s.messages, Message{
+		Header:   r.Header.Clone(),
+		Endpoint: endpoint,
+		Payload:  payload,
+	})
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// decrypt reverses the aes128gcm content coding of RFC 8291 for a single record
+func decrypt(subscriber *subscriber, body []byte) ([]byte, error) {
+	// salt (16) | record size (4) | key length (1) | sender public key | ciphertext
+	if len(body) < 21 {
+		return nil, errors.New("body too short")
+	}
+	salt, keyLength := body[:16], int(body[20])
+	if binary.BigEndian.Uint32(body[16:20]) < 18 || len(body) < 21+keyLength {
+		return nil, errors.New("invalid header")
+	}
+	senderKey, ciphertext := body[21:21+keyLength], body[21+keyLength:]
+
+	public, err := ecdh.P256().NewPublicKey(senderKey)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := subscriber.key.ECDH(public)
+	if err != nil {
+		return nil, err
+	}
+
+	info := append([]byte("WebPush: info\x00"), subscriber.key.PublicKey().Bytes()...)
+	info = append(info, senderKey...)
+	ikm, err := hkdf.Key(sha256.New, secret, subscriber.auth, string(info), 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The last record ends with a 0x02 delimiter followed by zero padding
+	plaintext = []byte(strings.TrimRight(string(plaintext), "\x00"))
+	if len(plaintext) == 0 || plaintext[len(plaintext)-1] != 0x02 {
+		return nil, errors.New("missing padding delimiter")
+	}
+
+	return plaintext[:len(plaintext)-1], nil
+}
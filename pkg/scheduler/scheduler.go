@@ -0,0 +1,462 @@
+// Package scheduler runs periodic jobs persisted in a SQL database.
+//
+// Every replica runs a Scheduler against the same database, but only the one
+// holding the lease dispatches jobs. The lease is renewed on every tick and
+// taken over by another replica once it expires, so a dead leader stalls
+// jobs for at most one lease TTL.
+//
+// A job is claimed by moving its next run forward before it starts, so a
+// job that crashes its replica is retried after its interval rather than in
+// a loop.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	mrand "math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lucsky/cuid"
+)
+
+const (
+	defaultTick        = 15 * time.Second
+	defaultLeaseTTL    = time.Minute
+	defaultConcurrency = 2
+	defaultJitter      = 0.1
+
+	leaseName = "scheduler"
+)
+
+// ErrJobNotFound is returned for operations on a job that does not exist
+var ErrJobNotFound = errors.New("job not found")
+
+// Schema creates the tables of the scheduler, every statement is idempotent
+var Schema = []string{
+	`CREATE TABLE IF NOT EXISTS jobs (
+		name TEXT NOT NULL PRIMARY KEY,
+		kind TEXT NOT NULL,
+		key TEXT NOT NULL,
+		interval_seconds INTEGER NOT NULL,
+		next_run_at INTEGER NOT NULL,
+		last_run_at INTEGER,
+		last_duration_ms INTEGER,
+		last_error TEXT,
+		paused INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_jobs_next_run_at ON jobs(paused, next_run_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_jobs_key ON jobs(key)`,
+	`CREATE TABLE IF NOT EXISTS scheduler_leases (
+		name TEXT NOT NULL PRIMARY KEY,
+		holder TEXT NOT NULL,
+		expires_at INTEGER NOT NULL
+	)`,
+}
+
+// Job is a periodic run of the handler of Kind for Key, e.g. a sync of one user
+type Job struct {
+	LastRunAt    *time.Time
+	LastError    *string
+	NextRunAt    time.Time
+	CreatedAt    time.Time
+	Name         string
+	Kind         string
+	Key          string
+	Interval     time.Duration
+	LastDuration time.Duration
+	Paused       bool
+}
+
+// Handler runs one job. A returned error is stored as the last error of the job.
+type Handler func(ctx context.Context, job Job) error
+
+// Scheduler dispatches due jobs to the handler of their kind
+type Scheduler struct {
+	// Ready reports whether a job may start now, jobs that are not started
+	// stay due until the next tick. Nil always starts.
+	Ready    func() bool
+	DB       *sql.DB
+	handlers map[string]Handler
+	slots    chan struct{}
+	// ID identifies this replica as the lease holder
+	ID string
+	// Tick is how often due jobs are looked for and the lease renewed
+	Tick     time.Duration
+	LeaseTTL time.Duration
+	// Jitter spreads runs by up to this fraction of their interval
+	Jitter  float64
+	running sync.WaitGroup
+	mu      sync.Mutex
+}
+
+// New configures a scheduler from the environment:
+//
+//	SCHEDULER_TICK         how often due jobs are looked for (default 15s)
+//	SCHEDULER_LEASE_TTL    how long a silent leader keeps the lease (default 1m)
+//	SCHEDULER_CONCURRENCY  jobs running at once on the leader (default 2)
+//	SCHEDULER_JITTER       fraction of the interval runs are spread by (default 0.1)
+func New(db *sql.DB) *Scheduler {
+	hostname, _ := os.Hostname()
+
+	s := &Scheduler{
+		DB:       db,
+		ID:       hostname + "-" + cuid.New(),
+		Tick:     envDuration("SCHEDULER_TICK", defaultTick),
+		LeaseTTL: envDuration("SCHEDULER_LEASE_TTL", defaultLeaseTTL),
+		Jitter:   defaultJitter,
+	}
+	s.SetConcurrency(defaultConcurrency)
+
+	if raw := os.Getenv("SCHEDULER_CONCURRENCY"); raw != "" {
+		if value, err := strconv.Atoi(raw); err == nil && value > 0 {
+			s.SetConcurrency(value)
+		} else {
+			log.Printf("Invalid SCHEDULER_CONCURRENCY=%q, using default %d", raw, defaultConcurrency)
+		}
+	}
+
+	if raw := os.Getenv("SCHEDULER_JITTER"); raw != "" {
+		if value, err := strconv.ParseFloat(raw, 64); err == nil && value >= 0 && value < 1 {
+			s.Jitter = value
+		} else {
+			log.Printf("Invalid SCHEDULER_JITTER=%q, using default %g", raw, defaultJitter)
+		}
+	}
+
+	return s
+}
+
+// SetConcurrency caps the jobs running at once, call it before Run
+func (s *Scheduler) SetConcurrency(n int) {
+	s.slots = make(chan struct{}, n)
+}
+
+// Handle registers the handler of a job kind
+func (s *Scheduler) Handle(kind string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.handlers == nil {
+		s.handlers = make(map[string]Handler)
+	}
+	s.handlers[kind] = handler
+}
+
+// Name returns the name of the job of kind for key
+func Name(kind, key string) string {
+	return kind + ":" + key
+}
+
+// Ensure creates the job of kind for key if it does not exist yet. Its first
+// run is due within the jitter, existing jobs keep their schedule and pause.
+func (s *Scheduler) Ensure(ctx context.Context, kind, key string, interval time.Duration) error {
+	now := time.Now()
+	first := now.Add(time.Duration(mrand.Float64() * s.Jitter * float64(interval)))
+
+	_, err := s.DB.ExecContext(ctx, `
+			INSERT INTO jobs (name, kind, key, interval_seconds, next_run_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(name) DO UPDATE SET interval_seconds = excluded.interval_seconds
+		`, Name(kind, key), kind, key, int64(interval.Seconds()), first.Unix(), now.Unix())
+	return err
+}
+
+// Remove deletes every job of key
+func (s *Scheduler) Remove(ctx context.Context, key string) error {
+	_, err := s.DB.ExecContext(ctx, `DELETE FROM jobs WHERE key = ?`, key)
+	return err
+}
+
+// List returns the jobs of kind, or every job if kind is empty, in the order they are due
+func (s *Scheduler) List(ctx context.Context, kind string) ([]Job, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+			SELECT name, kind, key, interval_seconds, next_run_at, last_run_at, last_duration_ms, last_error, paused, created_at
+			FROM jobs
+			WHERE ? = '' OR kind = ?
+			ORDER BY next_run_at, name
+		`, kind, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Get returns the job called name
+func (s *Scheduler) Get(ctx context.Context, name string) (Job, error) {
+	job, err := scanJob(s.DB.QueryRowContext(ctx, `
+			SELECT name, kind, key, interval_seconds, next_run_at, last_run_at, last_duration_ms, last_error, paused, created_at
+			FROM jobs
+			WHERE name = ?
+		`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, ErrJobNotFound
+	}
+	return job, err
+}
+
+// SetPaused pauses or resumes the job called name. A running job finishes.
+func (s *Scheduler) SetPaused(ctx context.Context, name string, paused bool) error {
+	return s.update(ctx, `UPDATE jobs SET paused = ? WHERE name = ?`, paused, name)
+}
+
+// Trigger makes the job called name due now, it runs on the next tick
+// unless paused
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	return s.update(ctx, `UPDATE jobs SET next_run_at = ? WHERE name = ?`, time.Now().Unix(), name)
+}
+
+func (s *Scheduler) update(ctx context.Context, query string, args ...any) error {
+	result, err := s.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Run dispatches due jobs every tick until ctx is done, then gives up the lease
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Tick)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.dispatch(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Scheduler tick failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			s.running.Wait()
+			s.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunPending dispatches the jobs due now, if this replica is the leader,
+// and waits for every running job to finish. It returns how many started.
+func (s *Scheduler) RunPending(ctx context.Context) (int, error) {
+	started, err := s.dispatch(ctx)
+	s.running.Wait()
+	return started, err
+}
+
+// Leader reports whether this replica holds the lease, taking it over if it expired
+func (s *Scheduler) Leader(ctx context.Context) (bool, error) {
+	now := time.Now()
+
+	if _, err := s.DB.ExecContext(ctx, `
+			INSERT INTO scheduler_leases (name, holder, expires_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT(name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+			WHERE scheduler_leases.holder = excluded.holder OR scheduler_leases.expires_at < ?
+		`, leaseName, s.ID, now.Add(s.LeaseTTL).Unix(), now.Unix()); err != nil {
+		return false, err
+	}
+
+	var holder string
+	if err := s.DB.QueryRowContext(ctx, `SELECT holder FROM scheduler_leases WHERE name = ?`, leaseName).Scan(&holder); err != nil {
+		return false, err
+	}
+
+	return holder == s.ID, nil
+}
+
+// release gives up the lease so another replica takes over without waiting
+// for it to expire
+func (s *Scheduler) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.DB.ExecContext(ctx, `DELETE FROM scheduler_leases WHERE name = ? AND holder = ?`, leaseName, s.ID); err != nil {
+		log.Printf("Failed to release scheduler lease: %v", err)
+	}
+}
+
+// dispatch starts as many due jobs as there are free slots
+func (s *Scheduler) dispatch(ctx context.Context) (int, error) {
+	leader, err := s.Leader(ctx)
+	if err != nil || !leader {
+		return 0, err
+	}
+
+	free := cap(s.slots) - len(s.slots)
+	if free <= 0 {
+		return 0, nil
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+			SELECT name, kind, key, interval_seconds, next_run_at, last_run_at, last_duration_ms, last_error, paused, created_at
+			FROM jobs
+			WHERE paused = 0 AND next_run_at <= ?
+			ORDER BY next_run_at
+			LIMIT ?
+		`, time.Now().Unix(), free)
+	if err != nil {
+		return 0, err
+	}
+
+	var due []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		due = append(due, job)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+
+	started := 0
+	for _, job := range due {
+		if s.Ready != nil && !s.Ready() {
+			break
+		}
+
+		claimed, err := s.claim(ctx, job)
+		if err != nil {
+			return started, err
+		}
+		if !claimed {
+			continue
+		}
+
+		s.slots <- struct{}{}
+		s.running.Add(1)
+		started++
+
+		go func() {
+			defer s.running.Done()
+			defer func() { <-s.slots }()
+			s.run(ctx, job)
+		}()
+	}
+
+	return started, nil
+}
+
+// claim moves the next run of job forward, failing if another replica did first
+func (s *Scheduler) claim(ctx context.Context, job Job) (bool, error) {
+	next := time.Now().Add(s.spread(job.Interval))
+
+	result, err := s.DB.ExecContext(ctx, `
+			UPDATE jobs SET next_run_at = ? WHERE name = ? AND next_run_at = ? AND paused = 0
+		`, next.Unix(), job.Name, job.NextRunAt.Unix())
+	if err != nil {
+		return false, err
+	}
+	claimed, err := result.RowsAffected()
+	return claimed == 1, err
+}
+
+// spread returns interval moved by up to Jitter of itself either way
+func (s *Scheduler) spread(interval time.Duration) time.Duration {
+	if s.Jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration((mrand.Float64()*2-1)*s.Jitter*float64(interval))
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	s.mu.Lock()
+	handler, ok := s.handlers[job.Kind]
+	s.mu.Unlock()
+
+	started := time.Now()
+
+	var err error
+	if ok {
+		err = safeRun(ctx, handler, job)
+	} else {
+		err = fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+
+	var lastError *string
+	if err != nil {
+		message := err.Error()
+		lastError = &message
+	}
+
+	if _, dbErr := s.DB.ExecContext(context.WithoutCancel(ctx), `
+			UPDATE jobs SET last_run_at = ?, last_duration_ms = ?, last_error = ? WHERE name = ?
+		`, started.Unix(), time.Since(started).Milliseconds(), lastError, job.Name); dbErr != nil {
+		log.Printf("Failed to record run of job %s: %v", job.Name, dbErr)
+	}
+}
+
+// safeRun turns a panicking handler into an error of the job
+func safeRun(ctx context.Context, handler Handler, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row scanner) (Job, error) {
+	var (
+		job                            Job
+		interval, nextRunAt, createdAt int64
+		lastRunAt, lastDuration        sql.NullInt64
+		lastError                      sql.NullString
+	)
+
+	if err := row.Scan(&job.Name, &job.Kind, &job.Key, &interval, &nextRunAt, &lastRunAt, &lastDuration, &lastError, &job.Paused, &createdAt); err != nil {
+		return Job{}, err
+	}
+
+	job.Interval = time.Duration(interval) * time.Second
+	job.NextRunAt = time.Unix(nextRunAt, 0)
+	job.CreatedAt = time.Unix(createdAt, 0)
+	job.LastDuration = time.Duration(lastDuration.Int64) * time.Millisecond
+	if lastRunAt.Valid {
+		at := time.Unix(lastRunAt.Int64, 0)
+		job.LastRunAt = &at
+	}
+	if lastError.Valid {
+		job.LastError = &lastError.String
+	}
+
+	return job, nil
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		log.Printf("Invalid %s=%q, using default %s", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,215 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", "file:"+t.TempDir()+"/test.db?_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, stmt := range Schema {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+
+	return db
+}
+
+func newTestScheduler(db *sql.DB, id string) *Scheduler {
+	s := &Scheduler{
+		DB:       db,
+		ID:       id,
+		Tick:     time.Second,
+		LeaseTTL: time.Minute,
+	}
+	s.SetConcurrency(4)
+	return s
+}
+
+func TestRunPending(t *testing.T) {
+	ctx := context.Background()
+	s := newTestScheduler(newTestDB(t), "a")
+
+	var runs atomic.Int32
+	s.Handle("sync", func(ctx context.Context, job Job) error {
+		runs.Add(1)
+		if job.Key == "2" {
+			return errors.New("upstream down")
+		}
+		return nil
+	})
+
+	require.NoError(t, s.Ensure(ctx, "sync", "1", time.Hour))
+	require.NoError(t, s.Ensure(ctx, "sync", "2", time.Hour))
+	// Ensuring again keeps the schedule
+	require.NoError(t, s.Ensure(ctx, "sync", "1", time.Hour))
+
+	started, err := s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, started)
+	assert.Equal(t, int32(2), runs.Load())
+
+	// Both are claimed until their next run
+	started, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, started)
+
+	jobs, err := s.List(ctx, "sync")
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	for _, job := range jobs {
+		require.NotNil(t, job.LastRunAt, job.Name)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), job.NextRunAt, time.Minute, job.Name)
+	}
+
+	failed, err := s.Get(ctx, Name("sync", "2"))
+	require.NoError(t, err)
+	require.NotNil(t, failed.LastError)
+	assert.Equal(t, "upstream down", *failed.LastError)
+
+	succeeded, err := s.Get(ctx, Name("sync", "1"))
+	require.NoError(t, err)
+	assert.Nil(t, succeeded.LastError)
+}
+
+func TestPauseAndTrigger(t *testing.T) {
+	ctx := context.Background()
+	s := newTestScheduler(newTestDB(t), "a")
+
+	var runs atomic.Int32
+	s.Handle("sync", func(context.Context, Job) error {
+		runs.Add(1)
+		return nil
+	})
+
+	require.NoError(t, s.Ensure(ctx, "sync", "1", time.Hour))
+	name := Name("sync", "1")
+	require.NoError(t, s.SetPaused(ctx, name, true))
+
+	started, err := s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, started)
+
+	require.NoError(t, s.SetPaused(ctx, name, false))
+	_, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), runs.Load())
+
+	require.NoError(t, s.Trigger(ctx, name))
+	_, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), runs.Load())
+
+	assert.ErrorIs(t, s.SetPaused(ctx, "sync:unknown", true), ErrJobNotFound)
+	assert.ErrorIs(t, s.Trigger(ctx, "sync:unknown"), ErrJobNotFound)
+	_, err = s.Get(ctx, "sync:unknown")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	require.NoError(t, s.Remove(ctx, "1"))
+	jobs, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestLeader(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	a, b := newTestScheduler(db, "a"), newTestScheduler(db, "b")
+
+	var runs atomic.Int32
+	for _, s := range []*Scheduler{a, b} {
+		s.Handle("sync", func(context.Context, Job) error {
+			runs.Add(1)
+			return nil
+		})
+	}
+	require.NoError(t, a.Ensure(ctx, "sync", "1", time.Hour))
+
+	leader, err := a.Leader(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+
+	// The follower does nothing while the lease holds
+	started, err := b.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, started)
+
+	// and takes over once it expired
+	_, err = db.Exec(`UPDATE scheduler_leases SET expires_at = ?`, time.Now().Add(-time.Second).Unix())
+	require.NoError(t, err)
+
+	started, err = b.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, started)
+
+	leader, err = a.Leader(ctx)
+	require.NoError(t, err)
+	assert.False(t, leader)
+
+	// A released lease is free for the taking
+	b.release()
+	leader, err = a.Leader(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestConcurrencyAndReady(t *testing.T) {
+	ctx := context.Background()
+	s := newTestScheduler(newTestDB(t), "a")
+	s.SetConcurrency(1)
+
+	s.Handle("sync", func(context.Context, Job) error { return nil })
+	for _, key := range []string{"1", "2", "3"} {
+		require.NoError(t, s.Ensure(ctx, "sync", key, time.Hour))
+	}
+
+	started, err := s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, started)
+
+	s.Ready = func() bool { return false }
+	started, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, started)
+
+	s.Ready = nil
+	started, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, started)
+}
+
+func TestPanickingHandler(t *testing.T) {
+	ctx := context.Background()
+	s := newTestScheduler(newTestDB(t), "a")
+
+	s.Handle("sync", func(context.Context, Job) error { panic("boom") })
+	require.NoError(t, s.Ensure(ctx, "sync", "1", time.Hour))
+	require.NoError(t, s.Ensure(ctx, "unknown", "1", time.Hour))
+
+	_, err := s.RunPending(ctx)
+	require.NoError(t, err)
+
+	job, err := s.Get(ctx, Name("sync", "1"))
+	require.NoError(t, err)
+	require.NotNil(t, job.LastError)
+	assert.Contains(t, *job.LastError, "boom")
+
+	job, err = s.Get(ctx, Name("unknown", "1"))
+	require.NoError(t, err)
+	require.NotNil(t, job.LastError)
+	assert.Contains(t, *job.LastError, "no handler")
+}
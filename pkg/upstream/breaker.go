@@ -0,0 +1,125 @@
+package upstream
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when the breaker is rejecting calls to i-Ma'luum
+var ErrCircuitOpen = errors.New("upstream circuit breaker is open")
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+)
+
+// BreakerStats is a point in time snapshot of the breaker, served in /health
+type BreakerStats struct {
+	OpenedAt            time.Time `json:"opened_at,omitzero"`
+	State               State     `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Threshold           int       `json:"threshold"`
+	Cooldown            string    `json:"cooldown"`
+}
+
+// Breaker is a consecutive-failure circuit breaker.
+// After threshold failures in a row it opens and rejects every call until
+// cooldown has passed, then lets a single probe through (half-open).
+// A successful probe closes it again, a failed one re-opens it.
+type Breaker struct {
+	openedAt  time.Time
+	state     State
+	cooldown  time.Duration
+	threshold int
+	failures  int
+	probing   bool
+	mu        sync.Mutex
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		state:     StateClosed,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow reports whether a call may go through right now
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		// Only one probe at a time while half-open
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record reports the outcome of a call that was allowed through
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if success {
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Release gives back a half-open probe slot without recording an outcome,
+// used when the caller gave up before i-Ma'luum answered.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == StateOpen && time.Since(b.openedAt) >= b.cooldown {
+		state = StateHalfOpen
+	}
+
+	stats := BreakerStats{
+		State:               state,
+		ConsecutiveFailures: b.failures,
+		Threshold:           b.threshold,
+		Cooldown:            b.cooldown.String(),
+	}
+	if state != StateClosed {
+		stats.OpenedAt = b.openedAt
+	}
+
+	return stats
+}
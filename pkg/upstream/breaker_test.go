@@ -0,0 +1,88 @@
+package upstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+
+	tests := []struct {
+		name string
+		// outcomes are recorded in order, true for a success
+		outcomes []bool
+		wait     time.Duration
+		want     State
+		allowed  bool
+	}{
+		{name: "closed below the threshold", outcomes: []bool{false, false}, want: StateClosed, allowed: true},
+		{name: "a success resets the count", outcomes: []bool{false, false, true, false, false}, want: StateClosed, allowed: true},
+		{name: "opens at the threshold", outcomes: []bool{false, false, false}, want: StateOpen, allowed: false},
+		{name: "half-opens after the cooldown", outcomes: []bool{false, false, false}, wait: cooldown, want: StateHalfOpen, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := NewBreaker(3, cooldown)
+			for _, success := range tt.outcomes {
+				require.NoError(t, breaker.Allow())
+				breaker.Record(success)
+			}
+			time.Sleep(tt.wait)
+
+			assert.Equal(t, tt.want, breaker.Stats().State)
+			if tt.allowed {
+				assert.NoError(t, breaker.Allow())
+			} else {
+				assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+			}
+		})
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+
+	open := func(t *testing.T) *Breaker {
+		t.Helper()
+		breaker := NewBreaker(1, cooldown)
+		require.NoError(t, breaker.Allow())
+		breaker.Record(false)
+		require.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+		time.Sleep(cooldown)
+		return breaker
+	}
+
+	t.Run("one probe at a time", func(t *testing.T) {
+		breaker := open(t)
+		require.NoError(t, breaker.Allow())
+		assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+		// A released probe frees the slot without an outcome
+		breaker.Release()
+		assert.NoError(t, breaker.Allow())
+	})
+
+	t.Run("a successful probe closes", func(t *testing.T) {
+		breaker := open(t)
+		require.NoError(t, breaker.Allow())
+		breaker.Record(true)
+
+		assert.Equal(t, StateClosed, breaker.Stats().State)
+		assert.Zero(t, breaker.Stats().ConsecutiveFailures)
+		assert.NoError(t, breaker.Allow())
+	})
+
+	t.Run("a failed probe reopens", func(t *testing.T) {
+		breaker := open(t)
+		require.NoError(t, breaker.Allow())
+		breaker.Record(false)
+
+		assert.Equal(t, StateOpen, breaker.Stats().State)
+		assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+	})
+}
@@ -0,0 +1,366 @@
+package upstream
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bytedance/sonic"
+)
+
+// ErrNoRecording is returned in replay mode when no recording matches a request
+var ErrNoRecording = errors.New("no recording for upstream request")
+
+const redacted = "REDACTED"
+
+// Headers, query parameters and form fields whose values never leave the process
+var (
+	redactedHeaders = []string{"Authorization", "X-Forwarded-For"}
+	cookieHeaders   = []string{"Cookie", "Set-Cookie"}
+	redactedParams  = []string{"ticket", "username", "password"}
+)
+
+// piiPatterns scrub personal data from recorded bodies. This is best effort,
+// recordings should still be reviewed before they are shared.
+var piiPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// Labelled fields on the profile page
+	{regexp.MustCompile(`(?i)(IC / Passport No|Birth Date|Address|Phone|Mobile|E-?mail)(\s*:\s*)[^<]*`), "${1}${2}" + redacted},
+	// Student name on the profile page
+	{regexp.MustCompile(`(<h4 style="margin-top:1%;">)[^<]*(</h4>)`), "${1}" + redacted + "${2}"},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "redacted@example.com"},
+	// Malaysian IC number
+	{regexp.MustCompile(`\b\d{6}-\d{2}-\d{4}\b`), "000000-00-0000"},
+	{regexp.MustCompile(`\b(\+?60|0)1\d-?\d{7,8}\b`), "010-0000000"},
+	// Matric number heading the programme on the profile page, in labelled
+	// fields and naming smartcard photos. Other 7 digit numbers are left alone.
+	{regexp.MustCompile(`(<h4>\s*)\d{7}(\s*\|)`), "${1}0000000${2}"},
+	{regexp.MustCompile(`(?i)(Matric(?:ulation)? No\.?\s*:\s*)\d{7}\b`), "${1}0000000"},
+	{regexp.MustCompile(`(/)\d{7}(\.(?:jpe?g|png)\b)`), "${1}0000000${2}"},
+}
+
+// Recording is a single redacted request/response pair as stored on disk
+type Recording struct {
+	RecordedAt time.Time        `json:"recorded_at"`
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Header http.Header `json:"header"`
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body,omitempty"`
+	Encoding   string      `json:"encoding,omitempty"`
+	StatusCode int         `json:"status_code"`
+}
+
+// key identifies the upstream resource a recording answers
+func (r *Recording) key() string {
+	return r.Request.Method + " " + r.Request.URL
+}
+
+// Recorder writes every exchange that goes through Base to Dir as a JSON
+// file, with cookies, credentials and personal data scrubbed. The response
+// is passed on to the caller untouched.
+type Recorder struct {
+	Base http.RoundTripper
+	Dir  string
+}
+
+func (t *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			_ = body.Close()
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	recording := Recording{
+		RecordedAt: time.Now(),
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    redactURL(req.URL.String()),
+			Header: redactHeader(req.Header),
+			Body:   redactForm(string(reqBody)),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header),
+		},
+	}
+	recording.Response.Body = encodeBody(resp.Header.Get("Content-Type"), respBody)
+
+	if err := t.write(&recording); err != nil {
+		// Recording is a debugging aid, never fail the real call because of it
+		log.Printf("Failed to record %s: %v", recording.key(), err)
+	}
+
+	return resp, nil
+}
+
+func (t *Recorder) write(recording *Recording) error {
+	if err := os.MkdirAll(t.Dir, 0o750); err != nil {
+		return err
+	}
+
+	data, err := sonic.ConfigStd.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s-%s.json", recording.RecordedAt.UnixNano(), strings.ToLower(recording.Request.Method), slug(recording.Request.URL))
+	return os.WriteFile(filepath.Join(t.Dir, name), data, 0o600)
+}
+
+// Replayer answers requests from the recordings in a directory instead of
+// the network. When a resource was recorded more than once the most recent
+// recording wins, and requests nobody recorded fail with ErrNoRecording.
+type Replayer struct {
+	recordings map[string]*Recording
+}
+
+// NewReplayer loads every recording in dir
+func NewReplayer(dir string) (*Replayer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	// File names start with the recording time
+	sort.Strings(paths)
+
+	replayer := &Replayer{
+		recordings: make(map[string]*Recording, len(paths)),
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var recording Recording
+		if err := sonic.Unmarshal(data, &recording); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		replayer.recordings[recording.key()] = &recording
+	}
+
+	return replayer, nil
+}
+
+func (t *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	key := req.Method + " " + redactURL(req.URL.String())
+
+	recording, ok := t.recordings[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoRecording, key)
+	}
+
+	body, err := decodeBody(recording.Response.Body, recording.Response.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scrubbing changed the body length
+	header := recording.Response.Header.Clone()
+	header.Del("Content-Length")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recording.Response.StatusCode, http.StatusText(recording.Response.StatusCode)),
+		StatusCode:    recording.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// WithRecording wraps base according to the environment:
+//
+//	UPSTREAM_RECORD_DIR  record every upstream exchange to this directory
+//	UPSTREAM_REPLAY_DIR  serve upstream calls from the recordings in this directory
+//
+// Replay takes precedence when both are set.
+func WithRecording(base http.RoundTripper) (http.RoundTripper, error) {
+	if dir := os.Getenv("UPSTREAM_REPLAY_DIR"); dir != "" {
+		replayer, err := NewReplayer(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load upstream recordings: %w", err)
+		}
+		log.Printf("Replaying %d upstream recordings from %s", len(replayer.recordings), dir)
+		return replayer, nil
+	}
+
+	if dir := os.Getenv("UPSTREAM_RECORD_DIR"); dir != "" {
+		log.Printf("Recording upstream traffic to %s", dir)
+		return &Recorder{Base: base, Dir: dir}, nil
+	}
+
+	return base, nil
+}
+
+func redactHeader(header http.Header) http.Header {
+	clone := header.Clone()
+	for _, name := range redactedHeaders {
+		if clone.Get(name) != "" {
+			clone.Set(name, redacted)
+		}
+	}
+	for _, name := range cookieHeaders {
+		values := clone.Values(name)
+		for i, value := range values {
+			values[i] = redactCookie(value)
+		}
+	}
+	if location := clone.Get("Location"); location != "" {
+		clone.Set("Location", redactURL(location))
+	}
+	return clone
+}
+
+// redactCookie keeps cookie names and attributes so replayed logins still
+// set the cookies the scrapers look for
+func redactCookie(value string) string {
+	parts := strings.Split(value, ";")
+	for i, part := range parts {
+		name, _, found := strings.Cut(part, "=")
+		// Set-Cookie attributes such as Path are needed to replay the cookie
+		if !found || (i > 0 && isCookieAttribute(name)) {
+			continue
+		}
+		parts[i] = name + "=" + redacted
+	}
+	return strings.Join(parts, ";")
+}
+
+func isCookieAttribute(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "path", "domain", "expires", "max-age", "samesite":
+		return true
+	}
+	return false
+}
+
+// redactURL hides credentials in the query and personal data in the path,
+// such as the matric number in smartcard photo URLs
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	u.Path = scrub(u.Path)
+	u.RawPath = ""
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for _, param := range redactedParams {
+			if query.Has(param) {
+				query.Set(param, redacted)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+func redactForm(body string) string {
+	form, err := url.ParseQuery(body)
+	if err != nil || len(form) == 0 {
+		return scrub(body)
+	}
+
+	for _, param := range redactedParams {
+		if form.Has(param) {
+			form.Set(param, redacted)
+		}
+	}
+	return form.Encode()
+}
+
+func scrub(body string) string {
+	for _, pii := range piiPatterns {
+		body = pii.pattern.ReplaceAllString(body, pii.replacement)
+	}
+	return body
+}
+
+// encodeBody stores scrubbed text as is so recordings can be turned into
+// fixtures. Binary bodies cannot be scrubbed and are dropped: the images
+// fetched are student photos, and the PDFs are slips and study plans that
+// carry the student's name, matric and IC numbers.
+func encodeBody(contentType string, body []byte) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/"),
+		mediaType == "application/pdf",
+		mediaType == "application/octet-stream",
+		!utf8.Valid(body):
+		return ""
+	default:
+		return scrub(string(body))
+	}
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(raw string) string {
+	u, err := url.Parse(raw)
+	if err == nil {
+		raw = u.Host + u.Path
+	}
+	s := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(raw), "-"), "-")
+	if len(s) > 80 {
+		s = s[:80]
+	}
+	return s
+}
@@ -0,0 +1,163 @@
+package upstream
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrub(t *testing.T) {
+	t.Run("profile with personal data", func(t *testing.T) {
+		page := `<h4 style="margin-top:1%;">AHMAD FULAN BIN ABDULLAH</h4>
+<h4>2110000 | BACHELOR OF COMPUTER SCIENCE (HONOURS)</h4>
+<p>IC / Passport No : 000101-01-0101</p>
+<p>Address : 1, JALAN CONTOH, 53100 GOMBAK</p>
+<p>Matric No: 2110000</p>
+<p>Contact ahmad.fulan@live.iium.edu.my or 012-3456789, IC 990101-14-5555</p>`
+
+		scrubbed := scrub(page)
+		for _, pii := range []string{"AHMAD FULAN", "2110000", "000101-01-0101", "JALAN CONTOH", "ahmad.fulan", "012-3456789", "990101-14-5555"} {
+			assert.NotContains(t, scrubbed, pii)
+		}
+		assert.Contains(t, scrubbed, "<h4>0000000 | BACHELOR OF COMPUTER SCIENCE (HONOURS)</h4>")
+		assert.Contains(t, scrubbed, "Matric No: 0000000")
+	})
+
+	t.Run("timetable without personal data", func(t *testing.T) {
+		page := `<tr><td>CSCI 4311</td><td>COMPUTER ARCHITECTURE</td><td>1</td><td>3</td>
+<td>Registered</td><td>M - W</td><td>830 - 950</td><td>ICT LR 1</td><td>DR. LECTURER ONE</td></tr>
+<tr><td>Class ref 1234567</td><td>Total fees 1500000</td></tr>`
+
+		// Course data with 7 digit numbers is left alone
+		assert.Equal(t, page, scrub(page))
+	})
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{
+			raw:  "https://imaluum.iium.edu.my/home?ticket=ST-123-abc",
+			want: "https://imaluum.iium.edu.my/home?ticket=REDACTED",
+		},
+		{
+			raw:  "https://cas.iium.edu.my:8448/cas/login?service=https%3A%2F%2Fimaluum.iium.edu.my%2Fhome&username=2110000&password=secret",
+			want: "https://cas.iium.edu.my:8448/cas/login?password=REDACTED&service=https%3A%2F%2Fimaluum.iium.edu.my%2Fhome&username=REDACTED",
+		},
+		{
+			raw:  "https://smartcard.iium.edu.my/packages/card/printing/camera/uploads/original/2110000.jpeg",
+			want: "https://smartcard.iium.edu.my/packages/card/printing/camera/uploads/original/0000000.jpeg",
+		},
+		{
+			raw:  "https://imaluum.iium.edu.my/MyAcademic/schedule?ses=2024/2025&sem=1",
+			want: "https://imaluum.iium.edu.my/MyAcademic/schedule?sem=1&ses=2024%2F2025",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, redactURL(tt.raw))
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer v4.local.secret")
+	header.Set("Cookie", "MOD_AUTH_CAS=abc123; XSRF-TOKEN=def456")
+	header.Add("Set-Cookie", "MOD_AUTH_CAS=abc123; Path=/; Domain=.iium.edu.my; HttpOnly")
+	header.Set("Location", "https://imaluum.iium.edu.my/home?ticket=ST-123-abc")
+	header.Set("Content-Type", "text/html")
+
+	redactedHeader := redactHeader(header)
+	assert.Equal(t, redacted, redactedHeader.Get("Authorization"))
+	assert.Equal(t, "MOD_AUTH_CAS=REDACTED; XSRF-TOKEN=REDACTED", redactedHeader.Get("Cookie"))
+	// Cookie names and attributes survive so replayed logins still work
+	assert.Equal(t, "MOD_AUTH_CAS=REDACTED; Path=/; Domain=.iium.edu.my; HttpOnly", redactedHeader.Get("Set-Cookie"))
+	assert.Equal(t, "https://imaluum.iium.edu.my/home?ticket=REDACTED", redactedHeader.Get("Location"))
+	assert.Equal(t, "text/html", redactedHeader.Get("Content-Type"))
+
+	// The live header is untouched
+	assert.Equal(t, "MOD_AUTH_CAS=abc123; XSRF-TOKEN=def456", header.Get("Cookie"))
+}
+
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "MOD_AUTH_CAS", Value: "abc123", Path: "/"})
+		_, _ = w.Write([]byte(`<h4>2110000 | BACHELOR OF COMPUTER SCIENCE (HONOURS)</h4>`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: &Recorder{Base: http.DefaultTransport, Dir: dir}}
+
+	resp, err := client.PostForm(server.URL+"/cas/login?ticket=ST-1", url.Values{
+		"username":  {"2110000"},
+		"password":  {"secret"},
+		"execution": {"e1s1"},
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// The caller gets the real response
+	assert.Contains(t, resp.Header.Get("Set-Cookie"), "abc123")
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+
+	data, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	recording := string(data)
+	for _, secret := range []string{"abc123", "2110000", "secret", "ST-1"} {
+		assert.NotContains(t, recording, secret)
+	}
+	assert.Contains(t, recording, "execution=e1s1")
+}
+
+func TestRecorderDropsBinaryBodies(t *testing.T) {
+	slip := []byte("%PDF-1.4\n(AHMAD FULAN BIN ABDULLAH) (2110000) (000101-01-0101)\n%%EOF")
+	photo := []byte{0xff, 0xd8, 0xff, 0xe0, 0x00, 0x10}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/examslip":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write(slip)
+		default:
+			// Binary bodies are dropped whatever they are labelled
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write(photo)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: &Recorder{Base: http.DefaultTransport, Dir: dir}}
+
+	for _, path := range []string{"/examslip", "/photo"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		recording := string(data)
+		for _, payload := range []string{"PDF", "AHMAD", "2110000", base64.StdEncoding.EncodeToString(slip), base64.StdEncoding.EncodeToString(photo)} {
+			assert.NotContains(t, recording, payload)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package upstream
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryPolicy describes how an idempotent call to i-Ma'luum is retried.
+// Delays grow exponentially from BaseDelay up to MaxDelay with full jitter.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// NoRetry makes exactly one attempt
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy attaches a retry policy to the context of outgoing requests
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func retryPolicyFrom(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return NoRetry
+}
+
+// RetryPolicyFromEnv returns fallback overridden by
+// UPSTREAM_RETRY_<NAME>_ATTEMPTS, UPSTREAM_RETRY_<NAME>_BASE_DELAY and UPSTREAM_RETRY_<NAME>_MAX_DELAY
+func RetryPolicyFromEnv(name string, fallback RetryPolicy) RetryPolicy {
+	prefix := "UPSTREAM_RETRY_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+
+	return RetryPolicy{
+		MaxAttempts: envInt(prefix+"_ATTEMPTS", fallback.MaxAttempts),
+		BaseDelay:   envDuration(prefix+"_BASE_DELAY", fallback.BaseDelay),
+		MaxDelay:    envDuration(prefix+"_MAX_DELAY", fallback.MaxDelay),
+	}
+}
+
+// backoff returns the jittered delay before the given retry (1-based)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	ceiling := p.BaseDelay << (retry - 1)
+	if ceiling <= 0 || (p.MaxDelay > 0 && ceiling > p.MaxDelay) {
+		ceiling = p.MaxDelay
+	}
+
+	return rand.N(ceiling) + 1
+}
+
+// isIdempotent reports whether req can safely be sent again
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry only retries network errors and 5xx responses.
+// Errors raised by the guard itself are final.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !IsUnavailable(err)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain discards a response that is about to be retried so its connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
+
+// BindContext returns a RoundTripper that sends every request with ctx.
+// Colly builds its requests without a context, so collectors use this to
+// carry cancellation and the retry policy through to the Transport.
+func BindContext(ctx context.Context, base http.RoundTripper) http.RoundTripper {
+	return &contextTransport{ctx: ctx, base: base}
+}
+
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
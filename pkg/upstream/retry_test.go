@@ -0,0 +1,119 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldRetry(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		status int
+		want   bool
+	}{
+		{name: "network error", err: errors.New("connection reset"), want: true},
+		{name: "circuit open", err: ErrCircuitOpen},
+		{name: "rate limited", err: ErrRateLimited},
+		{name: "ok", status: http.StatusOK},
+		{name: "redirect", status: http.StatusFound},
+		{name: "not found", status: http.StatusNotFound},
+		{name: "too many requests", status: http.StatusTooManyRequests},
+		{name: "internal server error", status: http.StatusInternalServerError, want: true},
+		{name: "bad gateway", status: http.StatusBadGateway, want: true},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+			assert.Equal(t, tt.want, shouldRetry(resp, tt.err))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	for retry := 1; retry <= 10; retry++ {
+		ceiling := min(policy.BaseDelay<<(retry-1), policy.MaxDelay)
+		for range 100 {
+			delay := policy.backoff(retry)
+			assert.Positive(t, delay)
+			assert.LessOrEqual(t, delay, ceiling, "retry %d", retry)
+		}
+	}
+
+	// Shifting far enough overflows, which is capped as well
+	assert.LessOrEqual(t, policy.backoff(64), policy.MaxDelay)
+	assert.Zero(t, NoRetry.backoff(1))
+}
+
+func TestRetryAttempts(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		policy RetryPolicy
+		want   int32
+	}{
+		{name: "capped at MaxAttempts", method: http.MethodGet, policy: policy, want: 3},
+		{name: "no policy attached", method: http.MethodGet, want: 1},
+		{name: "POST is never retried", method: http.MethodPost, body: "username=2110000", policy: policy, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, base := testTransport(http.StatusBadGateway)
+			transport.Breaker = NewBreaker(10, time.Minute)
+
+			ctx := context.Background()
+			if tt.policy.MaxAttempts > 0 {
+				ctx = WithRetryPolicy(ctx, tt.policy)
+			}
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, err := http.NewRequestWithContext(ctx, tt.method, "https://imaluum.iium.edu.my/", body)
+			require.NoError(t, err)
+
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.want, base.calls.Load())
+		})
+	}
+}
+
+func TestRetryPolicyFromEnv(t *testing.T) {
+	fallback := RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+	t.Setenv("UPSTREAM_RETRY_EXAM_SLIP_ATTEMPTS", "5")
+	t.Setenv("UPSTREAM_RETRY_EXAM_SLIP_BASE_DELAY", "1s")
+	t.Setenv("UPSTREAM_RETRY_EXAM_SLIP_MAX_DELAY", "soon")
+
+	assert.Equal(t, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 2 * time.Second},
+		RetryPolicyFromEnv("exam-slip", fallback))
+
+	// Other endpoints keep the fallback
+	assert.Equal(t, fallback, RetryPolicyFromEnv("schedule", fallback))
+
+	t.Setenv("UPSTREAM_RETRY_SCHEDULE_ATTEMPTS", "0")
+	assert.Equal(t, fallback, RetryPolicyFromEnv("schedule", fallback))
+}
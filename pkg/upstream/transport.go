@@ -0,0 +1,178 @@
+package upstream
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited is returned when a call would have to queue longer than
+// the configured maximum wait for a rate limiter token
+var ErrRateLimited = errors.New("upstream rate limit exceeded")
+
+const (
+	defaultRateLimit        = 20
+	defaultBurst            = 40
+	defaultMaxWait          = 2 * time.Second
+	defaultFailureThreshold = 5
+	defaultCooldown         = 30 * time.Second
+)
+
+// Transport guards every call to i-Ma'luum with a token-bucket rate limiter
+// and a circuit breaker, so a slow or dead upstream fails fast instead of
+// holding goroutines for the whole client timeout.
+type Transport struct {
+	Base    http.RoundTripper
+	Limiter *rate.Limiter
+	Breaker *Breaker
+	MaxWait time.Duration
+}
+
+// New wraps base with a limiter and breaker configured from the environment:
+//
+//	UPSTREAM_RATE_LIMIT         requests per second (default 20)
+//	UPSTREAM_BURST              bucket size (default 40)
+//	UPSTREAM_MAX_WAIT           longest wait for a token (default 2s)
+//	UPSTREAM_BREAKER_THRESHOLD  consecutive failed calls before opening (default 5)
+//	UPSTREAM_BREAKER_COOLDOWN   time spent open before probing (default 30s)
+func New(base http.RoundTripper) *Transport {
+	return &Transport{
+		Base:    base,
+		Limiter: rate.NewLimiter(rate.Limit(envInt("UPSTREAM_RATE_LIMIT", defaultRateLimit)), envInt("UPSTREAM_BURST", defaultBurst)),
+		Breaker: NewBreaker(envInt("UPSTREAM_BREAKER_THRESHOLD", defaultFailureThreshold), envDuration("UPSTREAM_BREAKER_COOLDOWN", defaultCooldown)),
+		MaxWait: envDuration("UPSTREAM_MAX_WAIT", defaultMaxWait),
+	}
+}
+
+// RoundTrip sends req, retrying idempotent requests according to the
+// RetryPolicy attached to the request context (see WithRetryPolicy).
+//
+// Every attempt waits for its own limiter token, as each one reaches
+// i-Ma'luum. The breaker sees the call once: it must be allowed before the
+// first attempt and only the final outcome is recorded, so the retries of
+// one flaky request count as a single failure.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.send(req)
+	if err != nil && (req.Context().Err() != nil || errors.Is(err, ErrRateLimited)) {
+		// The caller went away or never reached i-Ma'luum, this says
+		// nothing about its health
+		t.Breaker.Release()
+		return nil, err
+	}
+
+	t.Breaker.Record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+
+	return resp, err
+}
+
+// send makes the attempts of a call allowed through the breaker
+func (t *Transport) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	policy := retryPolicyFrom(ctx)
+	if !isIdempotent(req) {
+		policy = NoRetry
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.attempt(req)
+		if attempt >= policy.MaxAttempts || !shouldRetry(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			drain(resp)
+		}
+
+		if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// attempt makes a single rate limited call to i-Ma'luum
+func (t *Transport) attempt(req *http.Request) (*http.Response, error) {
+	if err := t.wait(req); err != nil {
+		return nil, err
+	}
+	return t.Base.RoundTrip(req)
+}
+
+func (t *Transport) wait(req *http.Request) error {
+	reservation := t.Limiter.Reserve()
+	if !reservation.OK() {
+		return ErrRateLimited
+	}
+
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if delay > t.MaxWait {
+		reservation.Cancel()
+		return ErrRateLimited
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		reservation.Cancel()
+		return req.Context().Err()
+	}
+}
+
+// IsUnavailable reports whether err was produced by the guard rather than by i-Ma'luum itself
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrRateLimited)
+}
+
+func envInt(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		log.Printf("Invalid %s=%q, using default %d", key, raw, fallback)
+		return fallback
+	}
+
+	return value
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		log.Printf("Invalid %s=%q, using default %s", key, raw, fallback)
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,140 @@
+package upstream
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// stubUpstream answers every request with status and counts them
+type stubUpstream struct {
+	calls  atomic.Int32
+	status int
+}
+
+func (s *stubUpstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.calls.Add(1)
+	return &http.Response{
+		StatusCode: s.status,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func testTransport(status int) (*Transport, *stubUpstream) {
+	base := &stubUpstream{status: status}
+	return &Transport{
+		Base:    base,
+		Limiter: rate.NewLimiter(rate.Inf, 1),
+		Breaker: NewBreaker(2, time.Minute),
+		MaxWait: time.Second,
+	}, base
+}
+
+func get(t *testing.T, ctx context.Context, transport http.RoundTripper) (*http.Response, error) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://imaluum.iium.edu.my/", nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestTransportBreaker(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		calls   int
+		want    State
+		wantErr error
+	}{
+		{name: "successes keep it closed", status: http.StatusOK, calls: 3, want: StateClosed},
+		{name: "client errors are not failures", status: http.StatusNotFound, calls: 3, want: StateClosed},
+		{name: "opens after the threshold", status: http.StatusBadGateway, calls: 2, want: StateOpen, wantErr: ErrCircuitOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, base := testTransport(tt.status)
+			for range tt.calls {
+				_, err := get(t, context.Background(), transport)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, transport.Breaker.Stats().State)
+
+			_, err := get(t, context.Background(), transport)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, IsUnavailable(err))
+				// Rejected without reaching i-Ma'luum
+				assert.EqualValues(t, tt.calls, base.calls.Load())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransportRetriesCountOnce(t *testing.T) {
+	transport, base := testTransport(http.StatusServiceUnavailable)
+	ctx := WithRetryPolicy(context.Background(), RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	resp, err := get(t, ctx, transport)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// Three attempts reached i-Ma'luum, the breaker saw one failed call
+	assert.EqualValues(t, 3, base.calls.Load())
+	stats := transport.Breaker.Stats()
+	assert.Equal(t, 1, stats.ConsecutiveFailures)
+	assert.Equal(t, StateClosed, stats.State)
+}
+
+func TestTransportLimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxWait time.Duration
+		wantErr error
+	}{
+		{name: "waits for a token", maxWait: time.Second},
+		{name: "rejects past the maximum wait", maxWait: time.Millisecond, wantErr: ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, base := testTransport(http.StatusOK)
+			transport.Limiter = rate.NewLimiter(rate.Every(50*time.Millisecond), 1)
+			transport.MaxWait = tt.maxWait
+
+			_, err := get(t, context.Background(), transport)
+			require.NoError(t, err)
+
+			started := time.Now()
+			_, err = get(t, context.Background(), transport)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.True(t, IsUnavailable(err))
+				assert.EqualValues(t, 1, base.calls.Load())
+			} else {
+				require.NoError(t, err)
+				assert.GreaterOrEqual(t, time.Since(started), 40*time.Millisecond)
+				assert.EqualValues(t, 2, base.calls.Load())
+			}
+
+			// Never reaching i-Ma'luum is not held against it
+			assert.Zero(t, transport.Breaker.Stats().ConsecutiveFailures)
+		})
+	}
+}
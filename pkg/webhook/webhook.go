@@ -0,0 +1,215 @@
+// Package webhook delivers HMAC-signed JSON events to user-configured URLs.
+//
+// Every delivery carries the event type, a delivery ID, a unix timestamp and
+// a signature over "<timestamp>.<body>":
+//
+//	X-Gomaluum-Event: result.released
+//	X-Gomaluum-Delivery: <id>
+//	X-Gomaluum-Timestamp: 1735689600
+//	X-Gomaluum-Signature: sha256=<hex hmac>
+//
+// Receivers should recompute the signature with their secret and reject
+// stale timestamps.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	mrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nrmnqdds/gomaluum/pkg/netguard"
+)
+
+const (
+	EventHeader     = "X-Gomaluum-Event"
+	DeliveryHeader  = "X-Gomaluum-Delivery"
+	TimestampHeader = "X-Gomaluum-Timestamp"
+	SignatureHeader = "X-Gomaluum-Signature"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBaseDelay   = time.Second
+	defaultMaxDelay    = time.Minute
+	defaultTimeout     = 10 * time.Second
+)
+
+var (
+	// ErrInvalidURL is returned for webhook URLs that cannot be delivered to
+	ErrInvalidURL = errors.New("webhook URL must be an absolute https URL")
+
+	// ErrPrivateURL is returned for webhook URLs on loopback, private or
+	// link-local addresses
+	ErrPrivateURL = errors.New("webhook URL must point to a public address")
+)
+
+// Sender posts events, retrying failed deliveries with exponential backoff
+type Sender struct {
+	Client      *http.Client
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// AllowHTTP accepts plain http URLs on any address, for local development
+	AllowHTTP bool
+}
+
+// NewSender configures a sender from the environment:
+//
+//	WEBHOOK_MAX_ATTEMPTS  deliveries tried before giving up (default 5)
+//	WEBHOOK_BASE_DELAY    backoff before the second attempt (default 1s)
+//	WEBHOOK_ALLOW_HTTP    accept plain http URLs on any address (default false)
+func NewSender() *Sender {
+	sender := &Sender{
+		MaxAttempts: defaultMaxAttempts,
+		BaseDelay:   defaultBaseDelay,
+		MaxDelay:    defaultMaxDelay,
+	}
+
+	if raw := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); raw != "" {
+		if attempts, err := strconv.Atoi(raw); err == nil && attempts > 0 {
+			sender.MaxAttempts = attempts
+		} else {
+			log.Printf("Invalid WEBHOOK_MAX_ATTEMPTS=%q, using default %d", raw, defaultMaxAttempts)
+		}
+	}
+
+	if raw := os.Getenv("WEBHOOK_BASE_DELAY"); raw != "" {
+		if delay, err := time.ParseDuration(raw); err == nil && delay > 0 {
+			sender.BaseDelay = delay
+		} else {
+			log.Printf("Invalid WEBHOOK_BASE_DELAY=%q, using default %s", raw, defaultBaseDelay)
+		}
+	}
+
+	sender.AllowHTTP, _ = strconv.ParseBool(os.Getenv("WEBHOOK_ALLOW_HTTP"))
+
+	// Webhooks point anywhere, never through the i-Ma'luum transport, and
+	// only at public addresses unless developing locally
+	sender.Client = netguard.NewClient(defaultTimeout)
+	if sender.AllowHTTP {
+		sender.Client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return sender
+}
+
+// Validate checks that raw is a URL the sender delivers to. Its host must
+// resolve to public addresses only, the Client checks again on every dial.
+func (s *Sender) Validate(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ErrInvalidURL
+	}
+	if u.Scheme != "https" && (u.Scheme != "http" || !s.AllowHTTP) {
+		return ErrInvalidURL
+	}
+	if s.AllowHTTP {
+		return nil
+	}
+
+	if err := netguard.CheckHost(ctx, u.Hostname()); err != nil {
+		return fmt.Errorf("%w: %v", ErrPrivateURL, err)
+	}
+	return nil
+}
+
+// NewSecret returns a random signing secret
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// Sign returns the signature header value for body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches body sent at timestamp
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Deliver posts body to target until it answers 2xx, the attempts run out,
+// or it answers with an error retrying would not fix. It returns the number
+// of attempts made.
+func (s *Sender) Deliver(ctx context.Context, target, secret, event, id string, body []byte) (int, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = s.post(ctx, target, secret, event, id, body)
+		if err == nil {
+			return attempt, nil
+		}
+		if !retry || attempt >= s.MaxAttempts {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(s.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// post makes a single delivery and reports whether a failure is worth retrying
+func (s *Sender) post(ctx context.Context, target, secret, event, id string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gomaluum-webhook")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, id)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, netguard.ErrForbiddenAddress), err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= http.StatusInternalServerError ||
+		resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook answered %s", resp.Status)
+}
+
+// backoff is exponential with full jitter, capped at MaxDelay
+func (s *Sender) backoff(attempt int) time.Duration {
+	ceiling := s.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > s.MaxDelay {
+		ceiling = s.MaxDelay
+	}
+	return mrand.N(ceiling + 1)
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nrmnqdds/gomaluum/pkg/netguard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSender() *Sender {
+	return &Sender{
+		Client:      http.DefaultClient,
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		AllowHTTP:   true,
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signature := Sign("whsec_test", 1735689600, body)
+
+	assert.True(t, Verify("whsec_test", 1735689600, body, signature))
+	assert.False(t, Verify("whsec_other", 1735689600, body, signature))
+	assert.False(t, Verify("whsec_test", 1735689601, body, signature))
+	assert.False(t, Verify("whsec_test", 1735689600, []byte(`{"id":"2"}`), signature))
+}
+
+func TestValidate(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		sender = testSender()
+	)
+	sender.AllowHTTP = false
+
+	assert.NoError(t, sender.Validate(ctx, "https://93.184.215.14/hook"))
+	assert.ErrorIs(t, sender.Validate(ctx, "http://example.com/hook"), ErrInvalidURL)
+	assert.ErrorIs(t, sender.Validate(ctx, "/hook"), ErrInvalidURL)
+	assert.ErrorIs(t, sender.Validate(ctx, "ftp://example.com/hook"), ErrInvalidURL)
+
+	sender.AllowHTTP = true
+	assert.NoError(t, sender.Validate(ctx, "http://localhost:8080/hook"))
+}
+
+func TestValidateRejectsPrivateAddresses(t *testing.T) {
+	sender := testSender()
+	sender.AllowHTTP = false
+
+	for _, target := range []string{
+		"https://127.0.0.1/hook",
+		"https://localhost:8443/hook",
+		"https://[::1]/hook",
+		"https://10.0.0.8/hook",
+		"https://192.168.1.1/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://0.0.0.0/hook",
+	} {
+		assert.ErrorIs(t, sender.Validate(context.Background(), target), ErrPrivateURL, target)
+	}
+}
+
+func TestDeliverRefusesPrivateAddresses(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	// A host that resolved elsewhere when it was registered is refused on dial
+	sender := testSender()
+	sender.Client = netguard.NewClient(time.Second)
+
+	attempts, err := sender.Deliver(context.Background(), receiver.URL, "whsec_test", "result.released", "delivery-1", []byte(`{}`))
+	assert.ErrorIs(t, err, netguard.ErrForbiddenAddress)
+	assert.Equal(t, 1, attempts)
+	assert.Zero(t, calls.Load())
+}
+
+func TestDeliver(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+
+		assert.Equal(t, "result.released", r.Header.Get(EventHeader))
+		assert.Equal(t, "delivery-1", r.Header.Get(DeliveryHeader))
+		assert.True(t, Verify("whsec_test", timestamp, body, r.Header.Get(SignatureHeader)))
+
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer receiver.Close()
+
+	attempts, err := testSender().Deliver(context.Background(), receiver.URL, "whsec_test", "result.released", "delivery-1", []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	for status, want := range map[int]int{
+		http.StatusServiceUnavailable: 3,
+		http.StatusTooManyRequests:    3,
+		// Retrying would not fix a rejected delivery
+		http.StatusBadRequest: 1,
+		http.StatusGone:       1,
+	} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var calls atomic.Int32
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(status)
+			}))
+			defer receiver.Close()
+
+			attempts, err := testSender().Deliver(context.Background(), receiver.URL, "whsec_test", "result.released", "delivery-1", []byte(`{}`))
+			assert.Error(t, err)
+			assert.Equal(t, want, attempts)
+			assert.Equal(t, int32(want), calls.Load())
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	require.NoError(t, err)
+	second, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, first)
+	assert.NotEqual(t, first, second)
+}